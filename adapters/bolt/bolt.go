@@ -4,11 +4,16 @@ import (
 	"os"
 	"time"
 
+	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/errors"
 	bolt "go.etcd.io/bbolt"
 )
 
-func NewBolt(path string) (*bolt.DB, error) {
+// NewBolt opens the database file. If readOnly is set the file is opened
+// using a shared lock which makes it possible to open it from multiple
+// processes at the same time as long as all of them open it in read-only
+// mode.
+func NewBolt(path string, readOnly bool) (*bolt.DB, error) {
 	_, err := os.Stat(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -18,13 +23,37 @@ func NewBolt(path string) (*bolt.DB, error) {
 		return nil, errors.Wrap(err, "could not stat the database file")
 	}
 
+	return open(path, readOnly)
+}
+
+// NewDestinationBolt creates a new database file. It returns
+// ErrDestinationExists if the file already exists so that existing files are
+// never overwritten.
+func NewDestinationBolt(path string) (*bolt.DB, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return nil, application.ErrDestinationExists
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrap(err, "could not stat the database file")
+	}
+
+	return open(path, false)
+}
+
+func open(path string, readOnly bool) (*bolt.DB, error) {
 	options := &bolt.Options{
-		Timeout: 5 * time.Second,
+		Timeout:  5 * time.Second,
+		ReadOnly: readOnly,
 	}
 
 	db, err := bolt.Open(path, 0600, options)
 	if err != nil {
 		if errors.Is(err, bolt.ErrTimeout) {
+			if readOnly {
+				return nil, errors.Wrap(err, "error opening the database (is another program using it in read-write mode?)")
+			}
 			return nil, errors.Wrap(err, "error opening the database (is another instance of the program running?)")
 		}
 		return nil, errors.Wrap(err, "error opening the database")
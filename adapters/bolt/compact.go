@@ -0,0 +1,126 @@
+package bolt
+
+import (
+	"os"
+	"time"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// compactionTxMaxSize limits the size of the transactions used to
+	// write to the destination database so that the progress can be
+	// observed and the memory usage stays low.
+	compactionTxMaxSize = 64 * 1024
+
+	compactionProgressInterval = 100 * time.Millisecond
+)
+
+// Compact writes a defragmented copy of the database to a new file located at
+// the destination path using bbolt's Compact. The progress is reported by
+// periodically counting the top-level buckets which were already committed to
+// the destination database. The destination file is removed if the
+// compaction fails. If fn returns an error the compaction fails only after
+// bbolt's Compact finishes copying the database as it can't be aborted.
+func Compact(src *bolt.DB, destination string, fn application.CompactionProgressFn) (application.CompactionResult, error) {
+	sizeBefore, err := fileSize(src.Path())
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not get the size of the source file")
+	}
+
+	totalBuckets, err := countBuckets(src)
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not count the buckets")
+	}
+
+	dst, err := NewDestinationBolt(destination)
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not create the destination database")
+	}
+
+	if err := compact(dst, src, totalBuckets, fn); err != nil {
+		dst.Close()
+		os.Remove(destination)
+		return application.CompactionResult{}, errors.Wrap(err, "compaction failed")
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(destination)
+		return application.CompactionResult{}, errors.Wrap(err, "could not close the destination database")
+	}
+
+	sizeAfter, err := fileSize(destination)
+	if err != nil {
+		return application.CompactionResult{}, errors.Wrap(err, "could not get the size of the destination file")
+	}
+
+	return application.CompactionResult{
+		SizeBefore: sizeBefore,
+		SizeAfter:  sizeAfter,
+	}, nil
+}
+
+func compact(dst, src *bolt.DB, totalBuckets int, fn application.CompactionProgressFn) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- bolt.Compact(dst, src, compactionTxMaxSize)
+	}()
+
+	ticker := time.NewTicker(compactionProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return errors.Wrap(err, "could not compact the database")
+			}
+			return reportProgress(dst, totalBuckets, fn)
+		case <-ticker.C:
+			if err := reportProgress(dst, totalBuckets, fn); err != nil {
+				// the compaction has to finish before the
+				// destination database can be closed
+				<-done
+				return errors.Wrap(err, "could not report the progress")
+			}
+		}
+	}
+}
+
+func reportProgress(dst *bolt.DB, totalBuckets int, fn application.CompactionProgressFn) error {
+	copiedBuckets, err := countBuckets(dst)
+	if err != nil {
+		return errors.Wrap(err, "could not count the buckets")
+	}
+
+	size, err := fileSize(dst.Path())
+	if err != nil {
+		return errors.Wrap(err, "could not get the size of the destination file")
+	}
+
+	return fn(application.CompactionProgress{
+		CopiedBuckets: copiedBuckets,
+		TotalBuckets:  totalBuckets,
+		Size:          size,
+	})
+}
+
+func countBuckets(db *bolt.DB) (n int, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			n++
+			return nil
+		})
+	})
+	return n, err
+}
+
+func fileSize(path string) (int64, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "stat failed")
+	}
+	return fileInfo.Size(), nil
+}
@@ -1,13 +1,14 @@
 package bolt
 
 import (
+	"bytes"
+	"io"
+
 	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/errors"
 	"go.etcd.io/bbolt"
 )
 
-const perPage = 10
-
 type Database struct {
 	tx *bbolt.Tx
 }
@@ -18,15 +19,20 @@ func NewDatabase(tx *bbolt.Tx) *Database {
 	}
 }
 
-func (d *Database) Browse(path []application.Key, before, after, from *application.Key) ([]application.Entry, error) {
+func (d *Database) Browse(path []application.Key, before, after, from, prefix *application.Key, limit int) (application.Page, error) {
+	var prefixBytes []byte
+	if prefix != nil {
+		prefixBytes = prefix.Bytes()
+	}
+
 	if len(path) == 0 {
 		c := d.tx.Cursor()
-		return d.iterate(c, before, after, from, isAlwaysBucket)
+		return d.iterate(c, before, after, from, prefixBytes, limit, isAlwaysBucket)
 	}
 
 	bucket, err := d.getBucket(path)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not get the bucket")
+		return application.Page{}, errors.Wrap(err, "could not get the bucket")
 	}
 
 	isBucket := func(key []byte) bool {
@@ -34,23 +40,330 @@ func (d *Database) Browse(path []application.Key, before, after, from *applicati
 	}
 
 	c := bucket.Cursor()
-	return d.iterate(c, before, after, from, isBucket)
+	return d.iterate(c, before, after, from, prefixBytes, limit, isBucket)
+}
+
+func (d *Database) Get(path []application.Key, key application.Key) (application.Entry, error) {
+	if len(path) == 0 {
+		if d.tx.Bucket(key.Bytes()) == nil {
+			return application.Entry{}, application.ErrKeyNotFound
+		}
+		return newEntry(isAlwaysBucket, key.Bytes(), nil)
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return application.Entry{}, errors.Wrap(err, "could not get the bucket")
+	}
+
+	k, v := bucket.Cursor().Seek(key.Bytes())
+	if !bytes.Equal(k, key.Bytes()) {
+		return application.Entry{}, application.ErrKeyNotFound
+	}
+
+	isBucket := func(key []byte) bool {
+		return bucket.Bucket(key) != nil
+	}
+
+	return newEntry(isBucket, k, v)
+}
+
+func (d *Database) Put(path []application.Key, key application.Key, value application.Value) error {
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	if err := bucket.Put(key.Bytes(), value.Bytes()); err != nil {
+		if errors.Is(err, bbolt.ErrIncompatibleValue) {
+			return application.ErrKeyIsABucket
+		}
+		return errors.Wrap(err, "put failed")
+	}
+
+	return nil
+}
+
+func (d *Database) Delete(path []application.Key, key application.Key) error {
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	if k, _ := bucket.Cursor().Seek(key.Bytes()); !bytes.Equal(k, key.Bytes()) {
+		return application.ErrKeyNotFound
+	}
+
+	if err := bucket.Delete(key.Bytes()); err != nil {
+		if errors.Is(err, bbolt.ErrIncompatibleValue) {
+			return application.ErrKeyIsABucket
+		}
+		return errors.Wrap(err, "delete failed")
+	}
+
+	return nil
+}
+
+func (d *Database) CreateBucket(path []application.Key) error {
+	_, err := d.createBucket(path)
+	return err
+}
+
+func (d *Database) DeleteBucket(path []application.Key) error {
+	parent, err := d.getParent(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the parent bucket")
+	}
+
+	if err := parent.DeleteBucket(path[len(path)-1].Bytes()); err != nil {
+		if errors.Is(err, bbolt.ErrBucketNotFound) || errors.Is(err, bbolt.ErrIncompatibleValue) {
+			return application.ErrBucketNotFound
+		}
+		return errors.Wrap(err, "delete bucket failed")
+	}
+
+	return nil
+}
+
+func (d *Database) MoveBucket(source, destination []application.Key) error {
+	sourceBucket, err := d.getBucket(source)
+	if err != nil {
+		return errors.Wrap(err, "could not get the source bucket")
+	}
+
+	destinationBucket, err := d.createBucket(destination)
+	if err != nil {
+		return errors.Wrap(err, "could not create the destination bucket")
+	}
+
+	if err := copyBucket(destinationBucket, sourceBucket); err != nil {
+		return errors.Wrap(err, "could not copy the bucket")
+	}
+
+	if err := d.DeleteBucket(source); err != nil {
+		return errors.Wrap(err, "could not delete the source bucket")
+	}
+
+	return nil
+}
+
+func (d *Database) Walk(path []application.Key, recursive bool, fn application.WalkFn) error {
+	if len(path) == 0 {
+		return d.tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			return walkEntry(bucket, nil, name, nil, recursive, fn)
+		})
+	}
+
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	return walkBucket(bucket, path, recursive, fn)
+}
+
+func (d *Database) Check(fn application.CheckFn) error {
+	var fnErr error
+
+	// the channel has to be drained even if fn returns an error as
+	// otherwise the goroutine performing the check would never exit
+	for problem := range d.tx.Check() {
+		if fnErr == nil {
+			fnErr = fn(problem)
+		}
+	}
+
+	return fnErr
+}
+
+func (d *Database) Size() int64 {
+	return d.tx.Size()
+}
+
+func (d *Database) TxID() int {
+	if d.tx.Writable() {
+		return d.tx.ID() - 1
+	}
+	return d.tx.ID()
+}
+
+func (d *Database) WriteTo(w io.Writer) (int64, error) {
+	return d.tx.WriteTo(w)
+}
+
+func walkBucket(bucket *bbolt.Bucket, path []application.Key, recursive bool, fn application.WalkFn) error {
+	return bucket.ForEach(func(k, v []byte) error {
+		return walkEntry(bucket.Bucket(k), path, k, v, recursive, fn)
+	})
+}
+
+// walkEntry calls fn for the entry and walks the nested bucket if it is not
+// nil and recursive is set.
+func walkEntry(nested *bbolt.Bucket, path []application.Key, k, v []byte, recursive bool, fn application.WalkFn) error {
+	isBucket := func(_ []byte) bool {
+		return nested != nil
+	}
+
+	entry, err := newEntry(isBucket, k, v)
+	if err != nil {
+		return errors.Wrap(err, "could not create an entry")
+	}
+
+	if err := fn(path, entry); err != nil {
+		return err
+	}
+
+	if recursive && entry.Bucket {
+		nestedPath := make([]application.Key, len(path), len(path)+1)
+		copy(nestedPath, path)
+		nestedPath = append(nestedPath, entry.Key)
+
+		return walkBucket(nested, nestedPath, recursive, fn)
+	}
+
+	return nil
+}
+
+func (d *Database) BucketSequence(path []application.Key) (uint64, error) {
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get the bucket")
+	}
+
+	return bucket.Sequence(), nil
+}
+
+func (d *Database) SetBucketSequence(path []application.Key, sequence uint64) error {
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket")
+	}
+
+	return bucket.SetSequence(sequence)
+}
+
+func (d *Database) BucketStats(path []application.Key) (application.BucketStats, error) {
+	bucket, err := d.getBucket(path)
+	if err != nil {
+		return application.BucketStats{}, errors.Wrap(err, "could not get the bucket")
+	}
+
+	stats := bucket.Stats()
+
+	return application.BucketStats{
+		BranchPageN:       stats.BranchPageN,
+		BranchOverflowN:   stats.BranchOverflowN,
+		LeafPageN:         stats.LeafPageN,
+		LeafOverflowN:     stats.LeafOverflowN,
+		KeyN:              stats.KeyN,
+		Depth:             stats.Depth,
+		BranchAlloc:       stats.BranchAlloc,
+		BranchInuse:       stats.BranchInuse,
+		LeafAlloc:         stats.LeafAlloc,
+		LeafInuse:         stats.LeafInuse,
+		BucketN:           stats.BucketN,
+		InlineBucketN:     stats.InlineBucketN,
+		InlineBucketInuse: stats.InlineBucketInuse,
+	}, nil
+}
+
+func (d *Database) createBucket(path []application.Key) (*bbolt.Bucket, error) {
+	parent, err := d.getParent(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the parent bucket")
+	}
+
+	bucket, err := parent.CreateBucket(path[len(path)-1].Bytes())
+	if err != nil {
+		if errors.Is(err, bbolt.ErrBucketExists) || errors.Is(err, bbolt.ErrIncompatibleValue) {
+			return nil, application.ErrKeyAlreadyExists
+		}
+		return nil, errors.Wrap(err, "create bucket failed")
+	}
+
+	return bucket, nil
+}
+
+// getParent returns the bucket containing the last element of the path.
+func (d *Database) getParent(path []application.Key) (bucketCreatorDeleter, error) {
+	if len(path) == 1 {
+		return d.tx, nil
+	}
+
+	return d.getBucket(path[:len(path)-1])
+}
+
+type bucketCreatorDeleter interface {
+	CreateBucket(key []byte) (*bbolt.Bucket, error)
+	DeleteBucket(key []byte) error
+}
+
+func copyBucket(dst, src *bbolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return errors.Wrap(err, "could not set the sequence")
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		if nested := src.Bucket(k); nested != nil {
+			nestedDst, err := dst.CreateBucket(k)
+			if err != nil {
+				return errors.Wrap(err, "could not create a nested bucket")
+			}
+			return copyBucket(nestedDst, nested)
+		}
+
+		if err := dst.Put(k, v); err != nil {
+			return errors.Wrap(err, "could not put a value")
+		}
+
+		return nil
+	})
 }
 
-func (d *Database) iterate(c *bbolt.Cursor, before, after, from *application.Key, isBucket isBucketFn) ([]application.Entry, error) {
-	if before != nil {
-		return iterBefore(c, *before, isBucket)
+func (d *Database) iterate(c *bbolt.Cursor, before, after, from *application.Key, prefix []byte, limit int, isBucket isBucketFn) (application.Page, error) {
+	var entries []application.Entry
+	var err error
+
+	switch {
+	case before != nil:
+		entries, err = iterBefore(c, *before, prefix, limit, isBucket)
+	case after != nil:
+		entries, err = iterAfter(c, *after, prefix, limit, isBucket)
+	case from != nil:
+		entries, err = iterFrom(c, *from, prefix, limit, isBucket)
+	default:
+		entries, err = iter(c, prefix, limit, isBucket)
 	}
 
-	if after != nil {
-		return iterAfter(c, *after, isBucket)
+	if err != nil {
+		return application.Page{}, errors.Wrap(err, "iteration failed")
 	}
 
-	if from != nil {
-		return iterFrom(c, *from, isBucket)
+	page := application.Page{
+		Entries: entries,
 	}
 
-	return iter(c, isBucket)
+	if len(entries) == 0 {
+		// if nothing was found then all keys are either before or after
+		// the requested position
+		k, _ := first(c, prefix)
+		if before != nil {
+			page.HasNext = hasPrefix(k, prefix)
+		} else {
+			page.HasPrevious = hasPrefix(k, prefix)
+		}
+		return page, nil
+	}
+
+	c.Seek(entries[0].Key.Bytes())
+	k, _ := c.Prev()
+	page.HasPrevious = hasPrefix(k, prefix)
+
+	c.Seek(entries[len(entries)-1].Key.Bytes())
+	k, _ = c.Next()
+	page.HasNext = hasPrefix(k, prefix)
+
+	return page, nil
 }
 
 func (d *Database) getBucket(path []application.Key) (*bbolt.Bucket, error) {
@@ -69,12 +382,12 @@ func (d *Database) getBucket(path []application.Key) (*bbolt.Bucket, error) {
 	return bucket, nil
 }
 
-func iterBefore(c *bbolt.Cursor, before application.Key, isBucket isBucketFn) ([]application.Entry, error) {
+func iterBefore(c *bbolt.Cursor, before application.Key, prefix []byte, limit int, isBucket isBucketFn) ([]application.Entry, error) {
 	var entries []application.Entry
 
 	c.Seek(before.Bytes())
 
-	for key, value := c.Prev(); key != nil; key, value = c.Prev() {
+	for key, value := c.Prev(); hasPrefix(key, prefix); key, value = c.Prev() {
 		entry, err := newEntry(isBucket, key, value)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create an entry")
@@ -82,7 +395,7 @@ func iterBefore(c *bbolt.Cursor, before application.Key, isBucket isBucketFn) ([
 
 		entries = append(entries, entry)
 
-		if len(entries) >= perPage {
+		if len(entries) >= limit {
 			break
 		}
 	}
@@ -94,12 +407,12 @@ func iterBefore(c *bbolt.Cursor, before application.Key, isBucket isBucketFn) ([
 	return entries, nil
 }
 
-func iterAfter(c *bbolt.Cursor, after application.Key, isBucket isBucketFn) ([]application.Entry, error) {
+func iterAfter(c *bbolt.Cursor, after application.Key, prefix []byte, limit int, isBucket isBucketFn) ([]application.Entry, error) {
 	var entries []application.Entry
 
 	c.Seek(after.Bytes())
 
-	for key, value := c.Next(); key != nil; key, value = c.Next() {
+	for key, value := c.Next(); hasPrefix(key, prefix); key, value = c.Next() {
 		entry, err := newEntry(isBucket, key, value)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create an entry")
@@ -107,7 +420,7 @@ func iterAfter(c *bbolt.Cursor, after application.Key, isBucket isBucketFn) ([]a
 
 		entries = append(entries, entry)
 
-		if len(entries) >= perPage {
+		if len(entries) >= limit {
 			break
 		}
 	}
@@ -115,10 +428,10 @@ func iterAfter(c *bbolt.Cursor, after application.Key, isBucket isBucketFn) ([]a
 	return entries, nil
 }
 
-func iterFrom(c *bbolt.Cursor, after application.Key, isBucket isBucketFn) ([]application.Entry, error) {
+func iterFrom(c *bbolt.Cursor, after application.Key, prefix []byte, limit int, isBucket isBucketFn) ([]application.Entry, error) {
 	var entries []application.Entry
 
-	for key, value := c.Seek(after.Bytes()); key != nil; key, value = c.Next() {
+	for key, value := c.Seek(after.Bytes()); hasPrefix(key, prefix); key, value = c.Next() {
 		entry, err := newEntry(isBucket, key, value)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create an entry")
@@ -126,7 +439,7 @@ func iterFrom(c *bbolt.Cursor, after application.Key, isBucket isBucketFn) ([]ap
 
 		entries = append(entries, entry)
 
-		if len(entries) >= perPage {
+		if len(entries) >= limit {
 			break
 		}
 	}
@@ -134,10 +447,10 @@ func iterFrom(c *bbolt.Cursor, after application.Key, isBucket isBucketFn) ([]ap
 	return entries, nil
 }
 
-func iter(c *bbolt.Cursor, isBucket isBucketFn) ([]application.Entry, error) {
+func iter(c *bbolt.Cursor, prefix []byte, limit int, isBucket isBucketFn) ([]application.Entry, error) {
 	var entries []application.Entry
 
-	for key, value := c.First(); key != nil; key, value = c.Next() {
+	for key, value := first(c, prefix); hasPrefix(key, prefix); key, value = c.Next() {
 		entry, err := newEntry(isBucket, key, value)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create an entry")
@@ -145,7 +458,7 @@ func iter(c *bbolt.Cursor, isBucket isBucketFn) ([]application.Entry, error) {
 
 		entries = append(entries, entry)
 
-		if len(entries) >= perPage {
+		if len(entries) >= limit {
 			break
 		}
 	}
@@ -153,6 +466,21 @@ func iter(c *bbolt.Cursor, isBucket isBucketFn) ([]application.Entry, error) {
 	return entries, nil
 }
 
+// first moves the cursor to the first key which starts with the prefix. If
+// the prefix is nil then it moves the cursor to the first key.
+func first(c *bbolt.Cursor, prefix []byte) ([]byte, []byte) {
+	if prefix == nil {
+		return c.First()
+	}
+	return c.Seek(prefix)
+}
+
+// hasPrefix returns false for nil keys which are returned by the cursor if
+// there are no more keys.
+func hasPrefix(key, prefix []byte) bool {
+	return key != nil && bytes.HasPrefix(key, prefix)
+}
+
 type isBucketFn func(k []byte) bool
 
 func isAlwaysBucket(_ []byte) bool {
@@ -0,0 +1,184 @@
+package bolt
+
+import (
+	"bytes"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucket is implemented by both *bolt.Tx and *bolt.Bucket so that the root
+// of the database can be compared in the same way as nested buckets.
+type bucket interface {
+	Cursor() *bolt.Cursor
+	Bucket(name []byte) *bolt.Bucket
+}
+
+// Diff compares the bucket specified by the path in the old database with the
+// same bucket in the new database. Both bucket trees are walked in key order
+// using parallel cursors. Entries which exist only in the old database are
+// reported as removed, entries which exist only in the new database are
+// reported as added and entries which exist in both databases but differ are
+// reported as modified. If a bucket is replaced with a value or the other way
+// around then the entry is reported as modified and the contents of the
+// bucket are reported as removed or added. It returns ErrBucketNotFound if the
+// bucket doesn't exist in either of the databases.
+func Diff(old, new *bolt.DB, path []application.Key, fn application.DiffFn) error {
+	return old.View(func(oldTx *bolt.Tx) error {
+		return new.View(func(newTx *bolt.Tx) error {
+			oldBucket := lookupBucket(oldTx, path)
+			newBucket := lookupBucket(newTx, path)
+
+			if oldBucket == nil && newBucket == nil {
+				return application.ErrBucketNotFound
+			}
+
+			return diffBuckets(path, oldBucket, newBucket, fn)
+		})
+	})
+}
+
+// lookupBucket returns nil if the bucket specified by the path doesn't exist.
+func lookupBucket(tx *bolt.Tx, path []application.Key) bucket {
+	var b bucket = tx
+	for _, key := range path {
+		child := b.Bucket(key.Bytes())
+		if child == nil {
+			return nil
+		}
+		b = child
+	}
+	return b
+}
+
+// diffBuckets compares the contents of two buckets. Either of the buckets can
+// be nil in which case all entries of the other bucket are reported as added
+// or removed.
+func diffBuckets(path []application.Key, old, new bucket, fn application.DiffFn) error {
+	var oldCursor, newCursor *bolt.Cursor
+	var oldKey, oldValue, newKey, newValue []byte
+
+	if old != nil {
+		oldCursor = old.Cursor()
+		oldKey, oldValue = oldCursor.First()
+	}
+
+	if new != nil {
+		newCursor = new.Cursor()
+		newKey, newValue = newCursor.First()
+	}
+
+	for oldKey != nil || newKey != nil {
+		switch {
+		case newKey == nil || (oldKey != nil && bytes.Compare(oldKey, newKey) < 0):
+			if err := diffEntry(path, oldKey, old, nil, oldValue, nil, fn); err != nil {
+				return errors.Wrap(err, "could not report a removed entry")
+			}
+			oldKey, oldValue = oldCursor.Next()
+		case oldKey == nil || bytes.Compare(oldKey, newKey) > 0:
+			if err := diffEntry(path, newKey, nil, new, nil, newValue, fn); err != nil {
+				return errors.Wrap(err, "could not report an added entry")
+			}
+			newKey, newValue = newCursor.Next()
+		default:
+			if err := diffEntry(path, oldKey, old, new, oldValue, newValue, fn); err != nil {
+				return errors.Wrap(err, "could not compare an entry")
+			}
+			oldKey, oldValue = oldCursor.Next()
+			newKey, newValue = newCursor.Next()
+		}
+	}
+
+	return nil
+}
+
+// diffEntry compares the entry stored under the key in the old and new parent
+// buckets. A parent is nil if the entry doesn't exist on that side. Nested
+// buckets are compared recursively after the entry is reported.
+func diffEntry(path []application.Key, key []byte, oldParent, newParent bucket, oldValue, newValue []byte, fn application.DiffFn) error {
+	oldState, oldChild, err := entryState(oldParent, key, oldValue)
+	if err != nil {
+		return errors.Wrap(err, "could not get the old state")
+	}
+
+	newState, newChild, err := entryState(newParent, key, newValue)
+	if err != nil {
+		return errors.Wrap(err, "could not get the new state")
+	}
+
+	entry := application.DiffEntry{
+		Path: path,
+		Key:  application.MustNewKey(key),
+		Old:  oldState,
+		New:  newState,
+	}
+
+	switch {
+	case newState == nil:
+		entry.Kind = application.DiffKindRemoved
+	case oldState == nil:
+		entry.Kind = application.DiffKindAdded
+	case !statesEqual(*oldState, *newState):
+		entry.Kind = application.DiffKindModified
+	}
+
+	if entry.Kind != (application.DiffKind{}) {
+		if err := fn(entry); err != nil {
+			return errors.Wrap(err, "callback returned an error")
+		}
+	}
+
+	if oldChild != nil || newChild != nil {
+		childPath := make([]application.Key, len(path), len(path)+1)
+		copy(childPath, path)
+		childPath = append(childPath, entry.Key)
+
+		if err := diffBuckets(childPath, oldChild, newChild, fn); err != nil {
+			return errors.Wrap(err, "could not compare the nested buckets")
+		}
+	}
+
+	return nil
+}
+
+// entryState returns nil if the parent is nil. If the entry is a bucket then
+// the bucket is returned as well.
+func entryState(parent bucket, key, value []byte) (*application.DiffEntryState, bucket, error) {
+	if parent == nil {
+		return nil, nil, nil
+	}
+
+	if value == nil {
+		child := parent.Bucket(key)
+		if child == nil {
+			return nil, nil, errors.New("bucket not found")
+		}
+
+		return &application.DiffEntryState{
+			Bucket:   true,
+			Sequence: child.Sequence(),
+		}, child, nil
+	}
+
+	v, err := application.NewValue(value)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create a value")
+	}
+
+	return &application.DiffEntryState{
+		Value: v,
+	}, nil, nil
+}
+
+func statesEqual(a, b application.DiffEntryState) bool {
+	if a.Bucket != b.Bucket {
+		return false
+	}
+
+	if a.Bucket {
+		return a.Sequence == b.Sequence
+	}
+
+	return bytes.Equal(a.Value.Bytes(), b.Value.Bytes())
+}
@@ -0,0 +1,425 @@
+package bolt
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/logging"
+	"github.com/boreq/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// WatchInterval specifies how often Watch checks if the database file was
+// replaced.
+const WatchInterval = 1 * time.Second
+
+// Source provides access to a database which can be replaced while the
+// program is running. Depending on the mode it either serves the database
+// file directly or serves a snapshot of it.
+type Source struct {
+	path         string
+	readOnly     bool
+	snapshotMode bool
+	external     bool
+
+	mutex      sync.RWMutex
+	db         *bolt.DB
+	fileInfo   os.FileInfo
+	openedAt   time.Time
+	reopenedAt time.Time
+
+	log logging.Logger
+}
+
+// NewSource opens the database file and serves it directly.
+func NewSource(path string, readOnly bool) (*Source, error) {
+	s := &Source{
+		path:     path,
+		readOnly: readOnly,
+		log:      logging.New("adapters/bolt.Source"),
+	}
+
+	db, fileInfo, err := s.open()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open the database")
+	}
+
+	s.db = db
+	s.fileInfo = fileInfo
+	s.openedAt = time.Now()
+	return s, nil
+}
+
+// NewExternalSource serves a database which was opened by someone else. The
+// database is never reopened or closed.
+func NewExternalSource(db *bolt.DB) (*Source, error) {
+	fileInfo, err := os.Stat(db.Path())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not stat the database file")
+	}
+
+	return &Source{
+		path:     db.Path(),
+		readOnly: db.IsReadOnly(),
+		external: true,
+		db:       db,
+		fileInfo: fileInfo,
+		openedAt: time.Now(),
+		log:      logging.New("adapters/bolt.Source"),
+	}, nil
+}
+
+// NewSnapshotSource takes a snapshot of the database file and serves it
+// instead of the file itself.
+func NewSnapshotSource(path string) (*Source, error) {
+	s := &Source{
+		path:         path,
+		readOnly:     true,
+		snapshotMode: true,
+		log:          logging.New("adapters/bolt.Source"),
+	}
+
+	db, fileInfo, err := s.snapshot()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not take a snapshot")
+	}
+
+	s.db = db
+	s.fileInfo = fileInfo
+	s.openedAt = time.Now()
+	return s, nil
+}
+
+func (s *Source) View(fn func(tx *bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.db.View(fn)
+}
+
+func (s *Source) Update(fn func(tx *bolt.Tx) error) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.db.Update(fn)
+}
+
+// TakeSnapshot replaces the currently served snapshot with a new one. The
+// old snapshot is closed once all transactions using it finish.
+func (s *Source) TakeSnapshot() error {
+	if !s.snapshotMode {
+		return application.ErrSnapshotsDisabled
+	}
+
+	db, fileInfo, err := s.snapshot()
+	if err != nil {
+		return errors.Wrap(err, "could not take a snapshot")
+	}
+
+	if err := s.replace(db, fileInfo, false); err != nil {
+		return errors.Wrap(err, "could not replace the snapshot")
+	}
+
+	return nil
+}
+
+// Reopen opens the database file again so that the database file which
+// replaced the previously opened one is served. In the snapshot mode a new
+// snapshot is taken instead. The old database is closed once all
+// transactions using it finish. Outside of the snapshot mode nothing happens
+// if the database file wasn't replaced as the file can't be opened again
+// while the old database still holds a lock on it.
+func (s *Source) Reopen() error {
+	if s.external {
+		return application.ErrReopenDisabled
+	}
+
+	if !s.snapshotMode {
+		replaced, err := s.replaced()
+		if err != nil {
+			return errors.Wrap(err, "could not check if the file was replaced")
+		}
+
+		if !replaced {
+			return nil
+		}
+	}
+
+	db, fileInfo, err := s.open()
+	if err != nil {
+		return errors.Wrap(err, "could not open the database")
+	}
+
+	if err := s.replace(db, fileInfo, true); err != nil {
+		return errors.Wrap(err, "could not replace the database")
+	}
+
+	return nil
+}
+
+// Close waits for all transactions to finish and closes the served database.
+// In the snapshot mode the snapshot file is removed. Databases which weren't
+// opened by the source aren't closed.
+func (s *Source) Close() error {
+	if s.external {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.snapshotMode {
+		if err := closeSnapshot(s.db); err != nil {
+			return errors.Wrap(err, "could not close the snapshot")
+		}
+		return nil
+	}
+
+	if err := s.db.Close(); err != nil {
+		return errors.Wrap(err, "could not close the database")
+	}
+
+	return nil
+}
+
+// Watch periodically checks if the database file was replaced, for example
+// by renaming a new file over it, and reopens it if it was. Watch returns
+// when the context is cancelled or immediately if the database wasn't opened
+// by the source.
+func (s *Source) Watch(ctx context.Context) {
+	if s.external {
+		return
+	}
+
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			replaced, err := s.replaced()
+			if err != nil {
+				s.log.Debug("could not check if the file was replaced", "err", err)
+				continue
+			}
+
+			if replaced {
+				if err := s.Reopen(); err != nil {
+					s.log.Error("could not reopen the database", "err", err)
+					continue
+				}
+				s.log.Info("reopened the database", "file", s.path)
+			}
+		}
+	}
+}
+
+// replaced returns true if the file located at the path is not the file
+// which was opened.
+func (s *Source) replaced() (bool, error) {
+	fileInfo, err := os.Stat(s.path)
+	if err != nil {
+		return false, errors.Wrap(err, "stat failed")
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return !os.SameFile(s.fileInfo, fileInfo), nil
+}
+
+// replace waits for all transactions using the old database to finish,
+// starts serving the new database and closes the old one.
+func (s *Source) replace(db *bolt.DB, fileInfo os.FileInfo, reopened bool) error {
+	s.mutex.Lock()
+	oldDB := s.db
+	s.db = db
+	s.fileInfo = fileInfo
+	s.openedAt = time.Now()
+	if reopened {
+		s.reopenedAt = s.openedAt
+	}
+	s.mutex.Unlock()
+
+	if s.snapshotMode {
+		if err := closeSnapshot(oldDB); err != nil {
+			return errors.Wrap(err, "could not close the old snapshot")
+		}
+		return nil
+	}
+
+	if err := oldDB.Close(); err != nil {
+		return errors.Wrap(err, "could not close the old database")
+	}
+
+	return nil
+}
+
+// open opens the database file or takes a snapshot of it depending on the
+// mode. The returned file info describes the database file.
+func (s *Source) open() (*bolt.DB, os.FileInfo, error) {
+	if s.snapshotMode {
+		return s.snapshot()
+	}
+
+	fileInfo, err := os.Stat(s.path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not stat the database file")
+	}
+
+	db, err := NewBolt(s.path, s.readOnly)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not open the database")
+	}
+
+	return db, fileInfo, nil
+}
+
+func (s *Source) Info() application.SourceInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return application.SourceInfo{
+		SnapshotMode: s.snapshotMode,
+		OpenedAt:     s.openedAt,
+		ReopenedAt:   s.reopenedAt,
+	}
+}
+
+func (s *Source) DatabaseInfo() (application.DatabaseInfo, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	fileInfo, err := os.Stat(s.db.Path())
+	if err != nil {
+		return application.DatabaseInfo{}, errors.Wrap(err, "could not stat the database file")
+	}
+
+	stats := s.db.Stats()
+
+	return application.DatabaseInfo{
+		FileSize:      fileInfo.Size(),
+		PageSize:      s.db.Info().PageSize,
+		FreePageN:     stats.FreePageN,
+		PendingPageN:  stats.PendingPageN,
+		FreeAlloc:     stats.FreeAlloc,
+		FreelistInuse: stats.FreelistInuse,
+		TxN:           stats.TxN,
+		OpenTxN:       stats.OpenTxN,
+		Tx: application.TxStats{
+			PageCount:     stats.TxStats.GetPageCount(),
+			PageAlloc:     stats.TxStats.GetPageAlloc(),
+			CursorCount:   stats.TxStats.GetCursorCount(),
+			NodeCount:     stats.TxStats.GetNodeCount(),
+			NodeDeref:     stats.TxStats.GetNodeDeref(),
+			Rebalance:     stats.TxStats.GetRebalance(),
+			RebalanceTime: stats.TxStats.GetRebalanceTime(),
+			Split:         stats.TxStats.GetSplit(),
+			Spill:         stats.TxStats.GetSpill(),
+			SpillTime:     stats.TxStats.GetSpillTime(),
+			Write:         stats.TxStats.GetWrite(),
+			WriteTime:     stats.TxStats.GetWriteTime(),
+		},
+	}, nil
+}
+
+func (s *Source) Compact(destination string, fn application.CompactionProgressFn) (application.CompactionResult, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return Compact(s.db, destination, fn)
+}
+
+// Diff compares the served database with the database file located at the
+// other path. If other is empty then the served snapshot is compared with the
+// database file itself. The other database is opened in read-only mode for
+// the duration of the comparison.
+func (s *Source) Diff(other string, path []application.Key, fn application.DiffFn) error {
+	if other == "" {
+		if !s.snapshotMode {
+			return application.ErrSnapshotsDisabled
+		}
+		other = s.path
+	}
+
+	otherDB, err := NewBolt(other, true)
+	if err != nil {
+		return errors.Wrap(err, "could not open the other database")
+	}
+	defer otherDB.Close()
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return Diff(s.db, otherDB, path, fn)
+}
+
+// snapshot copies the database file to a temporary file using a read
+// transaction and opens the copy. The database file is only kept open while
+// the copy is being made. The returned file info describes the database
+// file.
+func (s *Source) snapshot() (*bolt.DB, os.FileInfo, error) {
+	start := time.Now()
+
+	fileInfo, err := os.Stat(s.path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not stat the database file")
+	}
+
+	file, err := os.CreateTemp("", "bolt-ui-snapshot-*.db")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not create a temporary file")
+	}
+
+	if err := s.copyTo(file); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, nil, errors.Wrap(err, "could not copy the database")
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return nil, nil, errors.Wrap(err, "could not close the temporary file")
+	}
+
+	db, err := NewBolt(file.Name(), true)
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, nil, errors.Wrap(err, "could not open the snapshot")
+	}
+
+	s.log.Debug("took a snapshot", "file", file.Name(), "duration", time.Since(start))
+	return db, fileInfo, nil
+}
+
+func (s *Source) copyTo(file *os.File) error {
+	db, err := NewBolt(s.path, true)
+	if err != nil {
+		return errors.Wrap(err, "could not open the database")
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(file)
+		return err
+	})
+}
+
+func closeSnapshot(db *bolt.DB) error {
+	path := db.Path()
+
+	if err := db.Close(); err != nil {
+		return errors.Wrap(err, "could not close the database")
+	}
+
+	if err := os.Remove(path); err != nil {
+		return errors.Wrap(err, "could not remove the file")
+	}
+
+	return nil
+}
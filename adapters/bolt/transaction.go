@@ -10,13 +10,19 @@ type AdaptersProvider interface {
 	Provide(tx *bolt.Tx) (*application.TransactableAdapters, error)
 }
 
+// DB is implemented by *bolt.DB and by types which wrap it.
+type DB interface {
+	View(fn func(tx *bolt.Tx) error) error
+	Update(fn func(tx *bolt.Tx) error) error
+}
+
 type TransactionProvider struct {
-	db       *bolt.DB
+	db       DB
 	provider AdaptersProvider
 }
 
 func NewTransactionProvider(
-	db *bolt.DB,
+	db DB,
 	provider AdaptersProvider,
 ) *TransactionProvider {
 	return &TransactionProvider{
@@ -1,6 +1,10 @@
 package application
 
-import "errors"
+import (
+	"errors"
+	"io"
+	"time"
+)
 
 type Key struct {
 	b []byte
@@ -59,26 +63,202 @@ func (v Value) IsEmpty() bool {
 }
 
 type Tree struct {
-	Path    []Key
+	Path        []Key
+	Entries     []Entry
+	HasPrevious bool
+	HasNext     bool
+}
+
+type Page struct {
 	Entries []Entry
+
+	// HasPrevious is set if there are entries before the returned
+	// entries.
+	HasPrevious bool
+
+	// HasNext is set if there are entries after the returned entries.
+	HasNext bool
 }
 
-var ErrBucketNotFound = errors.New("err bucket not found")
+var (
+	ErrBucketNotFound     = errors.New("err bucket not found")
+	ErrKeyNotFound        = errors.New("err key not found")
+	ErrKeyIsABucket       = errors.New("err key is a bucket")
+	ErrKeyAlreadyExists   = errors.New("err key already exists")
+	ErrWritesDisabled     = errors.New("err writes disabled")
+	ErrSnapshotsDisabled  = errors.New("err snapshots disabled")
+	ErrReopenDisabled     = errors.New("err reopen disabled")
+	ErrCompactionDisabled = errors.New("err compaction disabled")
+	ErrDiffDisabled       = errors.New("err diff disabled")
+	ErrDestinationExists  = errors.New("err destination exists")
+	ErrInvalidEntry       = errors.New("err invalid entry")
+)
 
 type Database interface {
-	// Browse returns ErrBucketNotFound if the bucket specified by the path
-	// does not exist.
-	Browse(path []Key, before, after, from *Key) ([]Entry, error)
+	// Browse returns at most limit entries. If prefix is not nil then
+	// only entries with keys starting with that prefix are returned. It
+	// returns ErrBucketNotFound if the bucket specified by the path does
+	// not exist.
+	Browse(path []Key, before, after, from, prefix *Key, limit int) (Page, error)
+
+	// Get returns ErrBucketNotFound if the bucket specified by the path
+	// does not exist and ErrKeyNotFound if the key does not exist.
+	Get(path []Key, key Key) (Entry, error)
+
+	// Put returns ErrBucketNotFound if the bucket specified by the path
+	// does not exist and ErrKeyIsABucket if the key refers to a bucket.
+	Put(path []Key, key Key, value Value) error
+
+	// Delete returns ErrBucketNotFound if the bucket specified by the path
+	// does not exist, ErrKeyNotFound if the key does not exist and
+	// ErrKeyIsABucket if the key refers to a bucket.
+	Delete(path []Key, key Key) error
+
+	// CreateBucket returns ErrBucketNotFound if the parent bucket does not
+	// exist and ErrKeyAlreadyExists if the key is already in use.
+	CreateBucket(path []Key) error
+
+	// DeleteBucket removes the bucket and all of its contents. It returns
+	// ErrBucketNotFound if the bucket specified by the path does not exist.
+	DeleteBucket(path []Key) error
+
+	// MoveBucket copies the bucket and all of its contents including the
+	// sequences of all nested buckets to the destination and then removes
+	// the source bucket. It returns ErrBucketNotFound if the source bucket
+	// or the parent of the destination bucket don't exist and
+	// ErrKeyAlreadyExists if the destination key is already in use.
+	MoveBucket(source, destination []Key) error
+
+	// Walk calls fn for every entry in the bucket specified by the path in
+	// key order. If recursive is set then the nested buckets are walked as
+	// well right after fn is called for the entry representing them. Walk
+	// stops and returns the error returned by fn if it isn't nil. It
+	// returns ErrBucketNotFound if the bucket specified by the path does
+	// not exist.
+	Walk(path []Key, recursive bool, fn WalkFn) error
+
+	// BucketStats returns ErrBucketNotFound if the bucket specified by the
+	// path does not exist.
+	BucketStats(path []Key) (BucketStats, error)
+
+	// BucketSequence returns ErrBucketNotFound if the bucket specified by
+	// the path does not exist.
+	BucketSequence(path []Key) (uint64, error)
+
+	// SetBucketSequence returns ErrBucketNotFound if the bucket specified
+	// by the path does not exist.
+	SetBucketSequence(path []Key, sequence uint64) error
+
+	// Check performs a consistency check of the database and calls fn for
+	// every problem that is found. If fn returns an error then it isn't
+	// called again and the error is returned once the entire database was
+	// checked.
+	Check(fn CheckFn) error
+
+	// Size returns the size of the database as seen by the current
+	// transaction.
+	Size() int64
+
+	// TxID returns the ID of the last committed write transaction as seen
+	// by the current transaction.
+	TxID() int
+
+	// WriteTo writes the entire database to the writer.
+	WriteTo(w io.Writer) (int64, error)
 }
 
+// WalkFn is called with the path to the bucket containing the entry.
+type WalkFn func(path []Key, entry Entry) error
+
+// CheckFn is called with a problem found during a consistency check.
+type CheckFn func(problem error) error
+
 type Entry struct {
 	Bucket bool
 	Key    Key
 	Value  Value
 }
 
+// Source represents the origin of the data served by the application.
+type Source interface {
+	// TakeSnapshot replaces the currently served snapshot with a new one.
+	// It returns ErrSnapshotsDisabled if the application doesn't serve
+	// snapshots.
+	TakeSnapshot() error
+
+	Info() SourceInfo
+
+	// DatabaseInfo returns the current statistics of the served
+	// database.
+	DatabaseInfo() (DatabaseInfo, error)
+
+	// Compact writes a defragmented copy of the served database to a new
+	// file. It returns ErrDestinationExists if the destination file
+	// already exists.
+	Compact(destination string, fn CompactionProgressFn) (CompactionResult, error)
+
+	// Reopen opens the database file again so that a file which replaced
+	// the previously opened one is served. In the snapshot mode a new
+	// snapshot is taken instead. It returns ErrReopenDisabled if the
+	// database wasn't opened by the application.
+	Reopen() error
+
+	// Diff compares the bucket specified by the path in the served
+	// database with the same bucket in the database file located at the
+	// other path. If other is empty then the served snapshot is compared
+	// with the database file and ErrSnapshotsDisabled is returned if the
+	// application doesn't serve snapshots. It returns ErrBucketNotFound if
+	// the bucket doesn't exist in either of the databases.
+	Diff(other string, path []Key, fn DiffFn) error
+}
+
+type SourceInfo struct {
+	// SnapshotMode is set if the application serves a snapshot of the
+	// database instead of the database file itself.
+	SnapshotMode bool
+
+	// OpenedAt is the time at which the database was opened or, in the
+	// snapshot mode, the time at which the current snapshot was taken.
+	OpenedAt time.Time
+
+	// ReopenedAt is the time at which the database was last reopened. It
+	// is zero if the database was never reopened.
+	ReopenedAt time.Time
+}
+
 type Application struct {
-	Browse *BrowseHandler
+	Browse       *BrowseHandler
+	Put          *PutHandler
+	Delete       *DeleteHandler
+	CreateBucket *CreateBucketHandler
+	DeleteBucket *DeleteBucketHandler
+	MoveBucket   *MoveBucketHandler
+
+	GetSourceInfo *GetSourceInfoHandler
+	TakeSnapshot  *TakeSnapshotHandler
+	Reopen        *ReopenHandler
+
+	Search *SearchHandler
+	Query  *QueryHandler
+
+	GetBucketStats   *GetBucketStatsHandler
+	GetDatabaseStats *GetDatabaseStatsHandler
+	GetDatabaseInfo  *GetDatabaseInfoHandler
+
+	CheckConsistency *CheckConsistencyHandler
+	Compact          *CompactHandler
+	Backup           *BackupHandler
+	Export           *ExportHandler
+	Import           *ImportHandler
+	Diff             *DiffHandler
+	WatchBucket      *WatchBucketHandler
+}
+
+// Permissions specify which operations can be performed by the application.
+type Permissions struct {
+	Write   bool
+	Compact bool
+	Diff    bool
 }
 
 type TransactionProvider interface {
@@ -0,0 +1,45 @@
+package application
+
+import (
+	"io"
+
+	"github.com/boreq/errors"
+)
+
+// BackupFn is called with the size of the backup in bytes before the backup
+// is written to the returned writer.
+type BackupFn func(size int64) (io.Writer, error)
+
+type Backup struct {
+}
+
+type BackupHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewBackupHandler(transactionProvider TransactionProvider) *BackupHandler {
+	return &BackupHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute writes a consistent copy of the entire database using a single read
+// transaction.
+func (h *BackupHandler) Execute(cmd Backup, fn BackupFn) error {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		w, err := fn(adapters.Database.Size())
+		if err != nil {
+			return errors.Wrap(err, "could not get the writer")
+		}
+
+		if _, err := adapters.Database.WriteTo(w); err != nil {
+			return errors.Wrap(err, "could not write the database")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
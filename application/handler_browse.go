@@ -1,17 +1,26 @@
 package application
 
 import (
+	"bytes"
+
 	"github.com/boreq/errors"
 )
 
+const (
+	DefaultLimit = 10
+	MaxLimit     = 1000
+)
+
 type Browse struct {
 	path   []Key
 	before *Key
 	after  *Key
 	from   *Key
+	prefix *Key
+	limit  int
 }
 
-func NewBrowse(path []Key, before *Key, after *Key, from *Key) (Browse, error) {
+func NewBrowse(path []Key, before *Key, after *Key, from *Key, prefix *Key, limit int) (Browse, error) {
 	var counter int
 	if before != nil {
 		counter++
@@ -25,17 +34,29 @@ func NewBrowse(path []Key, before *Key, after *Key, from *Key) (Browse, error) {
 	if counter > 1 {
 		return Browse{}, errors.New("passed more than one before/after/from at the same time")
 	}
+	if limit <= 0 || limit > MaxLimit {
+		return Browse{}, errors.New("limit is out of range")
+	}
+	if prefix != nil {
+		for _, key := range []*Key{before, after, from} {
+			if key != nil && !bytes.HasPrefix(key.b, prefix.b) {
+				return Browse{}, errors.New("before/after/from must start with the prefix")
+			}
+		}
+	}
 
 	return Browse{
 		path:   path,
 		before: before,
 		after:  after,
 		from:   from,
+		prefix: prefix,
+		limit:  limit,
 	}, nil
 }
 
-func MustNewBrowse(path []Key, before *Key, after *Key, from *Key) Browse {
-	b, err := NewBrowse(path, before, after, from)
+func MustNewBrowse(path []Key, before *Key, after *Key, from *Key, prefix *Key, limit int) Browse {
+	b, err := NewBrowse(path, before, after, from, prefix, limit)
 	if err != nil {
 		panic(err)
 	}
@@ -58,6 +79,14 @@ func (b Browse) From() *Key {
 	return b.from
 }
 
+func (b Browse) Prefix() *Key {
+	return b.prefix
+}
+
+func (b Browse) Limit() int {
+	return b.limit
+}
+
 type BrowseHandler struct {
 	transactionProvider TransactionProvider
 }
@@ -72,11 +101,15 @@ func (h *BrowseHandler) Execute(query Browse) (tree Tree, err error) {
 	tree.Path = query.Path()
 
 	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
-		tree.Entries, err = adapters.Database.Browse(query.Path(), query.Before(), query.After(), query.From())
+		page, err := adapters.Database.Browse(query.Path(), query.Before(), query.After(), query.From(), query.Prefix(), query.Limit())
 		if err != nil {
 			return errors.Wrap(err, "could not browse the database")
 		}
 
+		tree.Entries = page.Entries
+		tree.HasPrevious = page.HasPrevious
+		tree.HasNext = page.HasNext
+
 		return nil
 	}); err != nil {
 		return tree, errors.Wrap(err, "transaction failed")
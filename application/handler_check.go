@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+)
+
+type CheckConsistency struct {
+}
+
+type CheckConsistencyHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewCheckConsistencyHandler(transactionProvider TransactionProvider) *CheckConsistencyHandler {
+	return &CheckConsistencyHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute runs the consistency check in a read transaction and calls fn for
+// every problem as soon as it is found. It returns the number of found
+// problems. Once the context is cancelled or fn returns an error fn is no
+// longer called but the check can't be stopped, Execute returns only after
+// the entire database was checked.
+func (h *CheckConsistencyHandler) Execute(ctx context.Context, cmd CheckConsistency, fn CheckFn) (problems int, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		return adapters.Database.Check(func(problem error) error {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "context error")
+			}
+
+			problems++
+			return fn(problem)
+		})
+	}); err != nil {
+		return problems, errors.Wrap(err, "transaction failed")
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type CompactionProgress struct {
+	// CopiedBuckets is the number of top-level buckets which were already
+	// copied or are being copied.
+	CopiedBuckets int
+
+	// TotalBuckets is the number of top-level buckets in the source
+	// database.
+	TotalBuckets int
+
+	// Size is the current size of the destination file in bytes.
+	Size int64
+}
+
+// CompactionProgressFn is called periodically during the compaction. If a
+// non-nil error is returned then the compaction fails and fn is no longer
+// called. The compaction can't be aborted mid-way so it fails only after the
+// entire database was copied.
+type CompactionProgressFn func(progress CompactionProgress) error
+
+type CompactionResult struct {
+	// SizeBefore is the size of the source file in bytes.
+	SizeBefore int64
+
+	// SizeAfter is the size of the destination file in bytes.
+	SizeAfter int64
+}
+
+type Compact struct {
+	destination string
+}
+
+func NewCompact(destination string) (Compact, error) {
+	if destination == "" {
+		return Compact{}, errors.New("destination can not be empty")
+	}
+
+	return Compact{
+		destination: destination,
+	}, nil
+}
+
+func MustNewCompact(destination string) Compact {
+	c, err := NewCompact(destination)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (c Compact) Destination() string {
+	return c.destination
+}
+
+type CompactHandler struct {
+	source      Source
+	permissions Permissions
+}
+
+func NewCompactHandler(source Source, permissions Permissions) *CompactHandler {
+	return &CompactHandler{
+		source:      source,
+		permissions: permissions,
+	}
+}
+
+func (h *CompactHandler) Execute(cmd Compact, fn CompactionProgressFn) (CompactionResult, error) {
+	if !h.permissions.Compact {
+		return CompactionResult{}, ErrCompactionDisabled
+	}
+
+	result, err := h.source.Compact(cmd.Destination(), fn)
+	if err != nil {
+		return CompactionResult{}, errors.Wrap(err, "could not compact the database")
+	}
+
+	return result, nil
+}
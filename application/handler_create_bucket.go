@@ -0,0 +1,61 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type CreateBucket struct {
+	path []Key
+}
+
+func NewCreateBucket(path []Key) (CreateBucket, error) {
+	if len(path) == 0 {
+		return CreateBucket{}, errors.New("path can not be empty")
+	}
+
+	return CreateBucket{
+		path: path,
+	}, nil
+}
+
+func MustNewCreateBucket(path []Key) CreateBucket {
+	c, err := NewCreateBucket(path)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (c CreateBucket) Path() []Key {
+	return c.path
+}
+
+type CreateBucketHandler struct {
+	transactionProvider TransactionProvider
+	permissions         Permissions
+}
+
+func NewCreateBucketHandler(transactionProvider TransactionProvider, permissions Permissions) *CreateBucketHandler {
+	return &CreateBucketHandler{
+		transactionProvider: transactionProvider,
+		permissions:         permissions,
+	}
+}
+
+func (h *CreateBucketHandler) Execute(cmd CreateBucket) error {
+	if !h.permissions.Write {
+		return ErrWritesDisabled
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.CreateBucket(cmd.Path()); err != nil {
+			return errors.Wrap(err, "could not create the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
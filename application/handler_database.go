@@ -0,0 +1,183 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/boreq/bolt-ui/logging"
+	"github.com/boreq/errors"
+)
+
+const (
+	// DatabaseInfoSampleInterval specifies how often the sampler records
+	// the database info.
+	DatabaseInfoSampleInterval = 10 * time.Second
+
+	// DatabaseInfoHistoryLength specifies how many samples are kept by the
+	// sampler, this corresponds to one hour of history.
+	DatabaseInfoHistoryLength = 360
+)
+
+// DatabaseInfo describes the database file and the database as a whole as
+// opposed to BucketStats which describe specific buckets.
+type DatabaseInfo struct {
+	// FileSize is the size of the database file in bytes.
+	FileSize int64
+
+	// PageSize is the page size of the database in bytes.
+	PageSize int
+
+	// Freelist stats.
+	FreePageN     int // total number of free pages on the freelist
+	PendingPageN  int // total number of pending pages on the freelist
+	FreeAlloc     int // total bytes allocated in free pages
+	FreelistInuse int // total bytes used by the freelist
+
+	// Transaction stats.
+	TxN     int // total number of started read transactions
+	OpenTxN int // number of currently open read transactions
+
+	// Tx contains the global transaction stats.
+	Tx TxStats
+}
+
+type TxStats struct {
+	// Page statistics.
+	PageCount int64 // number of page allocations
+	PageAlloc int64 // total bytes allocated
+
+	// Cursor statistics.
+	CursorCount int64 // number of cursors created
+
+	// Node statistics.
+	NodeCount int64 // number of node allocations
+	NodeDeref int64 // number of node dereferences
+
+	// Rebalance statistics.
+	Rebalance     int64         // number of node rebalances
+	RebalanceTime time.Duration // total time spent rebalancing
+
+	// Split/Spill statistics.
+	Split     int64         // number of nodes split
+	Spill     int64         // number of nodes spilled
+	SpillTime time.Duration // total time spent spilling
+
+	// Write statistics.
+	Write     int64         // number of writes performed
+	WriteTime time.Duration // total time spent writing to disk
+}
+
+type DatabaseInfoSample struct {
+	Time time.Time
+	Info DatabaseInfo
+}
+
+// DatabaseInfoSampler periodically records the database info so that it is
+// possible to see how it changes over time, for example if the freelist keeps
+// growing.
+type DatabaseInfoSampler struct {
+	source Source
+
+	mutex   sync.Mutex
+	samples []DatabaseInfoSample
+
+	log logging.Logger
+}
+
+func NewDatabaseInfoSampler(source Source) *DatabaseInfoSampler {
+	return &DatabaseInfoSampler{
+		source: source,
+		log:    logging.New("application.DatabaseInfoSampler"),
+	}
+}
+
+// Run records samples until the context is cancelled.
+func (s *DatabaseInfoSampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(DatabaseInfoSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sample(); err != nil {
+			s.log.Error("could not sample the database info", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample records a single sample, the oldest sample is discarded if the
+// history is full.
+func (s *DatabaseInfoSampler) Sample() error {
+	info, err := s.source.DatabaseInfo()
+	if err != nil {
+		return errors.Wrap(err, "could not get the database info")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.samples = append(s.samples, DatabaseInfoSample{
+		Time: time.Now(),
+		Info: info,
+	})
+
+	if len(s.samples) > DatabaseInfoHistoryLength {
+		s.samples = s.samples[len(s.samples)-DatabaseInfoHistoryLength:]
+	}
+
+	return nil
+}
+
+// Samples returns the recorded samples starting with the oldest one.
+func (s *DatabaseInfoSampler) Samples() []DatabaseInfoSample {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tmp := make([]DatabaseInfoSample, len(s.samples))
+	copy(tmp, s.samples)
+	return tmp
+}
+
+type DatabaseInfoReport struct {
+	// Current is the database info at the time of the query.
+	Current DatabaseInfoSample
+
+	// History contains the samples recorded by the sampler starting
+	// with the oldest one.
+	History []DatabaseInfoSample
+}
+
+type GetDatabaseInfo struct {
+}
+
+type GetDatabaseInfoHandler struct {
+	source  Source
+	sampler *DatabaseInfoSampler
+}
+
+func NewGetDatabaseInfoHandler(source Source, sampler *DatabaseInfoSampler) *GetDatabaseInfoHandler {
+	return &GetDatabaseInfoHandler{
+		source:  source,
+		sampler: sampler,
+	}
+}
+
+func (h *GetDatabaseInfoHandler) Execute(query GetDatabaseInfo) (DatabaseInfoReport, error) {
+	info, err := h.source.DatabaseInfo()
+	if err != nil {
+		return DatabaseInfoReport{}, errors.Wrap(err, "could not get the database info")
+	}
+
+	return DatabaseInfoReport{
+		Current: DatabaseInfoSample{
+			Time: time.Now(),
+			Info: info,
+		},
+		History: h.sampler.Samples(),
+	}, nil
+}
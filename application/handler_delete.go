@@ -0,0 +1,67 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type Delete struct {
+	path []Key
+	key  Key
+}
+
+func NewDelete(path []Key, key Key) (Delete, error) {
+	if len(path) == 0 {
+		return Delete{}, errors.New("values can not be deleted from the root bucket")
+	}
+
+	return Delete{
+		path: path,
+		key:  key,
+	}, nil
+}
+
+func MustNewDelete(path []Key, key Key) Delete {
+	d, err := NewDelete(path, key)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Delete) Path() []Key {
+	return d.path
+}
+
+func (d Delete) Key() Key {
+	return d.key
+}
+
+type DeleteHandler struct {
+	transactionProvider TransactionProvider
+	permissions         Permissions
+}
+
+func NewDeleteHandler(transactionProvider TransactionProvider, permissions Permissions) *DeleteHandler {
+	return &DeleteHandler{
+		transactionProvider: transactionProvider,
+		permissions:         permissions,
+	}
+}
+
+func (h *DeleteHandler) Execute(cmd Delete) error {
+	if !h.permissions.Write {
+		return ErrWritesDisabled
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.Delete(cmd.Path(), cmd.Key()); err != nil {
+			return errors.Wrap(err, "could not delete the value")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
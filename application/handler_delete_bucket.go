@@ -0,0 +1,61 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type DeleteBucket struct {
+	path []Key
+}
+
+func NewDeleteBucket(path []Key) (DeleteBucket, error) {
+	if len(path) == 0 {
+		return DeleteBucket{}, errors.New("path can not be empty")
+	}
+
+	return DeleteBucket{
+		path: path,
+	}, nil
+}
+
+func MustNewDeleteBucket(path []Key) DeleteBucket {
+	d, err := NewDeleteBucket(path)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d DeleteBucket) Path() []Key {
+	return d.path
+}
+
+type DeleteBucketHandler struct {
+	transactionProvider TransactionProvider
+	permissions         Permissions
+}
+
+func NewDeleteBucketHandler(transactionProvider TransactionProvider, permissions Permissions) *DeleteBucketHandler {
+	return &DeleteBucketHandler{
+		transactionProvider: transactionProvider,
+		permissions:         permissions,
+	}
+}
+
+func (h *DeleteBucketHandler) Execute(cmd DeleteBucket) error {
+	if !h.permissions.Write {
+		return ErrWritesDisabled
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.DeleteBucket(cmd.Path()); err != nil {
+			return errors.Wrap(err, "could not delete the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
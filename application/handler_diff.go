@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/errors"
+)
+
+type DiffKind struct {
+	s string
+}
+
+func (k DiffKind) String() string {
+	return k.s
+}
+
+var (
+	DiffKindAdded    = DiffKind{"added"}
+	DiffKindRemoved  = DiffKind{"removed"}
+	DiffKindModified = DiffKind{"modified"}
+)
+
+// DiffEntryState describes an entry as it exists in one of the compared
+// databases.
+type DiffEntryState struct {
+	Bucket bool
+
+	// Value is empty if the entry is a bucket.
+	Value Value
+
+	// Sequence is the sequence of the bucket if the entry is a bucket.
+	Sequence uint64
+}
+
+type DiffEntry struct {
+	// Path is the path to the bucket containing the entry.
+	Path []Key
+	Key  Key
+	Kind DiffKind
+
+	// Old is nil if the entry was added.
+	Old *DiffEntryState
+
+	// New is nil if the entry was removed.
+	New *DiffEntryState
+
+	// Differences is set if the entry was modified and both values were
+	// recognized as JSON or CBOR.
+	Differences []display.Difference
+}
+
+// DiffFn is called for every entry which differs between the compared
+// databases. Entries are reported in key order and buckets are reported
+// before their contents.
+type DiffFn func(entry DiffEntry) error
+
+type Diff struct {
+	other string
+	path  []Key
+}
+
+// NewDiff creates a query comparing the served database with the database
+// file located at the other path. If other is empty then the served snapshot
+// is compared with the current state of the database file. An empty path
+// compares the entire databases.
+func NewDiff(other string, path []Key) (Diff, error) {
+	return Diff{
+		other: other,
+		path:  path,
+	}, nil
+}
+
+func MustNewDiff(other string, path []Key) Diff {
+	d, err := NewDiff(other, path)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Diff) Other() string {
+	return d.other
+}
+
+func (d Diff) Path() []Key {
+	return d.path
+}
+
+type DiffHandler struct {
+	source      Source
+	permissions Permissions
+	pretty      *display.Pretty
+}
+
+func NewDiffHandler(source Source, permissions Permissions) *DiffHandler {
+	return &DiffHandler{
+		source:      source,
+		permissions: permissions,
+		pretty:      display.NewPretty(),
+	}
+}
+
+// Execute calls fn for every entry which differs between the compared
+// databases. Comparing the served database with an arbitrary file requires
+// the diff permission as it makes it possible to read any database file
+// accessible to the program.
+func (h *DiffHandler) Execute(ctx context.Context, query Diff, fn DiffFn) error {
+	if query.Other() != "" && !h.permissions.Diff {
+		return ErrDiffDisabled
+	}
+
+	if err := h.source.Diff(query.Other(), query.Path(), func(entry DiffEntry) error {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "context error")
+		}
+
+		if entry.Kind == DiffKindModified && !entry.Old.Bucket && !entry.New.Bucket {
+			if differences, ok := h.pretty.Diff(entry.Old.Value.b, entry.New.Value.b); ok {
+				entry.Differences = differences
+			}
+		}
+
+		return fn(entry)
+	}); err != nil {
+		return errors.Wrap(err, "could not diff the databases")
+	}
+
+	return nil
+}
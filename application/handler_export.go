@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/errors"
+)
+
+type Export struct {
+	path []Key
+}
+
+// NewExport creates a query exporting the bucket specified by the path and
+// all of its contents. An empty path exports the entire database.
+func NewExport(path []Key) (Export, error) {
+	return Export{
+		path: path,
+	}, nil
+}
+
+func MustNewExport(path []Key) Export {
+	e, err := NewExport(path)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func (e Export) Path() []Key {
+	return e.path
+}
+
+type ExportedEntry struct {
+	// Path is the path to the bucket containing the entry.
+	Path []Key
+
+	Entry Entry
+
+	// Sequence is the sequence of the bucket if the entry is a bucket.
+	Sequence uint64
+}
+
+// ExportFn is called for every exported entry. Buckets are exported before
+// their contents.
+type ExportFn func(entry ExportedEntry) error
+
+type ExportHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewExportHandler(transactionProvider TransactionProvider) *ExportHandler {
+	return &ExportHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute calls fn for every entry in the exported bucket and its nested
+// buckets using a single read transaction. If the path is not empty then the
+// exported bucket itself is exported first so that its sequence is
+// preserved.
+func (h *ExportHandler) Execute(ctx context.Context, query Export, fn ExportFn) error {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		if len(query.Path()) > 0 {
+			if err := h.exportRoot(adapters, query.Path(), fn); err != nil {
+				return errors.Wrap(err, "could not export the bucket")
+			}
+		}
+
+		return adapters.Database.Walk(query.Path(), true, func(path []Key, entry Entry) error {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "context error")
+			}
+
+			exported := ExportedEntry{
+				Path:  path,
+				Entry: entry,
+			}
+
+			if entry.Bucket {
+				sequence, err := adapters.Database.BucketSequence(appendKey(path, entry.Key))
+				if err != nil {
+					return errors.Wrap(err, "could not get the bucket sequence")
+				}
+				exported.Sequence = sequence
+			}
+
+			return fn(exported)
+		})
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
+
+func (h *ExportHandler) exportRoot(adapters *TransactableAdapters, path []Key, fn ExportFn) error {
+	sequence, err := adapters.Database.BucketSequence(path)
+	if err != nil {
+		return errors.Wrap(err, "could not get the bucket sequence")
+	}
+
+	return fn(ExportedEntry{
+		Path: path[:len(path)-1],
+		Entry: Entry{
+			Bucket: true,
+			Key:    path[len(path)-1],
+		},
+		Sequence: sequence,
+	})
+}
+
+func appendKey(path []Key, key Key) []Key {
+	tmp := make([]Key, len(path), len(path)+1)
+	copy(tmp, path)
+	return append(tmp, key)
+}
@@ -0,0 +1,298 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/boreq/errors"
+)
+
+const (
+	DefaultImportBatchSize = 1000
+	MaxImportBatchSize     = 100000
+)
+
+type ImportedEntry struct {
+	// Path is the path to the bucket containing the entry.
+	Path []Key
+
+	Entry Entry
+
+	// Sequence is set if the sequence of the bucket should be restored.
+	Sequence *uint64
+}
+
+// ImportSource returns the entries which should be imported. It returns
+// io.EOF once there are no more entries and an error wrapping ErrInvalidEntry
+// if an entry is malformed.
+type ImportSource interface {
+	Next() (ImportedEntry, error)
+}
+
+type ChangeKind struct {
+	s string
+}
+
+func (k ChangeKind) String() string {
+	return k.s
+}
+
+var (
+	ChangeKindCreated   = ChangeKind{"created"}
+	ChangeKindUpdated   = ChangeKind{"updated"}
+	ChangeKindUnchanged = ChangeKind{"unchanged"}
+)
+
+type ImportChange struct {
+	// Path is the path to the bucket containing the entry.
+	Path   []Key
+	Key    Key
+	Bucket bool
+	Kind   ChangeKind
+}
+
+// ImportChangeFn is called for every imported entry. The import is interrupted
+// if a non-nil error is returned.
+type ImportChangeFn func(change ImportChange) error
+
+type ImportResult struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+type Import struct {
+	path      []Key
+	dryRun    bool
+	batchSize int
+}
+
+// NewImport creates a command importing entries into the bucket specified by
+// the path. The paths of the imported entries are relative to that bucket. An
+// empty path imports the entries into the root of the database. If dryRun is
+// set then the changes are reported but nothing is written.
+func NewImport(path []Key, dryRun bool, batchSize int) (Import, error) {
+	if batchSize <= 0 || batchSize > MaxImportBatchSize {
+		return Import{}, errors.New("invalid batch size")
+	}
+
+	return Import{
+		path:      path,
+		dryRun:    dryRun,
+		batchSize: batchSize,
+	}, nil
+}
+
+func MustNewImport(path []Key, dryRun bool, batchSize int) Import {
+	i, err := NewImport(path, dryRun, batchSize)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+func (i Import) Path() []Key {
+	return i.path
+}
+
+func (i Import) DryRun() bool {
+	return i.dryRun
+}
+
+func (i Import) BatchSize() int {
+	return i.batchSize
+}
+
+type ImportHandler struct {
+	transactionProvider TransactionProvider
+	permissions         Permissions
+}
+
+func NewImportHandler(transactionProvider TransactionProvider, permissions Permissions) *ImportHandler {
+	return &ImportHandler{
+		transactionProvider: transactionProvider,
+		permissions:         permissions,
+	}
+}
+
+// Execute imports the entries in batches, each batch is written using a
+// separate transaction. Missing buckets are created as needed. In the dry run
+// mode the changes are determined using read transactions and writes don't
+// have to be enabled.
+func (h *ImportHandler) Execute(ctx context.Context, cmd Import, source ImportSource, fn ImportChangeFn) (ImportResult, error) {
+	if !cmd.DryRun() && !h.permissions.Write {
+		return ImportResult{}, ErrWritesDisabled
+	}
+
+	var result ImportResult
+
+	for {
+		batch, err := h.readBatch(source, cmd)
+		if err != nil {
+			return result, errors.Wrap(err, "could not read the batch")
+		}
+
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		if err := h.importBatch(ctx, cmd, batch, &result, fn); err != nil {
+			return result, errors.Wrap(err, "could not import the batch")
+		}
+	}
+}
+
+func (h *ImportHandler) readBatch(source ImportSource, cmd Import) ([]ImportedEntry, error) {
+	var batch []ImportedEntry
+
+	for len(batch) < cmd.BatchSize() {
+		entry, err := source.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, errors.Wrap(err, "could not get the next entry")
+		}
+
+		entry.Path = append(append([]Key{}, cmd.Path()...), entry.Path...)
+
+		if !entry.Entry.Bucket && len(entry.Path) == 0 {
+			return nil, errors.Wrap(ErrInvalidEntry, "values can not be stored outside of buckets")
+		}
+
+		batch = append(batch, entry)
+	}
+
+	return batch, nil
+}
+
+func (h *ImportHandler) importBatch(ctx context.Context, cmd Import, batch []ImportedEntry, result *ImportResult, fn ImportChangeFn) error {
+	handler := func(adapters *TransactableAdapters) error {
+		for _, entry := range batch {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "context error")
+			}
+
+			kind, err := h.classify(adapters, entry)
+			if err != nil {
+				return errors.Wrap(err, "could not classify the entry")
+			}
+
+			if !cmd.DryRun() && kind != ChangeKindUnchanged {
+				if err := h.write(adapters, entry, kind); err != nil {
+					return errors.Wrap(err, "could not write the entry")
+				}
+			}
+
+			switch kind {
+			case ChangeKindCreated:
+				result.Created++
+			case ChangeKindUpdated:
+				result.Updated++
+			case ChangeKindUnchanged:
+				result.Unchanged++
+			}
+
+			if err := fn(ImportChange{
+				Path:   entry.Path,
+				Key:    entry.Entry.Key,
+				Bucket: entry.Entry.Bucket,
+				Kind:   kind,
+			}); err != nil {
+				return errors.Wrap(err, "fn returned an error")
+			}
+		}
+		return nil
+	}
+
+	if cmd.DryRun() {
+		return h.transactionProvider.Read(handler)
+	}
+	return h.transactionProvider.Write(handler)
+}
+
+// classify compares the entry with the current state of the database. Entries
+// located in buckets which don't exist yet are always created.
+func (h *ImportHandler) classify(adapters *TransactableAdapters, entry ImportedEntry) (ChangeKind, error) {
+	existing, err := adapters.Database.Get(entry.Path, entry.Entry.Key)
+	if err != nil {
+		if errors.Is(err, ErrBucketNotFound) || errors.Is(err, ErrKeyNotFound) {
+			return ChangeKindCreated, nil
+		}
+		return ChangeKind{}, errors.Wrap(err, "could not get the entry")
+	}
+
+	if entry.Entry.Bucket {
+		if !existing.Bucket {
+			return ChangeKind{}, ErrKeyAlreadyExists
+		}
+
+		if entry.Sequence == nil {
+			return ChangeKindUnchanged, nil
+		}
+
+		sequence, err := adapters.Database.BucketSequence(appendKey(entry.Path, entry.Entry.Key))
+		if err != nil {
+			return ChangeKind{}, errors.Wrap(err, "could not get the bucket sequence")
+		}
+
+		if sequence != *entry.Sequence {
+			return ChangeKindUpdated, nil
+		}
+		return ChangeKindUnchanged, nil
+	}
+
+	if existing.Bucket {
+		return ChangeKind{}, ErrKeyIsABucket
+	}
+
+	if !bytes.Equal(existing.Value.Bytes(), entry.Entry.Value.Bytes()) {
+		return ChangeKindUpdated, nil
+	}
+	return ChangeKindUnchanged, nil
+}
+
+func (h *ImportHandler) write(adapters *TransactableAdapters, entry ImportedEntry, kind ChangeKind) error {
+	if kind == ChangeKindCreated {
+		if err := h.ensureBucket(adapters, entry.Path); err != nil {
+			return errors.Wrap(err, "could not create the parent buckets")
+		}
+	}
+
+	if !entry.Entry.Bucket {
+		if err := adapters.Database.Put(entry.Path, entry.Entry.Key, entry.Entry.Value); err != nil {
+			return errors.Wrap(err, "could not put the value")
+		}
+		return nil
+	}
+
+	path := appendKey(entry.Path, entry.Entry.Key)
+
+	if err := adapters.Database.CreateBucket(path); err != nil {
+		if !errors.Is(err, ErrKeyAlreadyExists) {
+			return errors.Wrap(err, "could not create the bucket")
+		}
+	}
+
+	if entry.Sequence != nil {
+		if err := adapters.Database.SetBucketSequence(path, *entry.Sequence); err != nil {
+			return errors.Wrap(err, "could not set the bucket sequence")
+		}
+	}
+
+	return nil
+}
+
+// ensureBucket creates the bucket specified by the path and all of its parents
+// if they don't exist.
+func (h *ImportHandler) ensureBucket(adapters *TransactableAdapters, path []Key) error {
+	for i := 1; i <= len(path); i++ {
+		if err := adapters.Database.CreateBucket(path[:i]); err != nil {
+			if !errors.Is(err, ErrKeyAlreadyExists) {
+				return errors.Wrap(err, "could not create the bucket")
+			}
+		}
+	}
+	return nil
+}
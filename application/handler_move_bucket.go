@@ -0,0 +1,91 @@
+package application
+
+import (
+	"bytes"
+
+	"github.com/boreq/errors"
+)
+
+type MoveBucket struct {
+	source      []Key
+	destination []Key
+}
+
+func NewMoveBucket(source []Key, destination []Key) (MoveBucket, error) {
+	if len(source) == 0 {
+		return MoveBucket{}, errors.New("source can not be empty")
+	}
+
+	if len(destination) == 0 {
+		return MoveBucket{}, errors.New("destination can not be empty")
+	}
+
+	if isPrefixOf(source, destination) {
+		return MoveBucket{}, errors.New("bucket can not be moved into itself")
+	}
+
+	return MoveBucket{
+		source:      source,
+		destination: destination,
+	}, nil
+}
+
+func MustNewMoveBucket(source []Key, destination []Key) MoveBucket {
+	m, err := NewMoveBucket(source, destination)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m MoveBucket) Source() []Key {
+	return m.source
+}
+
+func (m MoveBucket) Destination() []Key {
+	return m.destination
+}
+
+type MoveBucketHandler struct {
+	transactionProvider TransactionProvider
+	permissions         Permissions
+}
+
+func NewMoveBucketHandler(transactionProvider TransactionProvider, permissions Permissions) *MoveBucketHandler {
+	return &MoveBucketHandler{
+		transactionProvider: transactionProvider,
+		permissions:         permissions,
+	}
+}
+
+func (h *MoveBucketHandler) Execute(cmd MoveBucket) error {
+	if !h.permissions.Write {
+		return ErrWritesDisabled
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.MoveBucket(cmd.Source(), cmd.Destination()); err != nil {
+			return errors.Wrap(err, "could not move the bucket")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
+
+func isPrefixOf(prefix []Key, path []Key) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if !bytes.Equal(prefix[i].b, path[i].b) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,73 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type Put struct {
+	path  []Key
+	key   Key
+	value Value
+}
+
+func NewPut(path []Key, key Key, value Value) (Put, error) {
+	if len(path) == 0 {
+		return Put{}, errors.New("values can not be put in the root bucket")
+	}
+
+	return Put{
+		path:  path,
+		key:   key,
+		value: value,
+	}, nil
+}
+
+func MustNewPut(path []Key, key Key, value Value) Put {
+	p, err := NewPut(path, key, value)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p Put) Path() []Key {
+	return p.path
+}
+
+func (p Put) Key() Key {
+	return p.key
+}
+
+func (p Put) Value() Value {
+	return p.value
+}
+
+type PutHandler struct {
+	transactionProvider TransactionProvider
+	permissions         Permissions
+}
+
+func NewPutHandler(transactionProvider TransactionProvider, permissions Permissions) *PutHandler {
+	return &PutHandler{
+		transactionProvider: transactionProvider,
+		permissions:         permissions,
+	}
+}
+
+func (h *PutHandler) Execute(cmd Put) error {
+	if !h.permissions.Write {
+		return ErrWritesDisabled
+	}
+
+	if err := h.transactionProvider.Write(func(adapters *TransactableAdapters) error {
+		if err := adapters.Database.Put(cmd.Path(), cmd.Key(), cmd.Value()); err != nil {
+			return errors.Wrap(err, "could not put the value")
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/bolt-ui/jsonpath"
+	"github.com/boreq/errors"
+)
+
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 10000
+)
+
+type Query struct {
+	path      []Key
+	filter    *jsonpath.Expression
+	fields    []jsonpath.Path
+	recursive bool
+	limit     int
+}
+
+// NewQuery creates a query which selects the values matching the filter and
+// projects the selected fields. If the filter is nil then all values are
+// selected.
+func NewQuery(path []Key, filter *jsonpath.Expression, fields []jsonpath.Path, recursive bool, limit int) (Query, error) {
+	if limit <= 0 || limit > MaxQueryLimit {
+		return Query{}, errors.New("limit is out of range")
+	}
+
+	return Query{
+		path:      path,
+		filter:    filter,
+		fields:    fields,
+		recursive: recursive,
+		limit:     limit,
+	}, nil
+}
+
+func MustNewQuery(path []Key, filter *jsonpath.Expression, fields []jsonpath.Path, recursive bool, limit int) Query {
+	q, err := NewQuery(path, filter, fields, recursive, limit)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (q Query) Path() []Key {
+	return q.path
+}
+
+func (q Query) Fields() []jsonpath.Path {
+	return q.fields
+}
+
+func (q Query) Recursive() bool {
+	return q.recursive
+}
+
+func (q Query) Limit() int {
+	return q.limit
+}
+
+func (q Query) Matches(v interface{}) bool {
+	if q.filter == nil {
+		return true
+	}
+	return q.filter.Evaluate(v)
+}
+
+type QueryResult struct {
+	// Path to the bucket containing the entry.
+	Path []Key
+	Key  Key
+
+	// Values contains the projected fields in the same order as they
+	// were specified in the query. Fields which don't exist are set to
+	// nil.
+	Values []interface{}
+}
+
+// QueryResultFn receives the query results as they are found. Returning an
+// error stops the query.
+type QueryResultFn func(result QueryResult) error
+
+type QueryHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewQueryHandler(transactionProvider TransactionProvider) *QueryHandler {
+	return &QueryHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute decodes the values stored in the database as JSON or CBOR and
+// evaluates the query against them until the limit of results is reached,
+// all entries are checked or the context is cancelled. Entries which can't
+// be decoded are skipped.
+func (h *QueryHandler) Execute(ctx context.Context, query Query, fn QueryResultFn) error {
+	var found int
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		return adapters.Database.Walk(query.Path(), query.Recursive(), func(path []Key, entry Entry) error {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "context error")
+			}
+
+			if entry.Bucket || entry.Value.IsEmpty() {
+				return nil
+			}
+
+			v, _, err := display.Decode(entry.Value.b)
+			if err != nil {
+				return nil
+			}
+
+			if !query.Matches(v) {
+				return nil
+			}
+
+			result := QueryResult{
+				Path: path,
+				Key:  entry.Key,
+			}
+
+			for _, field := range query.Fields() {
+				value, _ := field.Get(v)
+				result.Values = append(result.Values, value)
+			}
+
+			if err := fn(result); err != nil {
+				return errors.Wrap(err, "result function returned an error")
+			}
+
+			found++
+			if found >= query.Limit() {
+				return errLimitReached
+			}
+
+			return nil
+		})
+	}); err != nil {
+		if errors.Is(err, errLimitReached) {
+			return nil
+		}
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
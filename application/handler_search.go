@@ -0,0 +1,186 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+
+	"github.com/boreq/errors"
+)
+
+const (
+	DefaultSearchLimit = 100
+	MaxSearchLimit     = 10000
+)
+
+// Matcher checks if a key or a value matches the search criteria.
+type Matcher interface {
+	Match(b []byte) bool
+}
+
+type bytesMatcher struct {
+	b []byte
+}
+
+// NewSubstringMatcher creates a matcher which matches keys or values
+// containing the provided string.
+func NewSubstringMatcher(s string) (Matcher, error) {
+	return NewBytesMatcher([]byte(s))
+}
+
+// NewBytesMatcher creates a matcher which matches keys or values containing
+// the provided sequence of bytes.
+func NewBytesMatcher(b []byte) (Matcher, error) {
+	if len(b) == 0 {
+		return nil, errors.New("pattern can not be empty")
+	}
+
+	return bytesMatcher{b: b}, nil
+}
+
+func (m bytesMatcher) Match(b []byte) bool {
+	return bytes.Contains(b, m.b)
+}
+
+type regexpMatcher struct {
+	r *regexp.Regexp
+}
+
+// NewRegexpMatcher creates a matcher which matches keys or values matching
+// the provided regular expression.
+func NewRegexpMatcher(expr string) (Matcher, error) {
+	if expr == "" {
+		return nil, errors.New("expression can not be empty")
+	}
+
+	r, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compile the expression")
+	}
+
+	return regexpMatcher{r: r}, nil
+}
+
+func (m regexpMatcher) Match(b []byte) bool {
+	return m.r.Match(b)
+}
+
+type Search struct {
+	path      []Key
+	matcher   Matcher
+	keys      bool
+	values    bool
+	recursive bool
+	limit     int
+}
+
+func NewSearch(path []Key, matcher Matcher, keys, values, recursive bool, limit int) (Search, error) {
+	if matcher == nil {
+		return Search{}, errors.New("nil matcher")
+	}
+	if !keys && !values {
+		return Search{}, errors.New("either keys or values have to be searched")
+	}
+	if limit <= 0 || limit > MaxSearchLimit {
+		return Search{}, errors.New("limit is out of range")
+	}
+
+	return Search{
+		path:      path,
+		matcher:   matcher,
+		keys:      keys,
+		values:    values,
+		recursive: recursive,
+		limit:     limit,
+	}, nil
+}
+
+func MustNewSearch(path []Key, matcher Matcher, keys, values, recursive bool, limit int) Search {
+	s, err := NewSearch(path, matcher, keys, values, recursive, limit)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s Search) Path() []Key {
+	return s.path
+}
+
+func (s Search) Recursive() bool {
+	return s.recursive
+}
+
+func (s Search) Limit() int {
+	return s.limit
+}
+
+func (s Search) Matches(entry Entry) bool {
+	if s.keys && s.matcher.Match(entry.Key.b) {
+		return true
+	}
+
+	if s.values && !entry.Bucket && s.matcher.Match(entry.Value.b) {
+		return true
+	}
+
+	return false
+}
+
+type SearchResult struct {
+	// Path to the bucket containing the entry.
+	Path  []Key
+	Entry Entry
+}
+
+// SearchResultFn receives the search results as they are found. Returning an
+// error stops the search.
+type SearchResultFn func(result SearchResult) error
+
+type SearchHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewSearchHandler(transactionProvider TransactionProvider) *SearchHandler {
+	return &SearchHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+// Execute searches the database until the limit of results is reached, all
+// entries are checked or the context is cancelled.
+func (h *SearchHandler) Execute(ctx context.Context, query Search, fn SearchResultFn) error {
+	var found int
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		return adapters.Database.Walk(query.Path(), query.Recursive(), func(path []Key, entry Entry) error {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "context error")
+			}
+
+			if !query.Matches(entry) {
+				return nil
+			}
+
+			if err := fn(SearchResult{Path: path, Entry: entry}); err != nil {
+				return errors.Wrap(err, "result function returned an error")
+			}
+
+			found++
+			if found >= query.Limit() {
+				return errLimitReached
+			}
+
+			return nil
+		})
+	}); err != nil {
+		if errors.Is(err, errLimitReached) {
+			return nil
+		}
+		return errors.Wrap(err, "transaction failed")
+	}
+
+	return nil
+}
+
+var errLimitReached = errors.New("limit reached")
@@ -0,0 +1,62 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+type GetSourceInfo struct {
+}
+
+type GetSourceInfoHandler struct {
+	source Source
+}
+
+func NewGetSourceInfoHandler(source Source) *GetSourceInfoHandler {
+	return &GetSourceInfoHandler{
+		source: source,
+	}
+}
+
+func (h *GetSourceInfoHandler) Execute(query GetSourceInfo) (SourceInfo, error) {
+	return h.source.Info(), nil
+}
+
+type TakeSnapshot struct {
+}
+
+type TakeSnapshotHandler struct {
+	source Source
+}
+
+func NewTakeSnapshotHandler(source Source) *TakeSnapshotHandler {
+	return &TakeSnapshotHandler{
+		source: source,
+	}
+}
+
+func (h *TakeSnapshotHandler) Execute(cmd TakeSnapshot) error {
+	if err := h.source.TakeSnapshot(); err != nil {
+		return errors.Wrap(err, "could not take a snapshot")
+	}
+	return nil
+}
+
+type Reopen struct {
+}
+
+type ReopenHandler struct {
+	source Source
+}
+
+func NewReopenHandler(source Source) *ReopenHandler {
+	return &ReopenHandler{
+		source: source,
+	}
+}
+
+func (h *ReopenHandler) Execute(cmd Reopen) error {
+	if err := h.source.Reopen(); err != nil {
+		return errors.Wrap(err, "could not reopen the database")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package application
+
+import (
+	"github.com/boreq/errors"
+)
+
+// BucketStats describe a bucket and all of its nested buckets.
+type BucketStats struct {
+	// Page count statistics.
+	BranchPageN     int // number of logical branch pages
+	BranchOverflowN int // number of physical branch overflow pages
+	LeafPageN       int // number of logical leaf pages
+	LeafOverflowN   int // number of physical leaf overflow pages
+
+	// Tree statistics.
+	KeyN  int // number of keys/value pairs
+	Depth int // number of levels in B+tree
+
+	// Page size utilization.
+	BranchAlloc int // bytes allocated for physical branch pages
+	BranchInuse int // bytes actually used for branch data
+	LeafAlloc   int // bytes allocated for physical leaf pages
+	LeafInuse   int // bytes actually used for leaf data
+
+	// Bucket statistics.
+	BucketN           int // total number of buckets including the top bucket
+	InlineBucketN     int // total number on inlined buckets
+	InlineBucketInuse int // bytes used for inlined buckets (also accounted for in LeafInuse)
+}
+
+func (s *BucketStats) Add(other BucketStats) {
+	s.BranchPageN += other.BranchPageN
+	s.BranchOverflowN += other.BranchOverflowN
+	s.LeafPageN += other.LeafPageN
+	s.LeafOverflowN += other.LeafOverflowN
+	s.KeyN += other.KeyN
+	if s.Depth < other.Depth {
+		s.Depth = other.Depth
+	}
+	s.BranchAlloc += other.BranchAlloc
+	s.BranchInuse += other.BranchInuse
+	s.LeafAlloc += other.LeafAlloc
+	s.LeafInuse += other.LeafInuse
+	s.BucketN += other.BucketN
+	s.InlineBucketN += other.InlineBucketN
+	s.InlineBucketInuse += other.InlineBucketInuse
+}
+
+type GetBucketStats struct {
+	path []Key
+}
+
+func NewGetBucketStats(path []Key) (GetBucketStats, error) {
+	if len(path) == 0 {
+		return GetBucketStats{}, errors.New("path can not be empty")
+	}
+
+	return GetBucketStats{
+		path: path,
+	}, nil
+}
+
+func MustNewGetBucketStats(path []Key) GetBucketStats {
+	g, err := NewGetBucketStats(path)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func (g GetBucketStats) Path() []Key {
+	return g.path
+}
+
+type GetBucketStatsHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewGetBucketStatsHandler(transactionProvider TransactionProvider) *GetBucketStatsHandler {
+	return &GetBucketStatsHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *GetBucketStatsHandler) Execute(query GetBucketStats) (stats BucketStats, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		stats, err = adapters.Database.BucketStats(query.Path())
+		if err != nil {
+			return errors.Wrap(err, "could not get the bucket stats")
+		}
+
+		return nil
+	}); err != nil {
+		return stats, errors.Wrap(err, "transaction failed")
+	}
+
+	return stats, nil
+}
+
+type DatabaseStats struct {
+	// Buckets contains the stats of all top-level buckets.
+	Buckets []NamedBucketStats
+
+	// Total is a sum of the stats of all top-level buckets.
+	Total BucketStats
+}
+
+type NamedBucketStats struct {
+	Key   Key
+	Stats BucketStats
+}
+
+type GetDatabaseStats struct {
+}
+
+type GetDatabaseStatsHandler struct {
+	transactionProvider TransactionProvider
+}
+
+func NewGetDatabaseStatsHandler(transactionProvider TransactionProvider) *GetDatabaseStatsHandler {
+	return &GetDatabaseStatsHandler{
+		transactionProvider: transactionProvider,
+	}
+}
+
+func (h *GetDatabaseStatsHandler) Execute(query GetDatabaseStats) (stats DatabaseStats, err error) {
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		return adapters.Database.Walk(nil, false, func(path []Key, entry Entry) error {
+			bucketStats, err := adapters.Database.BucketStats([]Key{entry.Key})
+			if err != nil {
+				return errors.Wrap(err, "could not get the bucket stats")
+			}
+
+			stats.Buckets = append(stats.Buckets, NamedBucketStats{
+				Key:   entry.Key,
+				Stats: bucketStats,
+			})
+			stats.Total.Add(bucketStats)
+			return nil
+		})
+	}); err != nil {
+		return stats, errors.Wrap(err, "transaction failed")
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,223 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sort"
+	"time"
+
+	"github.com/boreq/errors"
+)
+
+const (
+	DefaultWatchInterval = 1 * time.Second
+	MinWatchInterval     = 100 * time.Millisecond
+)
+
+type WatchBucket struct {
+	path     []Key
+	interval time.Duration
+}
+
+// NewWatchBucket creates a query watching the direct children of the bucket
+// specified by the path. An empty path watches the top-level buckets. The
+// bucket is polled using the specified interval.
+func NewWatchBucket(path []Key, interval time.Duration) (WatchBucket, error) {
+	if interval < MinWatchInterval {
+		return WatchBucket{}, errors.New("interval is too short")
+	}
+
+	return WatchBucket{
+		path:     path,
+		interval: interval,
+	}, nil
+}
+
+func MustNewWatchBucket(path []Key, interval time.Duration) WatchBucket {
+	w, err := NewWatchBucket(path, interval)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+func (w WatchBucket) Path() []Key {
+	return w.path
+}
+
+func (w WatchBucket) Interval() time.Duration {
+	return w.interval
+}
+
+type BucketChange struct {
+	Kind DiffKind
+
+	// Entry is the current state of the entry. Only the key and the
+	// bucket flag are set if the entry was removed.
+	Entry Entry
+}
+
+// BucketChangesFn is called with the changes detected by every poll in key
+// order. It is first called without any changes once the initial state of the
+// bucket is read. Watching stops if a non-nil error is returned.
+type BucketChangesFn func(changes []BucketChange) error
+
+type WatchBucketHandler struct {
+	transactionProvider TransactionProvider
+	source              Source
+}
+
+func NewWatchBucketHandler(transactionProvider TransactionProvider, source Source) *WatchBucketHandler {
+	return &WatchBucketHandler{
+		transactionProvider: transactionProvider,
+		source:              source,
+	}
+}
+
+// Execute periodically reads the bucket and reports the entries which were
+// added, modified or removed since the previous poll. The bucket is only read
+// again if a write transaction was committed or the database was reopened
+// in the meantime. If the bucket is removed then all of its entries are
+// reported as removed and watching continues in case it is created again.
+// Execute returns nil once the context is cancelled. It returns
+// ErrBucketNotFound if the bucket doesn't exist when watching starts.
+func (h *WatchBucketHandler) Execute(ctx context.Context, query WatchBucket, fn BucketChangesFn) error {
+	state, _, err := h.read(query.Path(), newWatchedBucket())
+	if err != nil {
+		return errors.Wrap(err, "could not read the bucket")
+	}
+
+	if !state.exists {
+		return ErrBucketNotFound
+	}
+
+	if err := fn(nil); err != nil {
+		return errors.Wrap(err, "callback returned an error")
+	}
+
+	ticker := time.NewTicker(query.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if !h.changed(state) {
+				continue
+			}
+
+			newState, changes, err := h.read(query.Path(), state)
+			if err != nil {
+				return errors.Wrap(err, "could not read the bucket")
+			}
+
+			if err := fn(changes); err != nil {
+				return errors.Wrap(err, "callback returned an error")
+			}
+
+			state = newState
+		}
+	}
+}
+
+// changed returns false if the database definitely didn't change since the
+// state was read.
+func (h *WatchBucketHandler) changed(state watchedBucket) bool {
+	if !h.source.Info().OpenedAt.Equal(state.openedAt) {
+		return true
+	}
+
+	var txID int
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		txID = adapters.Database.TxID()
+		return nil
+	}); err != nil {
+		return true
+	}
+
+	return txID != state.txID
+}
+
+// read reads the current state of the bucket and compares it with the
+// previous state. A bucket which doesn't exist is treated as an empty
+// bucket.
+func (h *WatchBucketHandler) read(path []Key, previous watchedBucket) (watchedBucket, []BucketChange, error) {
+	state := newWatchedBucket()
+	state.openedAt = h.source.Info().OpenedAt
+
+	var changes []BucketChange
+
+	if err := h.transactionProvider.Read(func(adapters *TransactableAdapters) error {
+		state.txID = adapters.Database.TxID()
+
+		err := adapters.Database.Walk(path, false, func(path []Key, entry Entry) error {
+			key := string(entry.Key.b)
+			watched := newWatchedEntry(entry)
+			state.entries[key] = watched
+
+			previousEntry, ok := previous.entries[key]
+			switch {
+			case !ok:
+				changes = append(changes, BucketChange{Kind: DiffKindAdded, Entry: entry})
+			case previousEntry != watched:
+				changes = append(changes, BucketChange{Kind: DiffKindModified, Entry: entry})
+			}
+
+			return nil
+		})
+		if errors.Is(err, ErrBucketNotFound) {
+			return nil
+		}
+		state.exists = err == nil
+		return err
+	}); err != nil {
+		return watchedBucket{}, nil, errors.Wrap(err, "transaction failed")
+	}
+
+	for key, entry := range previous.entries {
+		if _, ok := state.entries[key]; !ok {
+			changes = append(changes, BucketChange{
+				Kind: DiffKindRemoved,
+				Entry: Entry{
+					Bucket: entry.bucket,
+					Key:    Key{[]byte(key)},
+				},
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return bytes.Compare(changes[i].Entry.Key.b, changes[j].Entry.Key.b) < 0
+	})
+
+	return state, changes, nil
+}
+
+type watchedBucket struct {
+	exists   bool
+	openedAt time.Time
+	txID     int
+	entries  map[string]watchedEntry
+}
+
+func newWatchedBucket() watchedBucket {
+	return watchedBucket{
+		entries: make(map[string]watchedEntry),
+	}
+}
+
+// watchedEntry doesn't retain the value so that watching large buckets
+// doesn't require keeping all of their values in memory.
+type watchedEntry struct {
+	bucket bool
+	hash   [sha256.Size]byte
+}
+
+func newWatchedEntry(entry Entry) watchedEntry {
+	return watchedEntry{
+		bucket: entry.Bucket,
+		hash:   sha256.Sum256(entry.Value.b),
+	}
+}
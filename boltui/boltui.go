@@ -0,0 +1,90 @@
+// Package boltui makes it possible to serve the user interface from within
+// other programs using a database which they already opened.
+package boltui
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	httpport "github.com/boreq/bolt-ui/ports/http"
+	"github.com/boreq/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// AuthProvider decides if a request is allowed to access the database.
+type AuthProvider = httpport.AuthProvider
+
+// AuthProviderFunc is an adapter which allows using ordinary functions as
+// auth providers.
+type AuthProviderFunc func(r *http.Request) (bool, error)
+
+func (f AuthProviderFunc) Check(r *http.Request) (bool, error) {
+	return f(r)
+}
+
+type Options struct {
+	// EnableWrites permits modifying the database. The database is served
+	// in the read-only mode by default.
+	EnableWrites bool
+
+	// PageSize is the number of entries returned when browsing a bucket.
+	// If it is zero then the default page size is used.
+	PageSize int
+
+	// Prefix is the path under which the handler is mounted, for example
+	// "/debug/bolt".
+	Prefix string
+
+	// Context stops the goroutine which periodically records the database
+	// info displayed by the frontend when it is cancelled. If it is nil then
+	// the goroutine isn't started and only the current database info is
+	// available.
+	Context context.Context
+}
+
+// NewHandler creates a handler serving the API and the frontend for the
+// provided database. The handler expects to receive requests with paths
+// starting with the prefix specified in the options. The responses are
+// compressed in the same way as by the server. The database is not closed by
+// the handler.
+func NewHandler(db *bolt.DB, authProvider AuthProvider, options Options) (http.Handler, error) {
+	if db == nil {
+		return nil, errors.New("database is nil")
+	}
+
+	if authProvider == nil {
+		return nil, errors.New("auth provider is nil")
+	}
+
+	prefix := strings.TrimSuffix(options.Prefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		return nil, errors.New("prefix must start with a slash")
+	}
+
+	conf := &config.Config{
+		EnableWrites: options.EnableWrites,
+		ReadOnly:     db.IsReadOnly(),
+		PageSize:     options.PageSize,
+		URLPrefix:    prefix,
+	}
+
+	embedded, err := wire.BuildHandler(db, authProvider, conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not build the handler")
+	}
+
+	if options.Context != nil {
+		go embedded.Sampler.Run(options.Context)
+	}
+
+	handler := httpport.Compress(embedded.Handler)
+
+	if prefix == "" {
+		return handler, nil
+	}
+
+	return http.StripPrefix(prefix, handler), nil
+}
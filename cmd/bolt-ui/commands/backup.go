@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/guinea"
+	"github.com/pkg/errors"
+)
+
+var backupCmd = guinea.Command{
+	Run: runBackup,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "database",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the database file",
+		},
+		{
+			Name:        "destination",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the backup file",
+		},
+	},
+	ShortDescription: "writes a consistent backup of the database",
+	Description: `
+Opens the database in read-only mode and writes a consistent copy of it to a
+new file. The destination file must not exist.
+`,
+}
+
+func runBackup(c guinea.Context) error {
+	conf := &config.Config{
+		ReadOnly: true,
+	}
+
+	app, cleanup, err := wire.BuildApplication(conf, config.NewDatabase(c.Arguments[0]))
+	if err != nil {
+		return errors.Wrap(err, "could not create the application")
+	}
+	defer cleanup()
+
+	destination := c.Arguments[1]
+
+	file, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not create the destination file")
+	}
+
+	var written int64
+	if err := app.Backup.Execute(application.Backup{}, func(size int64) (io.Writer, error) {
+		written = size
+		return file, nil
+	}); err != nil {
+		file.Close()
+		os.Remove(destination)
+		return errors.Wrap(err, "backup failed")
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(destination)
+		return errors.Wrap(err, "could not sync the destination file")
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(destination)
+		return errors.Wrap(err, "could not close the destination file")
+	}
+
+	fmt.Printf("written %s\n", formatSize(written))
+	return nil
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/guinea"
+	"github.com/pkg/errors"
+)
+
+var checkCmd = guinea.Command{
+	Run: runCheck,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "database",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the database file",
+		},
+	},
+	ShortDescription: "checks the consistency of the database",
+	Description: `
+Opens the database in read-only mode and performs a consistency check. All
+found problems are printed out. The command exits with a non-zero exit code if
+the database is inconsistent.
+`,
+}
+
+func runCheck(c guinea.Context) error {
+	conf := &config.Config{
+		ReadOnly: true,
+	}
+
+	app, cleanup, err := wire.BuildApplication(conf, config.NewDatabase(c.Arguments[0]))
+	if err != nil {
+		return errors.Wrap(err, "could not create the application")
+	}
+	defer cleanup()
+
+	problems, err := app.CheckConsistency.Execute(context.Background(), application.CheckConsistency{}, func(problem error) error {
+		fmt.Println(problem)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not check the database")
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("the database is inconsistent, found %d problems", problems)
+	}
+
+	fmt.Println("the database is consistent")
+	return nil
+}
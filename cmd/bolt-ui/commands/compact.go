@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/guinea"
+	"github.com/pkg/errors"
+)
+
+var compactCmd = guinea.Command{
+	Run: runCompact,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "database",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the database file",
+		},
+		{
+			Name:        "destination",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the new database file",
+		},
+	},
+	ShortDescription: "writes a compacted copy of the database",
+	Description: `
+Opens the database in read-only mode and writes a defragmented copy of it to a
+new file. The destination file must not exist. The database file itself is
+not modified.
+`,
+}
+
+func runCompact(c guinea.Context) error {
+	conf := &config.Config{
+		ReadOnly:         true,
+		EnableCompaction: true,
+	}
+
+	app, cleanup, err := wire.BuildApplication(conf, config.NewDatabase(c.Arguments[0]))
+	if err != nil {
+		return errors.Wrap(err, "could not create the application")
+	}
+	defer cleanup()
+
+	cmd, err := application.NewCompact(c.Arguments[1])
+	if err != nil {
+		return errors.Wrap(err, "could not create the command")
+	}
+
+	result, err := app.Compact.Execute(cmd, func(progress application.CompactionProgress) error {
+		fmt.Printf("copied %d/%d buckets, %s written\n", progress.CopiedBuckets, progress.TotalBuckets, formatSize(progress.Size))
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "compaction failed")
+	}
+
+	fmt.Printf("size before: %s\n", formatSize(result.SizeBefore))
+	fmt.Printf("size after: %s\n", formatSize(result.SizeAfter))
+	return nil
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/guinea"
+	"github.com/pkg/errors"
+)
+
+var diffCmd = guinea.Command{
+	Run: runDiff,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "old",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the old database file",
+		},
+		{
+			Name:        "new",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the new database file",
+		},
+		{
+			Name:        "bucket",
+			Optional:    true,
+			Multiple:    true,
+			Description: "Path to the compared bucket, by default the entire databases are compared",
+		},
+	},
+	Options: []guinea.Option{
+		{
+			Name:        nameHexPath,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Bucket names are hex encoded",
+		},
+	},
+	ShortDescription: "compares two databases",
+	Description: `
+Opens both databases in read-only mode and prints out the keys which were
+added (+), removed (-) or modified (~) in the new database. If both the old
+and the new value are JSON or CBOR then the modified fields are printed out
+as well.
+`,
+}
+
+func runDiff(c guinea.Context) error {
+	conf := &config.Config{
+		ReadOnly:   true,
+		EnableDiff: true,
+	}
+
+	path, err := readCmdPath(c.Arguments[2:], c.Options[nameHexPath].Bool())
+	if err != nil {
+		return errors.Wrap(err, "invalid path")
+	}
+
+	app, cleanup, err := wire.BuildApplication(conf, config.NewDatabase(c.Arguments[0]))
+	if err != nil {
+		return errors.Wrap(err, "could not create the application")
+	}
+	defer cleanup()
+
+	query, err := application.NewDiff(c.Arguments[1], path)
+	if err != nil {
+		return errors.Wrap(err, "could not create the query")
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	counts := make(map[application.DiffKind]int)
+
+	if err := app.Diff.Execute(context.Background(), query, func(entry application.DiffEntry) error {
+		counts[entry.Kind]++
+		return printDiffEntry(w, entry)
+	}); err != nil {
+		return errors.Wrap(err, "diff failed")
+	}
+
+	fmt.Fprintf(w, "%d added, %d removed, %d modified\n",
+		counts[application.DiffKindAdded],
+		counts[application.DiffKindRemoved],
+		counts[application.DiffKindModified],
+	)
+
+	return w.Flush()
+}
+
+func printDiffEntry(w io.Writer, entry application.DiffEntry) error {
+	name := formatCmdPath(entry.Path, entry.Key)
+
+	switch entry.Kind {
+	case application.DiffKindAdded:
+		_, err := fmt.Fprintf(w, "+ %s%s\n", name, bucketSuffix(entry.New))
+		return err
+	case application.DiffKindRemoved:
+		_, err := fmt.Fprintf(w, "- %s%s\n", name, bucketSuffix(entry.Old))
+		return err
+	}
+
+	switch {
+	case entry.Old.Bucket && entry.New.Bucket:
+		_, err := fmt.Fprintf(w, "~ %s/ (sequence %d -> %d)\n", name, entry.Old.Sequence, entry.New.Sequence)
+		return err
+	case entry.Old.Bucket:
+		_, err := fmt.Fprintf(w, "~ %s (bucket -> value)\n", name)
+		return err
+	case entry.New.Bucket:
+		_, err := fmt.Fprintf(w, "~ %s (value -> bucket)\n", name)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "~ %s\n", name); err != nil {
+		return err
+	}
+
+	for _, difference := range entry.Differences {
+		if err := printDifference(w, difference); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printDifference(w io.Writer, difference display.Difference) error {
+	var err error
+	switch difference.Kind {
+	case display.DifferenceKindAdded:
+		_, err = fmt.Fprintf(w, "    + %s: %s\n", difference.Path, formatDiffValue(difference.New))
+	case display.DifferenceKindRemoved:
+		_, err = fmt.Fprintf(w, "    - %s: %s\n", difference.Path, formatDiffValue(difference.Old))
+	default:
+		_, err = fmt.Fprintf(w, "    ~ %s: %s -> %s\n", difference.Path, formatDiffValue(difference.Old), formatDiffValue(difference.New))
+	}
+	return err
+}
+
+func bucketSuffix(state *application.DiffEntryState) string {
+	if state.Bucket {
+		return "/"
+	}
+	return ""
+}
+
+// formatCmdPath joins the path and the key using slashes. Keys which can't be
+// displayed as strings are hex encoded.
+func formatCmdPath(path []application.Key, key application.Key) string {
+	var parts []string
+	for _, pathKey := range path {
+		parts = append(parts, formatCmdKey(pathKey))
+	}
+	parts = append(parts, formatCmdKey(key))
+	return strings.Join(parts, "/")
+}
+
+func formatCmdKey(key application.Key) string {
+	b := key.Bytes()
+	if display.CanDisplayAsString(b) {
+		return string(b)
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+func formatDiffValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
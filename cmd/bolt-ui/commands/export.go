@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"os"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/bolt-ui/jsonlines"
+	"github.com/boreq/guinea"
+	"github.com/pkg/errors"
+)
+
+const (
+	nameEncoding = "encoding"
+	nameHexPath  = "hex-path"
+)
+
+var exportCmd = guinea.Command{
+	Run: runExport,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "database",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the database file",
+		},
+		{
+			Name:        "bucket",
+			Optional:    true,
+			Multiple:    true,
+			Description: "Path to the exported bucket, by default the entire database is exported",
+		},
+	},
+	Options: []guinea.Option{
+		{
+			Name:        nameEncoding,
+			Type:        guinea.String,
+			Default:     "hex",
+			Description: "Encoding of the raw values, one of: hex, base64. Default: hex",
+		},
+		{
+			Name:        nameHexPath,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Bucket names are hex encoded",
+		},
+	},
+	ShortDescription: "exports the database as JSON Lines",
+	Description: `
+Opens the database in read-only mode and writes the contents of the specified
+bucket and all of its nested buckets to the standard output as JSON Lines.
+`,
+}
+
+func runExport(c guinea.Context) error {
+	conf := &config.Config{
+		ReadOnly: true,
+	}
+
+	encoding, err := jsonlines.NewEncoding(c.Options[nameEncoding].Str())
+	if err != nil {
+		return errors.Wrap(err, "invalid encoding")
+	}
+
+	path, err := readCmdPath(c.Arguments[1:], c.Options[nameHexPath].Bool())
+	if err != nil {
+		return errors.Wrap(err, "invalid path")
+	}
+
+	app, cleanup, err := wire.BuildApplication(conf, config.NewDatabase(c.Arguments[0]))
+	if err != nil {
+		return errors.Wrap(err, "could not create the application")
+	}
+	defer cleanup()
+
+	query, err := application.NewExport(path)
+	if err != nil {
+		return errors.Wrap(err, "could not create the query")
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	encoder := jsonlines.NewEncoder(w, encoding)
+
+	if err := app.Export.Execute(context.Background(), query, func(entry application.ExportedEntry) error {
+		return encoder.Encode(entry)
+	}); err != nil {
+		return errors.Wrap(err, "export failed")
+	}
+
+	return w.Flush()
+}
+
+func readCmdPath(args []string, isHex bool) ([]application.Key, error) {
+	var path []application.Key
+	for _, arg := range args {
+		b := []byte(arg)
+		if isHex {
+			decoded, err := hex.DecodeString(arg)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not decode the bucket name")
+			}
+			b = decoded
+		}
+
+		key, err := application.NewKey(b)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create a key")
+		}
+
+		path = append(path, key)
+	}
+	return path, nil
+}
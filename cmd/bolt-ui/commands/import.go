@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/bolt-ui/jsonlines"
+	"github.com/boreq/guinea"
+	"github.com/pkg/errors"
+)
+
+const (
+	nameDryRun    = "dry-run"
+	nameBatchSize = "batch-size"
+)
+
+var importCmd = guinea.Command{
+	Run: runImport,
+	Arguments: []guinea.Argument{
+		{
+			Name:        "database",
+			Optional:    false,
+			Multiple:    false,
+			Description: "Path to the database file",
+		},
+		{
+			Name:        "bucket",
+			Optional:    true,
+			Multiple:    true,
+			Description: "Path to the bucket into which the records are imported, by default they are imported into the root of the database",
+		},
+	},
+	Options: []guinea.Option{
+		{
+			Name:        nameDryRun,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Reports the changes without modifying the database",
+		},
+		{
+			Name:        nameBatchSize,
+			Type:        guinea.Int,
+			Default:     application.DefaultImportBatchSize,
+			Description: fmt.Sprintf("Number of records written in a single transaction. Default: %d", application.DefaultImportBatchSize),
+		},
+		{
+			Name:        nameHexPath,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Bucket names are hex encoded",
+		},
+	},
+	ShortDescription: "imports JSON Lines into the database",
+	Description: `
+Reads records in the format produced by the export subcommand from the
+standard input and writes them to the database. Missing buckets are created
+and the sequences of the buckets are restored. The paths of the records are
+relative to the specified bucket.
+`,
+}
+
+func runImport(c guinea.Context) error {
+	dryRun := c.Options[nameDryRun].Bool()
+
+	conf := &config.Config{
+		EnableWrites: !dryRun,
+		ReadOnly:     dryRun,
+	}
+
+	path, err := readCmdPath(c.Arguments[1:], c.Options[nameHexPath].Bool())
+	if err != nil {
+		return errors.Wrap(err, "invalid path")
+	}
+
+	cmd, err := application.NewImport(path, dryRun, c.Options[nameBatchSize].Int())
+	if err != nil {
+		return errors.Wrap(err, "could not create the command")
+	}
+
+	app, cleanup, err := wire.BuildApplication(conf, config.NewDatabase(c.Arguments[0]))
+	if err != nil {
+		return errors.Wrap(err, "could not create the application")
+	}
+	defer cleanup()
+
+	decoder := jsonlines.NewDecoder(bufio.NewReader(os.Stdin))
+
+	result, err := app.Import.Execute(context.Background(), cmd, decoder, func(change application.ImportChange) error {
+		if dryRun && change.Kind != application.ChangeKindUnchanged {
+			fmt.Println(formatChange(change))
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "import failed")
+	}
+
+	fmt.Printf("created: %d, updated: %d, unchanged: %d\n", result.Created, result.Updated, result.Unchanged)
+	if dryRun {
+		fmt.Println("dry run, no changes were written")
+	}
+	return nil
+}
+
+func formatChange(change application.ImportChange) string {
+	kind := "key"
+	if change.Bucket {
+		kind = "bucket"
+	}
+
+	var path strings.Builder
+	for _, key := range change.Path {
+		path.WriteString(hex.EncodeToString(key.Bytes()))
+		path.WriteString("/")
+	}
+	path.WriteString(hex.EncodeToString(change.Key.Bytes()))
+
+	return fmt.Sprintf("%s %s /%s", change.Kind, kind, path.String())
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -12,9 +13,14 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/display"
 	"github.com/boreq/bolt-ui/internal/config"
 	"github.com/boreq/bolt-ui/internal/wire"
 	"github.com/boreq/bolt-ui/logging"
@@ -27,16 +33,36 @@ const (
 	nameInsecureCORS  = "insecure-cors"
 	nameInsecureToken = "insecure-token"
 	nameInsecureTLS   = "insecure-tls"
+	nameEnableWrites  = "enable-writes"
+	nameReadOnly      = "read-only"
+	nameSnapshot      = "snapshot"
+	namePageSize      = "page-size"
+
+	nameEnableCompaction = "enable-compaction"
+	nameEnableDiff       = "enable-diff"
+
+	nameProtobufDescriptors = "protobuf-descriptors"
+	nameProtobufMessages    = "protobuf-messages"
+
+	nameDecodingRules = "decoding-rules"
 )
 
 var MainCmd = guinea.Command{
 	Run: run,
+	Subcommands: map[string]*guinea.Command{
+		"check":   &checkCmd,
+		"compact": &compactCmd,
+		"backup":  &backupCmd,
+		"export":  &exportCmd,
+		"import":  &importCmd,
+		"diff":    &diffCmd,
+	},
 	Arguments: []guinea.Argument{
 		{
 			Name:        "database",
 			Optional:    false,
-			Multiple:    false,
-			Description: "Path to the database file",
+			Multiple:    true,
+			Description: "Paths to the database files or to directories containing them",
 		},
 	},
 	Options: []guinea.Option{
@@ -64,12 +90,95 @@ var MainCmd = guinea.Command{
 			Default:     false,
 			Description: "Disables serving using TLS",
 		},
+		{
+			Name:        nameEnableWrites,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Allows modifying the database",
+		},
+		{
+			Name:        nameReadOnly,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Opens the database in read-only mode using a shared lock which can't be acquired while another program has it open for writing",
+		},
+		{
+			Name:        nameSnapshot,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Serves a snapshot of the database instead of the database file",
+		},
+		{
+			Name:        namePageSize,
+			Type:        guinea.Int,
+			Default:     application.DefaultLimit,
+			Description: fmt.Sprintf("Number of entries loaded at once when browsing, at most %d. Default: %d", application.MaxLimit, application.DefaultLimit),
+		},
+		{
+			Name:        nameEnableCompaction,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Allows writing compacted copies of the database to arbitrary paths",
+		},
+		{
+			Name:        nameEnableDiff,
+			Type:        guinea.Bool,
+			Default:     false,
+			Description: "Allows comparing the database with database files located at arbitrary paths",
+		},
+		{
+			Name:        nameProtobufDescriptors,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Path to a file containing a protobuf FileDescriptorSet",
+		},
+		{
+			Name:        nameProtobufMessages,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Protobuf messages stored in buckets, for example: bucket/nested=package.Message,other=package.Other",
+		},
+		{
+			Name:        nameDecodingRules,
+			Type:        guinea.String,
+			Default:     "",
+			Description: "Path to a JSON file containing the rules which specify how keys and values stored in buckets are decoded",
+		},
 	},
 	ShortDescription: "a web user interface for the Bolt database",
 	Description: `
 Thanks to bolt-ui you are able to explore a Bolt database using a web
 interface. To access the web interface access the address printed out by the
 program. Make sure that the address includes the token query parameter.
+
+Multiple databases can be served at once. If a directory is specified then all
+files with the .db or .bolt extension which are located in it are served.
+Each database is named after its file and its API is available under
+/api/db/{name}/. The first database is also available directly under /api/.
+
+Values which use the protobuf wire format are displayed using field numbers
+and wire types. If a FileDescriptorSet is provided then the values stored in
+the buckets listed using the protobuf-messages option are decoded using the
+specified message types and displayed as JSON. The descriptor set can be
+created using protoc with the --descriptor_set_out and --include_imports
+flags. Bucket names equal to "*" match any bucket.
+
+The content types of the values and the way keys are displayed are detected
+automatically. The decoding-rules option can be used to override them for
+specific buckets using a JSON file:
+
+    {
+        "rules": [
+            {"bucket": "users/*/sessions", "key": "uint64", "value": "protobuf"},
+            {"bucket": "events", "key": "ulid", "value": "gzip+cbor"}
+        ]
+    }
+
+Bucket names equal to "*" match any bucket. Keys can be displayed using the
+string, hex, uint64, int64, ulid or uuid codecs. Values can be displayed
+using any content type, optionally prefixed with the compression, or as raw
+bytes using "raw". If a key or a value can't be decoded as specified then it
+is detected automatically.
 `,
 }
 
@@ -93,23 +202,110 @@ func run(c guinea.Context) error {
 		log.Warn("insecure-tls option enabled")
 	}
 
-	service, err := wire.BuildService(conf)
+	if conf.EnableWrites {
+		log.Warn("enable-writes option enabled")
+	}
+
+	if conf.EnableCompaction {
+		log.Warn("enable-compaction option enabled")
+	}
+
+	if conf.EnableDiff {
+		log.Warn("enable-diff option enabled")
+	}
+
+	if conf.ProtobufDescriptors != "" {
+		types, err := display.NewProtobufTypes(conf.ProtobufDescriptors, conf.ProtobufMessages)
+		if err != nil {
+			return errors.Wrap(err, "could not load the protobuf types")
+		}
+		display.RegisterProtobufTypes(types)
+	}
+
+	if conf.DecodingRules != "" {
+		rules, err := display.LoadRules(conf.DecodingRules)
+		if err != nil {
+			return errors.Wrap(err, "could not load the decoding rules")
+		}
+		if err := display.RegisterRules(rules); err != nil {
+			return errors.Wrap(err, "could not register the decoding rules")
+		}
+	}
+
+	service, cleanup, err := wire.BuildService(conf)
 	if err != nil {
 		return errors.Wrap(err, "could not create a service")
 	}
+	defer cleanup()
 
 	printInfo(conf)
 
-	return service.HTTPServer.Serve()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, database := range service.Databases {
+		go database.Sampler.Run(ctx)
+		go database.Source.Watch(ctx)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- service.HTTPServer.Serve()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+		log.Info("shutting down")
+		return nil
+	}
 }
 
 func newConfig(c guinea.Context) (*config.Config, error) {
 	conf := &config.Config{
 		ServeAddress:  c.Options[nameAddress].Str(),
-		DatabaseFile:  c.Arguments[0],
 		InsecureCORS:  c.Options[nameInsecureCORS].Bool(),
 		InsecureToken: c.Options[nameInsecureToken].Bool(),
 		InsecureTLS:   c.Options[nameInsecureTLS].Bool(),
+		EnableWrites:  c.Options[nameEnableWrites].Bool(),
+		ReadOnly:      c.Options[nameReadOnly].Bool(),
+		Snapshot:      c.Options[nameSnapshot].Bool(),
+		PageSize:      c.Options[namePageSize].Int(),
+
+		EnableCompaction: c.Options[nameEnableCompaction].Bool(),
+		EnableDiff:       c.Options[nameEnableDiff].Bool(),
+
+		ProtobufDescriptors: c.Options[nameProtobufDescriptors].Str(),
+		DecodingRules:       c.Options[nameDecodingRules].Str(),
+	}
+
+	protobufMessages, err := parseProtobufMessages(c.Options[nameProtobufMessages].Str())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid protobuf messages")
+	}
+	conf.ProtobufMessages = protobufMessages
+
+	if len(conf.ProtobufMessages) > 0 && conf.ProtobufDescriptors == "" {
+		return nil, errors.New("protobuf-messages option requires the protobuf-descriptors option")
+	}
+
+	databases, err := config.FindDatabases(c.Arguments)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find the databases")
+	}
+	conf.Databases = databases
+
+	if conf.PageSize < 1 || conf.PageSize > application.MaxLimit {
+		return nil, errors.Errorf("page-size option must be between 1 and %d", application.MaxLimit)
+	}
+
+	if conf.EnableWrites && conf.ReadOnly {
+		return nil, errors.New("enable-writes and read-only options can not be used at the same time")
+	}
+
+	if conf.EnableWrites && conf.Snapshot {
+		return nil, errors.New("enable-writes and snapshot options can not be used at the same time")
 	}
 
 	if !conf.InsecureToken {
@@ -131,6 +327,30 @@ func newConfig(c guinea.Context) (*config.Config, error) {
 	return conf, nil
 }
 
+// parseProtobufMessages parses a comma separated list of bucket paths and
+// message names separated using equal signs.
+func parseProtobufMessages(s string) (map[string]string, error) {
+	messages := make(map[string]string)
+	if s == "" {
+		return messages, nil
+	}
+
+	for _, element := range strings.Split(s, ",") {
+		bucket, message, ok := strings.Cut(element, "=")
+		if !ok || bucket == "" || message == "" {
+			return nil, fmt.Errorf("expected 'bucket=message' but got '%s'", element)
+		}
+
+		if _, ok := messages[bucket]; ok {
+			return nil, fmt.Errorf("bucket '%s' is listed more than once", bucket)
+		}
+
+		messages[bucket] = message
+	}
+
+	return messages, nil
+}
+
 func generateCertificate() (tls.Certificate, error) {
 	hosts := []string{
 		"localhost",
@@ -201,7 +421,14 @@ func printInfo(conf *config.Config) {
 		addr = fmt.Sprintf("%s/?token=%s", addr, conf.Token)
 	}
 
-	fmt.Printf("You can view database '%s' by clicking on this link:\n", conf.DatabaseFile)
+	if len(conf.Databases) == 1 {
+		fmt.Printf("You can view database '%s' by clicking on this link:\n", conf.Databases[0].File)
+	} else {
+		fmt.Println("You can view the following databases by clicking on this link:")
+		for _, database := range conf.Databases {
+			fmt.Printf("  %s: %s\n", database.Name, database.File)
+		}
+	}
 	fmt.Println(addr)
 	if !conf.InsecureTLS {
 		fmt.Println()
@@ -0,0 +1,381 @@
+package display
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+)
+
+// PrettifierBSON displays BSON documents as relaxed MongoDB Extended JSON.
+// The length of the document must be equal to the length of the value, all
+// lengths and terminators of the nested elements must be valid, strings must
+// be valid UTF-8 and the keys of arrays must be consecutive indexes.
+// Deprecated element types are rejected.
+type PrettifierBSON struct {
+}
+
+func NewPrettifierBSON() *PrettifierBSON {
+	return &PrettifierBSON{}
+}
+
+func (p *PrettifierBSON) Prettify(b []byte) (string, error) {
+	v, err := p.decode(nil, b)
+	if err != nil {
+		return "", err
+	}
+	return marshalStructured(v)
+}
+
+func (p *PrettifierBSON) decode(path [][]byte, b []byte) (interface{}, error) {
+	v, err := decodeBSONDocument(b, false, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid bson")
+	}
+	return v, nil
+}
+
+// decodeBSONDocument decodes a document which must take up the entire slice.
+func decodeBSONDocument(b []byte, array bool, depth int) (interface{}, error) {
+	if depth > maxStructuredDepth {
+		return nil, errors.New("maximum depth exceeded")
+	}
+
+	if len(b) < 5 {
+		return nil, errors.New("document is too short")
+	}
+
+	if int(binary.LittleEndian.Uint32(b)) != len(b) {
+		return nil, errors.New("invalid document length")
+	}
+
+	if b[len(b)-1] != 0 {
+		return nil, errors.New("document isn't terminated")
+	}
+
+	d := &bsonDecoder{b: b[4 : len(b)-1]}
+
+	var document orderedMap
+	var elements []interface{}
+
+	for len(d.b) > 0 {
+		t, err := d.read(1)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the element type")
+		}
+
+		key, err := d.readCString()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the key")
+		}
+
+		v, err := d.decodeElement(t[0], depth)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode element '%s'", key)
+		}
+
+		if array {
+			if key != strconv.Itoa(len(elements)) {
+				return nil, fmt.Errorf("unexpected array key '%s'", key)
+			}
+			elements = append(elements, v)
+		} else {
+			if document.has(key) {
+				return nil, fmt.Errorf("duplicate key '%s'", key)
+			}
+			document = append(document, orderedMapEntry{Key: key, Value: v})
+		}
+	}
+
+	if array {
+		if elements == nil {
+			return []interface{}{}, nil
+		}
+		return elements, nil
+	}
+
+	if document == nil {
+		return orderedMap{}, nil
+	}
+	return document, nil
+}
+
+type bsonDecoder struct {
+	b []byte
+}
+
+func (d *bsonDecoder) decodeElement(t byte, depth int) (interface{}, error) {
+	switch t {
+	case 0x01:
+		v, err := d.read(8)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read double")
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(v))
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return orderedMap{{Key: "$numberDouble", Value: structuredFloat(f, 64)}}, nil
+		}
+		return structuredFloat(f, 64), nil
+	case 0x02:
+		return d.readString()
+	case 0x03, 0x04:
+		n, err := d.peekLength()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the document length")
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the document")
+		}
+		return decodeBSONDocument(b, t == 0x04, depth+1)
+	case 0x05:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the binary length")
+		}
+		subtype, err := d.read(1)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the binary subtype")
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read binary data")
+		}
+		return orderedMap{{Key: "$binary", Value: orderedMap{
+			{Key: "base64", Value: base64.StdEncoding.EncodeToString(b)},
+			{Key: "subType", Value: hex.EncodeToString(subtype)},
+		}}}, nil
+	case 0x07:
+		b, err := d.read(12)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read object id")
+		}
+		return orderedMap{{Key: "$oid", Value: hex.EncodeToString(b)}}, nil
+	case 0x08:
+		b, err := d.read(1)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read boolean")
+		}
+		switch b[0] {
+		case 0:
+			return false, nil
+		case 1:
+			return true, nil
+		default:
+			return nil, errors.New("invalid boolean")
+		}
+	case 0x09:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read datetime")
+		}
+		ms := int64(binary.LittleEndian.Uint64(b))
+		if t := time.UnixMilli(ms).UTC(); t.Year() >= 1970 && t.Year() <= 9999 {
+			return orderedMap{{Key: "$date", Value: t.Format("2006-01-02T15:04:05.999Z07:00")}}, nil
+		}
+		return orderedMap{{Key: "$date", Value: orderedMap{{Key: "$numberLong", Value: strconv.FormatInt(ms, 10)}}}}, nil
+	case 0x0a:
+		return nil, nil
+	case 0x0b:
+		pattern, err := d.readCString()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the pattern")
+		}
+		options, err := d.readCString()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the options")
+		}
+		return orderedMap{{Key: "$regularExpression", Value: orderedMap{
+			{Key: "pattern", Value: pattern},
+			{Key: "options", Value: options},
+		}}}, nil
+	case 0x0d:
+		code, err := d.readString()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read code")
+		}
+		return orderedMap{{Key: "$code", Value: code}}, nil
+	case 0x10:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read int32")
+		}
+		return int32(binary.LittleEndian.Uint32(b)), nil
+	case 0x11:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read timestamp")
+		}
+		v := binary.LittleEndian.Uint64(b)
+		return orderedMap{{Key: "$timestamp", Value: orderedMap{
+			{Key: "t", Value: v >> 32},
+			{Key: "i", Value: v & 0xffffffff},
+		}}}, nil
+	case 0x12:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read int64")
+		}
+		return int64(binary.LittleEndian.Uint64(b)), nil
+	case 0x13:
+		b, err := d.read(16)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read decimal128")
+		}
+		return orderedMap{{Key: "$numberDecimal", Value: formatDecimal128(b)}}, nil
+	case 0xff:
+		return orderedMap{{Key: "$minKey", Value: 1}}, nil
+	case 0x7f:
+		return orderedMap{{Key: "$maxKey", Value: 1}}, nil
+	default:
+		return nil, fmt.Errorf("invalid or deprecated element type 0x%02x", t)
+	}
+}
+
+func (d *bsonDecoder) readString() (string, error) {
+	n, err := d.readLength()
+	if err != nil {
+		return "", errors.Wrap(err, "could not read the string length")
+	}
+
+	if n < 1 {
+		return "", errors.New("invalid string length")
+	}
+
+	b, err := d.read(n)
+	if err != nil {
+		return "", errors.Wrap(err, "could not read the string")
+	}
+
+	if b[n-1] != 0 {
+		return "", errors.New("string isn't terminated")
+	}
+
+	if !utf8.Valid(b[:n-1]) {
+		return "", errors.New("string isn't valid utf-8")
+	}
+
+	return string(b[:n-1]), nil
+}
+
+func (d *bsonDecoder) readCString() (string, error) {
+	i := bytes.IndexByte(d.b, 0)
+	if i < 0 {
+		return "", errors.New("cstring isn't terminated")
+	}
+
+	b := d.b[:i]
+	d.b = d.b[i+1:]
+
+	if !utf8.Valid(b) {
+		return "", errors.New("cstring isn't valid utf-8")
+	}
+
+	return string(b), nil
+}
+
+func (d *bsonDecoder) readLength() (int, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+
+	n := int32(binary.LittleEndian.Uint32(b))
+	if n < 0 {
+		return 0, errors.New("negative length")
+	}
+	return int(n), nil
+}
+
+// peekLength reads the length of an embedded document which includes the
+// length itself.
+func (d *bsonDecoder) peekLength() (int, error) {
+	if len(d.b) < 4 {
+		return 0, errors.New("unexpected end of data")
+	}
+
+	n := int32(binary.LittleEndian.Uint32(d.b))
+	if n < 5 {
+		return 0, errors.New("invalid length")
+	}
+	return int(n), nil
+}
+
+func (d *bsonDecoder) read(n int) ([]byte, error) {
+	if n > len(d.b) {
+		return nil, errors.New("unexpected end of data")
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
+
+// formatDecimal128 converts an IEEE 754-2008 128-bit decimal floating point
+// number using the binary integer decimal encoding to a string following the
+// BSON specification.
+func formatDecimal128(b []byte) string {
+	low := binary.LittleEndian.Uint64(b[:8])
+	high := binary.LittleEndian.Uint64(b[8:])
+
+	sign := ""
+	if high>>63 == 1 {
+		sign = "-"
+	}
+
+	switch (high >> 58) & 0x1f {
+	case 0x1f:
+		return "NaN"
+	case 0x1e:
+		return sign + "Infinity"
+	}
+
+	var exponent int
+	significand := new(big.Int)
+
+	if (high>>61)&0x3 == 0x3 {
+		// the significand would exceed the maximum allowed value so
+		// the value is non-canonical and interpreted as zero
+		exponent = int((high>>47)&0x3fff) - 6176
+	} else {
+		exponent = int((high>>49)&0x3fff) - 6176
+		significand.SetUint64(high & 0x1ffffffffffff)
+		significand.Lsh(significand, 64)
+		significand.Or(significand, new(big.Int).SetUint64(low))
+
+		if significand.Cmp(maxDecimal128Significand) > 0 {
+			significand.SetUint64(0)
+		}
+	}
+
+	digits := significand.String()
+	adjusted := exponent + len(digits) - 1
+
+	if exponent > 0 || adjusted < -6 {
+		result := digits[:1]
+		if len(digits) > 1 {
+			result += "." + digits[1:]
+		}
+		return fmt.Sprintf("%s%sE%+d", sign, result, adjusted)
+	}
+
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	point := len(digits) + exponent
+	if point > 0 {
+		return sign + digits[:point] + "." + digits[point:]
+	}
+	return sign + "0." + strings.Repeat("0", -point) + digits
+}
+
+var maxDecimal128Significand, _ = new(big.Int).SetString("9999999999999999999999999999999999", 10)
@@ -26,6 +26,15 @@ func (p PrettifierCBOR) Prettify(b []byte) (string, error) {
 	return cborToText(b)
 }
 
+// decode prettifies the value first as values are displayed and decoded using
+// different libraries which may not accept the same values.
+func (p PrettifierCBOR) decode(path [][]byte, b []byte) (interface{}, error) {
+	if _, err := p.Prettify(b); err != nil {
+		return nil, err
+	}
+	return decodeCBOR(b)
+}
+
 // from https://github.com/boreq/bolt-ui/pull/2
 func cborToText(dataCBOR []byte) (string, error) {
 	var buf bytes.Buffer
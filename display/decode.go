@@ -0,0 +1,88 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boreq/errors"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Decode decodes values which are recognized as CBOR or JSON, in that order,
+// mirroring the order used by Pretty. Maps are always decoded as
+// map[string]interface{} and arrays as []interface{} so that the results of
+// decoding both formats can be inspected in the same way. JSON numbers are
+// decoded as json.Number.
+func Decode(b []byte) (interface{}, ContentType, error) {
+	if v, err := decodeCBOR(b); err == nil {
+		return v, ContentTypeCBOR, nil
+	}
+
+	if v, err := decodeJSON(b); err == nil {
+		return v, ContentTypeJSON, nil
+	}
+
+	return nil, ContentType{}, errors.New("value is neither cbor nor json")
+}
+
+func decodeCBOR(b []byte) (interface{}, error) {
+	if err := cbor.Wellformed(b); err != nil {
+		return nil, errors.Wrap(err, "invalid cbor")
+	}
+
+	var v interface{}
+	if err := cbor.Unmarshal(b, &v); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal")
+	}
+
+	return normalizeCBOR(v), nil
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	if !json.Valid(b) {
+		return nil, errors.New("invalid json")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "could not decode")
+	}
+
+	return v, nil
+}
+
+func normalizeCBOR(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, value := range typed {
+			result[cborKeyToString(key)] = normalizeCBOR(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, value := range typed {
+			result[i] = normalizeCBOR(value)
+		}
+		return result
+	case cbor.Tag:
+		return normalizeCBOR(typed.Content)
+	default:
+		return v
+	}
+}
+
+func cborKeyToString(key interface{}) string {
+	switch typed := key.(type) {
+	case string:
+		return typed
+	case []byte:
+		return string(typed)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
@@ -0,0 +1,110 @@
+package display
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/boreq/errors"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxDecompressedSize limits the size of decompressed values. Compressed
+// values which exceed it are prettified as if they weren't compressed.
+const MaxDecompressedSize = 16 * 1024 * 1024
+
+var (
+	ContentTypeGzip   ContentType = ContentType{"gzip"}
+	ContentTypeZstd   ContentType = ContentType{"zstd"}
+	ContentTypeSnappy ContentType = ContentType{"snappy"}
+)
+
+type decompressor struct {
+	ContentType ContentType
+	Magic       []byte
+	NewReader   func(r io.Reader) (io.ReadCloser, error)
+}
+
+// decompressors recognize compressed values using their magic bytes. Snappy
+// values are only recognized if they use the framing format as the block
+// format doesn't have a header.
+var decompressors = []decompressor{
+	{
+		ContentType: ContentTypeGzip,
+		Magic:       []byte{0x1f, 0x8b},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		ContentType: ContentTypeZstd,
+		Magic:       []byte{0x28, 0xb5, 0x2f, 0xfd},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(r,
+				zstd.WithDecoderConcurrency(1),
+				zstd.WithDecoderMaxMemory(MaxDecompressedSize),
+			)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+	},
+	{
+		ContentType: ContentTypeSnappy,
+		Magic:       []byte{0xff, 0x06, 0x00, 0x00, 's', 'N', 'a', 'P', 'p', 'Y'},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(snappy.NewReader(r)), nil
+		},
+	},
+}
+
+// decompress returns false if the value isn't compressed or couldn't be
+// decompressed.
+func decompress(b []byte) (ContentType, []byte, bool) {
+	for _, decompressor := range decompressors {
+		if !bytes.HasPrefix(b, decompressor.Magic) {
+			continue
+		}
+
+		decompressed, err := decompressor.decompress(b)
+		if err != nil {
+			return ContentType{}, nil, false
+		}
+		return decompressor.ContentType, decompressed, true
+	}
+	return ContentType{}, nil, false
+}
+
+func findDecompressor(t ContentType) (decompressor, bool) {
+	for _, decompressor := range decompressors {
+		if decompressor.ContentType == t {
+			return decompressor, true
+		}
+	}
+	return decompressor{}, false
+}
+
+func (d decompressor) decompress(b []byte) ([]byte, error) {
+	r, err := d.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create a reader")
+	}
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decompress")
+	}
+
+	if len(decompressed) > MaxDecompressedSize {
+		return nil, errors.New("decompressed value is too large")
+	}
+
+	return decompressed, nil
+}
+
+func compressedContentType(compression ContentType, t ContentType) ContentType {
+	return ContentType{compression.s + "+" + t.s}
+}
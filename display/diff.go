@@ -0,0 +1,153 @@
+package display
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/boreq/bolt-ui/jsonpath"
+)
+
+type DifferenceKind struct {
+	s string
+}
+
+func (k DifferenceKind) String() string {
+	return k.s
+}
+
+var (
+	DifferenceKindAdded    = DifferenceKind{"added"}
+	DifferenceKindRemoved  = DifferenceKind{"removed"}
+	DifferenceKindModified = DifferenceKind{"modified"}
+)
+
+// Difference describes a single element which differs between two decoded
+// values. Old is nil if the element was added and New is nil if the element
+// was removed.
+type Difference struct {
+	Path jsonpath.Path
+	Kind DifferenceKind
+	Old  interface{}
+	New  interface{}
+}
+
+// Diff returns the structural differences between two values if both of them
+// are recognized as JSON or CBOR by Pretty. The returned bool is false if a
+// structural diff can't be performed.
+func (p *Pretty) Diff(old, new []byte) ([]Difference, bool) {
+	oldValue, ok := p.decodeStructured(old)
+	if !ok {
+		return nil, false
+	}
+
+	newValue, ok := p.decodeStructured(new)
+	if !ok {
+		return nil, false
+	}
+
+	return DiffValues(oldValue, newValue), true
+}
+
+func (p *Pretty) decodeStructured(b []byte) (interface{}, bool) {
+	decoded, err := p.DecodeInBucket(nil, b)
+	if err != nil {
+		return nil, false
+	}
+
+	if decoded.Type != ContentTypeJSON && decoded.Type != ContentTypeCBOR {
+		return nil, false
+	}
+
+	return decoded.Value, true
+}
+
+// DiffValues compares two values decoded using Decode. Maps are compared key
+// by key in key order and arrays element by element. Other values are
+// compared as a whole.
+func DiffValues(old, new interface{}) []Difference {
+	return diffValues(jsonpath.Root(), old, new, nil)
+}
+
+func diffValues(path jsonpath.Path, old, new interface{}, differences []Difference) []Difference {
+	switch oldTyped := old.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := new.(map[string]interface{}); ok {
+			return diffMaps(path, oldTyped, newTyped, differences)
+		}
+	case []interface{}:
+		if newTyped, ok := new.([]interface{}); ok {
+			return diffArrays(path, oldTyped, newTyped, differences)
+		}
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		differences = append(differences, Difference{
+			Path: path,
+			Kind: DifferenceKindModified,
+			Old:  old,
+			New:  new,
+		})
+	}
+
+	return differences
+}
+
+func diffMaps(path jsonpath.Path, old, new map[string]interface{}, differences []Difference) []Difference {
+	keys := make([]string, 0, len(old)+len(new))
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldValue, inOld := old[key]
+		newValue, inNew := new[key]
+
+		switch {
+		case !inNew:
+			differences = append(differences, Difference{
+				Path: path.Field(key),
+				Kind: DifferenceKindRemoved,
+				Old:  oldValue,
+			})
+		case !inOld:
+			differences = append(differences, Difference{
+				Path: path.Field(key),
+				Kind: DifferenceKindAdded,
+				New:  newValue,
+			})
+		default:
+			differences = diffValues(path.Field(key), oldValue, newValue, differences)
+		}
+	}
+
+	return differences
+}
+
+func diffArrays(path jsonpath.Path, old, new []interface{}, differences []Difference) []Difference {
+	for i := 0; i < len(old) || i < len(new); i++ {
+		switch {
+		case i >= len(new):
+			differences = append(differences, Difference{
+				Path: path.Index(i),
+				Kind: DifferenceKindRemoved,
+				Old:  old[i],
+			})
+		case i >= len(old):
+			differences = append(differences, Difference{
+				Path: path.Index(i),
+				Kind: DifferenceKindAdded,
+				New:  new[i],
+			})
+		default:
+			differences = diffValues(path.Index(i), old[i], new[i], differences)
+		}
+	}
+
+	return differences
+}
@@ -0,0 +1,51 @@
+package display_test
+
+import (
+	"testing"
+
+	"github.com/boreq/bolt-ui/display"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrettyDiff(t *testing.T) {
+	old, err := cbor.Marshal(map[string]interface{}{
+		"status": "pending",
+		"nested": map[string]interface{}{
+			"a": 1,
+		},
+	})
+	require.NoError(t, err)
+
+	new, err := cbor.Marshal(map[string]interface{}{
+		"status": "pending",
+		"nested": map[string]interface{}{
+			"a": 2,
+			"b": true,
+		},
+	})
+	require.NoError(t, err)
+
+	p := display.NewPretty()
+
+	differences, ok := p.Diff(old, new)
+	require.True(t, ok)
+	require.Len(t, differences, 2)
+
+	require.Equal(t, "$.nested.a", differences[0].Path.String())
+	require.Equal(t, display.DifferenceKindModified, differences[0].Kind)
+	require.Equal(t, uint64(1), differences[0].Old)
+	require.Equal(t, uint64(2), differences[0].New)
+
+	require.Equal(t, "$.nested.b", differences[1].Path.String())
+	require.Equal(t, display.DifferenceKindAdded, differences[1].Kind)
+	require.Nil(t, differences[1].Old)
+	require.Equal(t, true, differences[1].New)
+
+	differences, ok = p.Diff(old, old)
+	require.True(t, ok)
+	require.Empty(t, differences)
+
+	_, ok = p.Diff(old, []byte("some_string"))
+	require.False(t, ok)
+}
@@ -0,0 +1,659 @@
+package display
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+)
+
+// Ids of the types predefined by encoding/gob.
+const (
+	gobBool      = 1
+	gobInt       = 2
+	gobUint      = 3
+	gobFloat     = 4
+	gobBytes     = 5
+	gobString    = 6
+	gobComplex   = 7
+	gobInterface = 8
+
+	gobFirstUserId = 64
+)
+
+type gobKind int
+
+const (
+	gobKindArray gobKind = iota
+	gobKindSlice
+	gobKindStruct
+	gobKindMap
+	gobKindGobEncoder
+	gobKindBinaryMarshaler
+	gobKindTextMarshaler
+)
+
+// gobType is a type definition sent in a gob stream.
+type gobType struct {
+	kind   gobKind
+	name   string
+	key    int64
+	elem   int64
+	len    int64
+	fields []gobField
+}
+
+type gobField struct {
+	name string
+	id   int64
+}
+
+// PrettifierGob displays values encoded using encoding/gob as JSON without
+// knowing their Go types. The value must be a complete gob stream containing
+// the definitions of the used types followed by exactly one value. All
+// integers must be encoded minimally and all types must be defined.
+type PrettifierGob struct {
+}
+
+func NewPrettifierGob() *PrettifierGob {
+	return &PrettifierGob{}
+}
+
+func (p *PrettifierGob) Prettify(b []byte) (string, error) {
+	v, err := p.decode(nil, b)
+	if err != nil {
+		return "", err
+	}
+	return marshalStructured(v)
+}
+
+func (p *PrettifierGob) decode(path [][]byte, b []byte) (interface{}, error) {
+	d := &gobDecoder{
+		stream: b,
+		types:  make(map[int64]gobType),
+	}
+
+	v, err := d.decodeStream()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gob")
+	}
+
+	return v, nil
+}
+
+// gobDecoder reads the stream one message at a time. Similarly to
+// encoding/gob the type definitions needed by interface values can be sent in
+// separate messages in the middle of a value.
+type gobDecoder struct {
+	stream []byte
+	b      []byte
+	types  map[int64]gobType
+}
+
+func (d *gobDecoder) decodeStream() (interface{}, error) {
+	for {
+		if len(d.b) == 0 {
+			if err := d.receiveMessage(); err != nil {
+				return nil, errors.Wrap(err, "could not receive a message")
+			}
+		}
+
+		id, err := d.readInt()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the type id")
+		}
+
+		if id >= 0 {
+			v, err := d.decodeTopLevelValue(id)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not decode the value")
+			}
+
+			if len(d.b) > 0 || len(d.stream) > 0 {
+				return nil, errors.New("extraneous data after the value")
+			}
+
+			return v, nil
+		}
+
+		if err := d.decodeTypeDefinition(-id); err != nil {
+			return nil, errors.Wrap(err, "could not decode a type definition")
+		}
+
+		if len(d.b) > 0 {
+			return nil, errors.New("extraneous data after a type definition")
+		}
+	}
+}
+
+func (d *gobDecoder) receiveMessage() error {
+	if len(d.stream) == 0 {
+		return errors.New("unexpected end of stream")
+	}
+
+	stream := &gobDecoder{b: d.stream}
+
+	n, err := stream.readLength()
+	if err != nil {
+		return errors.Wrap(err, "could not read the length")
+	}
+
+	if n == 0 {
+		return errors.New("empty message")
+	}
+
+	message, err := stream.read(n)
+	if err != nil {
+		return errors.Wrap(err, "could not read the message")
+	}
+
+	d.stream = stream.b
+	d.b = message
+	return nil
+}
+
+func (d *gobDecoder) decodeTypeDefinition(id int64) error {
+	if id < gobFirstUserId {
+		return fmt.Errorf("invalid type id %d", id)
+	}
+
+	if _, ok := d.types[id]; ok {
+		return fmt.Errorf("type %d is defined more than once", id)
+	}
+
+	t, err := d.decodeWireType()
+	if err != nil {
+		return errors.Wrap(err, "could not decode the wire type")
+	}
+
+	d.types[id] = t
+	return nil
+}
+
+// decodeWireType decodes the wireType struct defined by encoding/gob.
+func (d *gobDecoder) decodeWireType() (gobType, error) {
+	var t gobType
+	set := false
+
+	if err := d.decodeStruct(func(field int) error {
+		if set {
+			return errors.New("wire type defines more than one type")
+		}
+		set = true
+
+		switch field {
+		case 0:
+			t.kind = gobKindArray
+			return d.decodeStruct(func(field int) error {
+				switch field {
+				case 0:
+					return d.decodeCommonType(&t)
+				case 1:
+					return d.readIntField(&t.elem)
+				case 2:
+					return d.readIntField(&t.len)
+				default:
+					return fmt.Errorf("unknown array type field %d", field)
+				}
+			})
+		case 1:
+			t.kind = gobKindSlice
+			return d.decodeStruct(func(field int) error {
+				switch field {
+				case 0:
+					return d.decodeCommonType(&t)
+				case 1:
+					return d.readIntField(&t.elem)
+				default:
+					return fmt.Errorf("unknown slice type field %d", field)
+				}
+			})
+		case 2:
+			t.kind = gobKindStruct
+			return d.decodeStruct(func(field int) error {
+				switch field {
+				case 0:
+					return d.decodeCommonType(&t)
+				case 1:
+					return d.decodeFields(&t)
+				default:
+					return fmt.Errorf("unknown struct type field %d", field)
+				}
+			})
+		case 3:
+			t.kind = gobKindMap
+			return d.decodeStruct(func(field int) error {
+				switch field {
+				case 0:
+					return d.decodeCommonType(&t)
+				case 1:
+					return d.readIntField(&t.key)
+				case 2:
+					return d.readIntField(&t.elem)
+				default:
+					return fmt.Errorf("unknown map type field %d", field)
+				}
+			})
+		case 4, 5, 6:
+			t.kind = gobKindGobEncoder + gobKind(field-4)
+			return d.decodeStruct(func(field int) error {
+				switch field {
+				case 0:
+					return d.decodeCommonType(&t)
+				default:
+					return fmt.Errorf("unknown encoder type field %d", field)
+				}
+			})
+		default:
+			return fmt.Errorf("unknown wire type field %d", field)
+		}
+	}); err != nil {
+		return gobType{}, err
+	}
+
+	if !set {
+		return gobType{}, errors.New("wire type is empty")
+	}
+
+	return t, nil
+}
+
+func (d *gobDecoder) decodeCommonType(t *gobType) error {
+	return d.decodeStruct(func(field int) error {
+		switch field {
+		case 0:
+			name, err := d.readString()
+			if err != nil {
+				return errors.Wrap(err, "could not read the name")
+			}
+			t.name = name
+			return nil
+		case 1:
+			var id int64
+			return d.readIntField(&id)
+		default:
+			return fmt.Errorf("unknown common type field %d", field)
+		}
+	})
+}
+
+func (d *gobDecoder) decodeFields(t *gobType) error {
+	n, err := d.readLength()
+	if err != nil {
+		return errors.Wrap(err, "could not read the number of fields")
+	}
+
+	if n > len(d.b) {
+		return errors.New("number of fields exceeds the remaining data")
+	}
+
+	for i := 0; i < n; i++ {
+		var f gobField
+		if err := d.decodeStruct(func(field int) error {
+			switch field {
+			case 0:
+				name, err := d.readString()
+				if err != nil {
+					return errors.Wrap(err, "could not read the name")
+				}
+				f.name = name
+				return nil
+			case 1:
+				return d.readIntField(&f.id)
+			default:
+				return fmt.Errorf("unknown field type field %d", field)
+			}
+		}); err != nil {
+			return errors.Wrapf(err, "could not decode field %d", i)
+		}
+		t.fields = append(t.fields, f)
+	}
+
+	return nil
+}
+
+// decodeTopLevelValue decodes a value which isn't a field of a struct.
+// Values other than structs are sent as if they were the first field of a
+// struct.
+func (d *gobDecoder) decodeTopLevelValue(id int64) (interface{}, error) {
+	if t, ok := d.types[id]; !ok || t.kind != gobKindStruct {
+		delta, err := d.readUint()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the delta")
+		}
+
+		if delta != 0 {
+			return nil, errors.New("non-zero delta for a value which isn't a struct")
+		}
+	}
+
+	return d.decodeValue(id, 0)
+}
+
+func (d *gobDecoder) decodeValue(id int64, depth int) (interface{}, error) {
+	if depth > maxStructuredDepth {
+		return nil, errors.New("maximum depth exceeded")
+	}
+
+	switch id {
+	case gobBool:
+		v, err := d.readUint()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read bool")
+		}
+		if v > 1 {
+			return nil, errors.New("invalid bool")
+		}
+		return v == 1, nil
+	case gobInt:
+		return d.readInt()
+	case gobUint:
+		return d.readUint()
+	case gobFloat:
+		f, err := d.readFloat()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read float")
+		}
+		return structuredFloat(f, 64), nil
+	case gobComplex:
+		real, err := d.readFloat()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the real part")
+		}
+		imag, err := d.readFloat()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the imaginary part")
+		}
+		return strconv.FormatComplex(complex(real, imag), 'g', -1, 128), nil
+	case gobBytes:
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read bytes")
+		}
+		return structuredBytes(b), nil
+	case gobString:
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read string")
+		}
+		if !utf8.Valid(b) {
+			return structuredBytes(b), nil
+		}
+		return string(b), nil
+	case gobInterface:
+		return d.decodeInterface(depth)
+	}
+
+	t, ok := d.types[id]
+	if !ok {
+		return nil, fmt.Errorf("type %d isn't defined", id)
+	}
+
+	switch t.kind {
+	case gobKindArray, gobKindSlice:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		if t.kind == gobKindArray && int64(n) != t.len {
+			return nil, fmt.Errorf("array has %d elements instead of %d", n, t.len)
+		}
+		if n > len(d.b) {
+			return nil, errors.New("length exceeds the remaining data")
+		}
+		result := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := d.decodeValue(t.elem, depth+1)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode element %d", i)
+			}
+			result = append(result, v)
+		}
+		return result, nil
+	case gobKindMap:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		if n > len(d.b)/2 {
+			return nil, errors.New("length exceeds the remaining data")
+		}
+		result := make(orderedMap, 0, n)
+		for i := 0; i < n; i++ {
+			k, err := d.decodeValue(t.key, depth+1)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode key %d", i)
+			}
+			key, err := structuredKey(k)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not convert key %d", i)
+			}
+			v, err := d.decodeValue(t.elem, depth+1)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode value %d", i)
+			}
+			result = append(result, orderedMapEntry{Key: key, Value: v})
+		}
+		return result, nil
+	case gobKindStruct:
+		result := make(orderedMap, 0)
+		if err := d.decodeStruct(func(field int) error {
+			if field >= len(t.fields) {
+				return fmt.Errorf("struct '%s' doesn't have field %d", t.name, field)
+			}
+			v, err := d.decodeValue(t.fields[field].id, depth+1)
+			if err != nil {
+				return errors.Wrapf(err, "could not decode field '%s'", t.fields[field].name)
+			}
+			result = append(result, orderedMapEntry{Key: t.fields[field].name, Value: v})
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the encoded value")
+		}
+		if t.kind == gobKindTextMarshaler && utf8.Valid(b) {
+			return string(b), nil
+		}
+		return structuredBytes(b), nil
+	}
+}
+
+// decodeInterface follows the way in which encoding/gob decodes interfaces
+// including the definitions of the types sent before the concrete value.
+func (d *gobDecoder) decodeInterface(depth int) (interface{}, error) {
+	name, err := d.readString()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the name")
+	}
+
+	if name == "" {
+		return nil, nil
+	}
+
+	var id int64
+	for {
+		if len(d.b) == 0 {
+			if err := d.receiveMessage(); err != nil {
+				return nil, errors.Wrap(err, "could not receive a message")
+			}
+		}
+
+		id, err = d.readInt()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the type id")
+		}
+
+		if id >= 0 {
+			break
+		}
+
+		if err := d.decodeTypeDefinition(-id); err != nil {
+			return nil, errors.Wrap(err, "could not decode a type definition")
+		}
+
+		if len(d.b) > 0 {
+			if _, err := d.readUint(); err != nil {
+				return nil, errors.Wrap(err, "could not read the length")
+			}
+		}
+	}
+
+	// the length of the value is only used to skip values of unknown types
+	if _, err := d.readLength(); err != nil {
+		return nil, errors.Wrap(err, "could not read the length")
+	}
+
+	v, err := d.decodeTopLevelValue(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode the concrete value")
+	}
+
+	return orderedMap{
+		{Key: "type", Value: name},
+		{Key: "value", Value: v},
+	}, nil
+}
+
+// decodeStruct calls fn for every field of the struct. Fields are identified
+// using deltas from the previous field and a delta equal to zero ends the
+// struct.
+func (d *gobDecoder) decodeStruct(fn func(field int) error) error {
+	field := -1
+	for {
+		delta, err := d.readUint()
+		if err != nil {
+			return errors.Wrap(err, "could not read the delta")
+		}
+
+		if delta == 0 {
+			return nil
+		}
+
+		if delta > math.MaxInt32 || field+int(delta) > math.MaxInt32 {
+			return errors.New("field number is too large")
+		}
+
+		field += int(delta)
+		if err := fn(field); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *gobDecoder) readIntField(id *int64) error {
+	v, err := d.readInt()
+	if err != nil {
+		return errors.Wrap(err, "could not read the type id")
+	}
+	*id = v
+	return nil
+}
+
+func (d *gobDecoder) readString() (string, error) {
+	b, err := d.readBytes()
+	if err != nil {
+		return "", err
+	}
+
+	if !utf8.Valid(b) {
+		return "", errors.New("string isn't valid utf-8")
+	}
+
+	return string(b), nil
+}
+
+func (d *gobDecoder) readBytes() ([]byte, error) {
+	n, err := d.readLength()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the length")
+	}
+	return d.read(n)
+}
+
+func (d *gobDecoder) readLength() (int, error) {
+	v, err := d.readUint()
+	if err != nil {
+		return 0, err
+	}
+
+	if v > uint64(len(d.b)) {
+		return 0, errors.New("length exceeds the remaining data")
+	}
+
+	return int(v), nil
+}
+
+func (d *gobDecoder) readFloat() (float64, error) {
+	v, err := d.readUint()
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(bits.ReverseBytes64(v)), nil
+}
+
+func (d *gobDecoder) readInt() (int64, error) {
+	v, err := d.readUint()
+	if err != nil {
+		return 0, err
+	}
+
+	if v&1 != 0 {
+		return ^int64(v >> 1), nil
+	}
+	return int64(v >> 1), nil
+}
+
+// readUint reads an unsigned integer which is either stored in a single byte
+// or in up to eight bytes preceded by their negated count.
+func (d *gobDecoder) readUint() (uint64, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+
+	if b[0] <= 0x7f {
+		return uint64(b[0]), nil
+	}
+
+	n := -int(int8(b[0]))
+	if n > 8 {
+		return 0, errors.New("integer is too long")
+	}
+
+	b, err = d.read(n)
+	if err != nil {
+		return 0, err
+	}
+
+	if b[0] == 0 {
+		return 0, errors.New("integer isn't encoded minimally")
+	}
+
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	if v <= 0x7f {
+		return 0, errors.New("integer isn't encoded minimally")
+	}
+
+	return v, nil
+}
+
+func (d *gobDecoder) read(n int) ([]byte, error) {
+	if n > len(d.b) {
+		return nil, errors.New("unexpected end of data")
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
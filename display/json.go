@@ -24,3 +24,7 @@ func (p PrettifierJSON) Prettify(b []byte) (string, error) {
 	}
 	return "", errors.New("invalid json")
 }
+
+func (p PrettifierJSON) decode(path [][]byte, b []byte) (interface{}, error) {
+	return decodeJSON(b)
+}
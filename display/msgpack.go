@@ -0,0 +1,296 @@
+package display
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+)
+
+const msgpackTimestampType = -1
+
+// PrettifierMsgpack displays values encoded using MessagePack as JSON. Only
+// maps and arrays are recognized as otherwise most short values would be
+// recognized as MessagePack integers. The value must consist of exactly one
+// well-formed element, strings must be valid UTF-8 and maps can't contain
+// duplicate keys.
+type PrettifierMsgpack struct {
+}
+
+func NewPrettifierMsgpack() *PrettifierMsgpack {
+	return &PrettifierMsgpack{}
+}
+
+func (p *PrettifierMsgpack) Prettify(b []byte) (string, error) {
+	v, err := p.decode(nil, b)
+	if err != nil {
+		return "", err
+	}
+	return marshalStructured(v)
+}
+
+func (p *PrettifierMsgpack) decode(path [][]byte, b []byte) (interface{}, error) {
+	if len(b) == 0 || !isMsgpackContainer(b[0]) {
+		return nil, errors.New("value isn't a msgpack map or array")
+	}
+
+	d := &msgpackDecoder{b: b}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid msgpack")
+	}
+
+	if len(d.b) > 0 {
+		return nil, errors.New("extraneous data after the msgpack element")
+	}
+
+	return v, nil
+}
+
+func isMsgpackContainer(b byte) bool {
+	return b&0xe0 == 0x80 || b == 0xdc || b == 0xdd || b == 0xde || b == 0xdf
+}
+
+type msgpackDecoder struct {
+	b []byte
+}
+
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxStructuredDepth {
+		return nil, errors.New("maximum depth exceeded")
+	}
+
+	t, err := d.read(1)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the type")
+	}
+
+	switch b := t[0]; {
+	case b <= 0x7f:
+		return uint64(b), nil
+	case b <= 0x8f:
+		return d.decodeMap(int(b&0x0f), depth)
+	case b <= 0x9f:
+		return d.decodeArray(int(b&0x0f), depth)
+	case b <= 0xbf:
+		return d.decodeString(int(b & 0x1f))
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	}
+
+	switch t[0] {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLength(t[0] - 0xc4)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read bin")
+		}
+		return structuredBytes(b), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLength(t[0] - 0xc7)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read float32")
+		}
+		return structuredFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(b))), 32), nil
+	case 0xcb:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read float64")
+		}
+		return structuredFloat(math.Float64frombits(binary.BigEndian.Uint64(b)), 64), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.readUint(1 << (t[0] - 0xcc))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read uint")
+		}
+		return v, nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		v, err := d.readUint(1 << (t[0] - 0xd0))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read int")
+		}
+		bits := 8 << (t[0] - 0xd0)
+		return int64(v<<(64-bits)) >> (64 - bits), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (t[0] - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLength(t[0] - 0xd9)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		return d.decodeString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLength(t[0] - 0xdc + 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readLength(t[0] - 0xde + 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read the length")
+		}
+		return d.decodeMap(n, depth)
+	default:
+		return nil, fmt.Errorf("invalid type 0x%02x", t[0])
+	}
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read str")
+	}
+
+	if !utf8.Valid(b) {
+		return nil, errors.New("str isn't valid utf-8")
+	}
+
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int, depth int) (interface{}, error) {
+	if n > len(d.b) {
+		return nil, errors.New("array is longer than the remaining data")
+	}
+
+	result := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode element %d", i)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int, depth int) (interface{}, error) {
+	if n > len(d.b)/2 {
+		return nil, errors.New("map is longer than the remaining data")
+	}
+
+	result := make(orderedMap, 0, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode key %d", i)
+		}
+
+		key, err := structuredKey(k)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not convert key %d", i)
+		}
+
+		if result.has(key) {
+			return nil, fmt.Errorf("duplicate key %s", key)
+		}
+
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode value %d", i)
+		}
+
+		result = append(result, orderedMapEntry{Key: key, Value: v})
+	}
+	return result, nil
+}
+
+func (d *msgpackDecoder) decodeExt(n int) (interface{}, error) {
+	t, err := d.read(1)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the ext type")
+	}
+
+	b, err := d.read(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read ext data")
+	}
+
+	if int8(t[0]) == msgpackTimestampType {
+		return decodeMsgpackTimestamp(b)
+	}
+
+	return orderedMap{
+		{Key: "type", Value: int8(t[0])},
+		{Key: "data", Value: structuredBytes(b)},
+	}, nil
+}
+
+func decodeMsgpackTimestamp(b []byte) (interface{}, error) {
+	var sec, nsec int64
+
+	switch len(b) {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(b))
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		sec = int64(v & 0x3ffffffff)
+		nsec = int64(v >> 34)
+	case 12:
+		sec = int64(binary.BigEndian.Uint64(b[4:]))
+		nsec = int64(binary.BigEndian.Uint32(b))
+	default:
+		return nil, fmt.Errorf("invalid timestamp length %d", len(b))
+	}
+
+	if nsec >= int64(time.Second) {
+		return nil, errors.New("invalid timestamp nanoseconds")
+	}
+
+	return time.Unix(sec, nsec).UTC().Format(time.RFC3339Nano), nil
+}
+
+// readLength reads a big endian length using 1, 2 or 4 bytes depending on
+// the size which should be equal to 0, 1 or 2.
+func (d *msgpackDecoder) readLength(size byte) (int, error) {
+	v, err := d.readUint(1 << size)
+	if err != nil {
+		return 0, err
+	}
+	if v > math.MaxInt32 {
+		return 0, errors.New("length is too large")
+	}
+	return int(v), nil
+}
+
+func (d *msgpackDecoder) readUint(n int) (uint64, error) {
+	b, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n > len(d.b) {
+		return nil, errors.New("unexpected end of data")
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
@@ -1,6 +1,12 @@
 package display
 
 import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/boreq/errors"
 )
 
@@ -8,56 +14,455 @@ type ContentType struct {
 	s string
 }
 
+// NewContentType creates a content type with the provided name. Content types
+// with equal names are equal.
+func NewContentType(name string) (ContentType, error) {
+	if name == "" {
+		return ContentType{}, errors.New("name is empty")
+	}
+	return ContentType{name}, nil
+}
+
+func (t ContentType) IsZero() bool {
+	return t == ContentType{}
+}
+
+func (t ContentType) String() string {
+	return t.s
+}
+
 var (
 	ContentTypeJSON   ContentType = ContentType{"json"}
 	ContentTypeCBOR   ContentType = ContentType{"cbor"}
 	ContentTypeString ContentType = ContentType{"string"}
+
+	ContentTypeBSON    ContentType = ContentType{"bson"}
+	ContentTypeMsgpack ContentType = ContentType{"msgpack"}
+	ContentTypeGob     ContentType = ContentType{"gob"}
+
+	ContentTypeProtobuf     ContentType = ContentType{"protobuf"}
+	ContentTypeProtobufWire ContentType = ContentType{"protobuf-wire"}
+)
+
+// Priorities of the built-in prettifiers. Prettifiers with higher priorities
+// are tried first.
+const (
+	PriorityProtobuf     = 400
+	PriorityCBOR         = 300
+	PriorityBSON         = 275
+	PriorityMsgpack      = 250
+	PriorityJSON         = 200
+	PriorityGob          = 150
+	PriorityString       = 100
+	PriorityProtobufWire = 50
 )
 
 type Prettifier interface {
 	Prettify(b []byte) (string, error)
 }
 
+// BucketPrettifier is implemented by prettifiers which decode values
+// depending on the bucket in which they are stored. The path contains the
+// names of the buckets starting at the root of the database.
+type BucketPrettifier interface {
+	Prettifier
+	PrettifyInBucket(path [][]byte, b []byte) (string, error)
+}
+
 type Prettified struct {
 	Type  ContentType
 	Value string
 }
 
+// Decoded is a value decoded by Pretty. Values which are displayed as JSON are
+// decoded into maps, arrays and other values which can be marshalled to JSON.
+// Other values, such as strings, are the same as the displayed values.
+type Decoded struct {
+	Type  ContentType
+	Value interface{}
+}
+
+// valueDecoder is implemented by the built-in prettifiers which display
+// values as JSON so that the decoded values can be returned without parsing
+// the displayed values. It must accept the same values as Prettify.
+type valueDecoder interface {
+	decode(path [][]byte, b []byte) (interface{}, error)
+}
+
+// showFn displays or decodes a value using the prettifier.
+type showFn func(prettifier Prettifier, path [][]byte, b []byte) (interface{}, error)
+
 type prettifier struct {
 	Prettifier  Prettifier
 	ContentType ContentType
+	Priority    int
+}
+
+var builtInContentTypes = []ContentType{
+	ContentTypeProtobuf,
+	ContentTypeCBOR,
+	ContentTypeBSON,
+	ContentTypeMsgpack,
+	ContentTypeJSON,
+	ContentTypeGob,
+	ContentTypeString,
+	ContentTypeProtobufWire,
+	ContentTypeGzip,
+	ContentTypeZstd,
+	ContentTypeSnappy,
+}
+
+var (
+	registeredMutex sync.Mutex
+	registered      []prettifier
+	protobufTypes   *ProtobufTypes
+	rules           *Rules
+)
+
+// Register adds a prettifier to all instances of Pretty created afterwards
+// using NewPretty. The name is used as the content type of the prettified
+// values and must be unique. Register should be called before the program
+// starts serving requests, for example in an init function.
+func Register(name string, priority int, p Prettifier) error {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+
+	prettifiers, err := addPrettifier(registered, name, priority, p)
+	if err != nil {
+		return errors.Wrap(err, "could not add the prettifier")
+	}
+
+	registered = prettifiers
+	return nil
+}
+
+// RegisterProtobufTypes sets the message types used by the protobuf
+// prettifier of all instances of Pretty created afterwards using NewPretty.
+// Similarly to Register it should be called before the program starts serving
+// requests.
+func RegisterProtobufTypes(types *ProtobufTypes) {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+
+	protobufTypes = types
+}
+
+// RegisterRules sets the rules used by all instances of Pretty created
+// afterwards using NewPretty. The content types used by the rules must be
+// known so RegisterRules should be called after registering the prettifiers.
+func RegisterRules(r *Rules) error {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+
+	if r != nil {
+		for _, rule := range r.rules {
+			if err := validateRuleValue(registered, rule.value); err != nil {
+				return errors.Wrapf(err, "invalid value of the rule for bucket '%s'", strings.Join(rule.bucket, "/"))
+			}
+		}
+	}
+
+	rules = r
+	return nil
+}
+
+func validateRuleValue(prettifiers []prettifier, value string) error {
+	if value == "" || value == RuleValueRaw {
+		return nil
+	}
+
+	names := strings.Split(value, "+")
+	for _, name := range names[:len(names)-1] {
+		if _, ok := findDecompressor(ContentType{name}); !ok {
+			return fmt.Errorf("unknown compression '%s'", name)
+		}
+	}
+
+	contentType := ContentType{names[len(names)-1]}
+	for _, builtIn := range builtInContentTypes {
+		if contentType == builtIn {
+			if _, ok := findDecompressor(contentType); ok {
+				return fmt.Errorf("'%s' is a compression", contentType)
+			}
+			return nil
+		}
+	}
+
+	for _, prettifier := range prettifiers {
+		if prettifier.ContentType == contentType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown content type '%s'", contentType)
 }
 
 type Pretty struct {
 	prettifiers []prettifier
+	rules       *Rules
 }
 
+// NewPretty creates a Pretty using the built-in prettifiers and the
+// prettifiers added using Register.
 func NewPretty() *Pretty {
-	return &Pretty{prettifiers: []prettifier{
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+
+	prettifiers := []prettifier{
+		{
+			Prettifier:  NewPrettifierProtobuf(protobufTypes),
+			ContentType: ContentTypeProtobuf,
+			Priority:    PriorityProtobuf,
+		},
 		{
 			Prettifier:  NewPrettifierCBOR(),
 			ContentType: ContentTypeCBOR,
+			Priority:    PriorityCBOR,
+		},
+		{
+			Prettifier:  NewPrettifierBSON(),
+			ContentType: ContentTypeBSON,
+			Priority:    PriorityBSON,
+		},
+		{
+			Prettifier:  NewPrettifierMsgpack(),
+			ContentType: ContentTypeMsgpack,
+			Priority:    PriorityMsgpack,
 		},
 		{
 			Prettifier:  NewPrettifierJSON(),
 			ContentType: ContentTypeJSON,
+			Priority:    PriorityJSON,
+		},
+		{
+			Prettifier:  NewPrettifierGob(),
+			ContentType: ContentTypeGob,
+			Priority:    PriorityGob,
 		},
 		{
 			Prettifier:  NewPrettifierString(),
 			ContentType: ContentTypeString,
+			Priority:    PriorityString,
 		},
-	}}
+		{
+			Prettifier:  NewPrettifierProtobufWire(),
+			ContentType: ContentTypeProtobufWire,
+			Priority:    PriorityProtobufWire,
+		},
+	}
+	prettifiers = append(prettifiers, registered...)
+	sortPrettifiers(prettifiers)
+
+	return &Pretty{
+		prettifiers: prettifiers,
+		rules:       rules,
+	}
+}
+
+// Register adds a prettifier to this instance of Pretty. See the package level
+// Register function. It must not be called concurrently with Print.
+func (p *Pretty) Register(name string, priority int, prettifier Prettifier) error {
+	prettifiers, err := addPrettifier(p.prettifiers, name, priority, prettifier)
+	if err != nil {
+		return errors.Wrap(err, "could not add the prettifier")
+	}
+
+	p.prettifiers = prettifiers
+	return nil
 }
 
+// Print tries the prettifiers in order of their priorities and returns the
+// result of the first one which completed successfully. Prettifiers with equal
+// priorities are tried in the order in which they were registered.
 func (p *Pretty) Print(b []byte) (Prettified, error) {
+	return p.PrintInBucket(nil, b)
+}
+
+// PrintInBucket works like Print but also uses the prettifiers which depend
+// on the bucket in which the value is stored.
+//
+// Values compressed using gzip, zstd or snappy are decompressed first. In that
+// case the content type contains the compression and the content type of the
+// decompressed value, for example "gzip+json".
+//
+// If a rule specifies the content type of the values stored in the bucket
+// then that content type is tried before detecting the content type.
+func (p *Pretty) PrintInBucket(path [][]byte, b []byte) (Prettified, error) {
+	contentType, v, err := p.show(path, b, printValue)
+	if err != nil {
+		return Prettified{}, err
+	}
+
+	return Prettified{
+		Type:  contentType,
+		Value: v.(string),
+	}, nil
+}
+
+// DecodeInBucket works like PrintInBucket but returns the decoded value
+// instead of displaying it. The content type is the same as the one returned
+// by PrintInBucket.
+func (p *Pretty) DecodeInBucket(path [][]byte, b []byte) (Decoded, error) {
+	contentType, v, err := p.show(path, b, decodeValue)
+	if err != nil {
+		return Decoded{}, err
+	}
+
+	return Decoded{
+		Type:  contentType,
+		Value: v,
+	}, nil
+}
+
+func (p *Pretty) show(path [][]byte, b []byte, fn showFn) (ContentType, interface{}, error) {
+	if value, ok := p.rules.value(path); ok {
+		if value == RuleValueRaw {
+			return ContentType{}, nil, errors.New("values stored in this bucket are displayed as raw bytes")
+		}
+
+		contentType, v, err := p.showAs(value, path, b, fn)
+		if err == nil {
+			return contentType, v, nil
+		}
+	}
+
+	if compression, decompressed, ok := decompress(b); ok {
+		contentType, v, err := p.detect(path, decompressed, fn)
+		if err == nil {
+			return compressedContentType(compression, contentType), v, nil
+		}
+	}
+	return p.detect(path, b, fn)
+}
+
+func (p *Pretty) detect(path [][]byte, b []byte, fn showFn) (ContentType, interface{}, error) {
 	for _, prettifier := range p.prettifiers {
-		v, err := prettifier.Prettifier.Prettify(b)
+		v, err := fn(prettifier.Prettifier, path, b)
 		if err == nil {
-			return Prettified{
-				Type:  prettifier.ContentType,
-				Value: v,
-			}, nil
+			return prettifier.ContentType, v, nil
 		}
 	}
-	return Prettified{}, errors.New("no prettifiers completed successfully")
+	return ContentType{}, nil, errors.New("no prettifiers completed successfully")
+}
+
+// showAs shows the value using the prettifier with the specified content type
+// after removing the compression layers listed in front of it.
+func (p *Pretty) showAs(value string, path [][]byte, b []byte, fn showFn) (ContentType, interface{}, error) {
+	if i := strings.Index(value, "+"); i >= 0 {
+		compression := ContentType{value[:i]}
+
+		decompressor, ok := findDecompressor(compression)
+		if !ok {
+			return ContentType{}, nil, fmt.Errorf("unknown compression '%s'", compression)
+		}
+
+		decompressed, err := decompressor.decompress(b)
+		if err != nil {
+			return ContentType{}, nil, errors.Wrap(err, "could not decompress")
+		}
+
+		contentType, v, err := p.showAs(value[i+1:], path, decompressed, fn)
+		if err != nil {
+			return ContentType{}, nil, err
+		}
+
+		return compressedContentType(compression, contentType), v, nil
+	}
+
+	contentType := ContentType{value}
+	for _, prettifier := range p.prettifiers {
+		if prettifier.ContentType == contentType {
+			v, err := fn(prettifier.Prettifier, path, b)
+			if err != nil {
+				return ContentType{}, nil, errors.Wrap(err, "prettifier failed")
+			}
+			return prettifier.ContentType, v, nil
+		}
+	}
+
+	return ContentType{}, nil, fmt.Errorf("unknown content type '%s'", contentType)
+}
+
+// PrintKeyInBucket displays a key stored in a bucket using the key codec
+// specified by the rules. If the rules don't specify the codec or the key
+// can't be decoded using it then keys which can be displayed as strings are
+// displayed using KeyCodecString and other keys using KeyCodecHex.
+func (p *Pretty) PrintKeyInBucket(path [][]byte, key []byte) PrettifiedKey {
+	if codec, ok := p.rules.keyCodec(path); ok {
+		prettified, err := printKey(codec, key)
+		if err == nil {
+			return prettified
+		}
+	}
+
+	if CanDisplayAsString(key) {
+		return PrettifiedKey{Codec: KeyCodecString, Value: string(key)}
+	}
+	return PrettifiedKey{Codec: KeyCodecHex, Value: hex.EncodeToString(key)}
+}
+
+func printValue(prettifier Prettifier, path [][]byte, b []byte) (interface{}, error) {
+	return prettify(prettifier, path, b)
+}
+
+// decodeValue returns the displayed value if the prettifier can't decode
+// values.
+func decodeValue(prettifier Prettifier, path [][]byte, b []byte) (interface{}, error) {
+	if decoder, ok := prettifier.(valueDecoder); ok {
+		return decoder.decode(path, b)
+	}
+	return prettify(prettifier, path, b)
+}
+
+func prettify(prettifier Prettifier, path [][]byte, b []byte) (string, error) {
+	if bucketPrettifier, ok := prettifier.(BucketPrettifier); ok && path != nil {
+		return bucketPrettifier.PrettifyInBucket(path, b)
+	}
+	return prettifier.Prettify(b)
+}
+
+// addPrettifier returns a new slice so that the slices which were already
+// handed out aren't modified.
+func addPrettifier(prettifiers []prettifier, name string, priority int, p Prettifier) ([]prettifier, error) {
+	if p == nil {
+		return nil, errors.New("prettifier is nil")
+	}
+
+	contentType, err := NewContentType(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid content type")
+	}
+
+	if strings.Contains(name, "+") {
+		return nil, errors.New("name can't contain '+' as it is used to report compressed values")
+	}
+
+	for _, builtIn := range builtInContentTypes {
+		if contentType == builtIn {
+			return nil, fmt.Errorf("prettifier '%s' is already registered", name)
+		}
+	}
+
+	for _, existing := range prettifiers {
+		if existing.ContentType == contentType {
+			return nil, fmt.Errorf("prettifier '%s' is already registered", name)
+		}
+	}
+
+	result := make([]prettifier, len(prettifiers), len(prettifiers)+1)
+	copy(result, prettifiers)
+	result = append(result, prettifier{
+		Prettifier:  p,
+		ContentType: contentType,
+		Priority:    priority,
+	})
+	sortPrettifiers(result)
+
+	return result, nil
+}
+
+func sortPrettifiers(prettifiers []prettifier) {
+	sort.SliceStable(prettifiers, func(i, j int) bool {
+		return prettifiers[i].Priority > prettifiers[j].Priority
+	})
 }
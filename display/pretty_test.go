@@ -1,10 +1,19 @@
 package display_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/boreq/bolt-ui/display"
 	"github.com/fxamacker/cbor/v2"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,6 +27,14 @@ func TestPretty(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	gobBuf := &bytes.Buffer{}
+	err = gob.NewEncoder(gobBuf).Encode(gobValue{
+		Name:   "alice",
+		Tags:   []string{"a"},
+		Scores: map[string]float64{"x": 1.5},
+	})
+	require.NoError(t, err)
+
 	testCases := []struct {
 		Name   string
 		Bytes  []byte
@@ -49,14 +66,427 @@ func TestPretty(t *testing.T) {
 				Value: "some_string",
 			},
 		},
+		{
+			Name:  "msgpack",
+			Bytes: msgpackValue(),
+			Result: display.Prettified{
+				Type: display.ContentTypeMsgpack,
+				Value: `{
+  "name": "alice",
+  "tags": [
+    "a",
+    -1
+  ],
+  "ok": true,
+  "bin": "0x0102",
+  "at": "1970-01-01T00:00:01Z"
+}`,
+			},
+		},
+		{
+			Name: "bson",
+			Bytes: bsonDocument(
+				bsonElement(0x02, "name", bsonString("alice")),
+				bsonElement(0x10, "n", []byte{0x2a, 0x00, 0x00, 0x00}),
+				bsonElement(0x04, "tags", bsonDocument(
+					bsonElement(0x02, "0", bsonString("a")),
+				)),
+				bsonElement(0x07, "_id", bytes.Repeat([]byte{0xab}, 12)),
+				bsonElement(0x0a, "none", nil),
+			),
+			Result: display.Prettified{
+				Type: display.ContentTypeBSON,
+				Value: `{
+  "name": "alice",
+  "n": 42,
+  "tags": [
+    "a"
+  ],
+  "_id": {
+    "$oid": "abababababababababababab"
+  },
+  "none": null
+}`,
+			},
+		},
+		{
+			Name:  "gob",
+			Bytes: gobBuf.Bytes(),
+			Result: display.Prettified{
+				Type: display.ContentTypeGob,
+				Value: `{
+  "Name": "alice",
+  "Tags": [
+    "a"
+  ],
+  "Scores": {
+    "x": 1.5
+  }
+}`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			p := display.NewPretty()
+			result, err := p.Print(testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Result, result)
+		})
+	}
+}
+
+func TestPrettyDecode(t *testing.T) {
+	cbor, err := cbor.Marshal(map[string]int{"a": 1})
+	require.NoError(t, err)
+
+	gobBuf := &bytes.Buffer{}
+	err = gob.NewEncoder(gobBuf).Encode(gobValue{
+		Name:   "alice",
+		Tags:   []string{"a"},
+		Scores: map[string]float64{"x": 1.5},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name  string
+		Bytes []byte
+		Type  display.ContentType
+		Value string
+	}{
+		{
+			Name:  "json",
+			Bytes: []byte(`{"some":"json","n":1.0}`),
+			Type:  display.ContentTypeJSON,
+			Value: `{"n":1.0,"some":"json"}`,
+		},
+		{
+			Name:  "cbor",
+			Bytes: cbor,
+			Type:  display.ContentTypeCBOR,
+			Value: `{"a":1}`,
+		},
+		{
+			Name:  "string",
+			Bytes: []byte("some_string"),
+			Type:  display.ContentTypeString,
+			Value: `"some_string"`,
+		},
+		{
+			Name:  "msgpack",
+			Bytes: msgpackValue(),
+			Type:  display.ContentTypeMsgpack,
+			Value: `{"name":"alice","tags":["a",-1],"ok":true,"bin":"0x0102","at":"1970-01-01T00:00:01Z"}`,
+		},
+		{
+			Name: "bson",
+			Bytes: bsonDocument(
+				bsonElement(0x02, "name", bsonString("alice")),
+				bsonElement(0x07, "_id", bytes.Repeat([]byte{0xab}, 12)),
+			),
+			Type:  display.ContentTypeBSON,
+			Value: `{"name":"alice","_id":{"$oid":"abababababababababababab"}}`,
+		},
+		{
+			Name:  "gob",
+			Bytes: gobBuf.Bytes(),
+			Type:  display.ContentTypeGob,
+			Value: `{"Name":"alice","Tags":["a"],"Scores":{"x":1.5}}`,
+		},
+		{
+			Name:  "protobuf_wire",
+			Bytes: []byte{0x08, 0x01},
+			Type:  display.ContentTypeProtobufWire,
+			Value: `"1 (varint): 1\n"`,
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.Name, func(t *testing.T) {
 			p := display.NewPretty()
+
+			decoded, err := p.DecodeInBucket(nil, testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Type, decoded.Type)
+
+			j, err := json.Marshal(decoded.Value)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Value, string(j))
+
+			prettified, err := p.Print(testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, prettified.Type, decoded.Type)
+		})
+	}
+}
+
+func TestPrettifiersRejectMalformedValues(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Prettifier display.Prettifier
+		Bytes      []byte
+	}{
+		{"msgpack_empty", display.NewPrettifierMsgpack(), nil},
+		{"msgpack_not_a_container", display.NewPrettifierMsgpack(), []byte{0x2a}},
+		{"msgpack_string", display.NewPrettifierMsgpack(), []byte{0xa1, 'a'}},
+		{"msgpack_truncated", display.NewPrettifierMsgpack(), []byte{0x92, 0x01}},
+		{"msgpack_extraneous_data", display.NewPrettifierMsgpack(), []byte{0x91, 0x01, 0x02}},
+		{"msgpack_never_used", display.NewPrettifierMsgpack(), []byte{0x91, 0xc1}},
+		{"msgpack_invalid_utf8", display.NewPrettifierMsgpack(), []byte{0x91, 0xa1, 0xff}},
+		{"msgpack_duplicate_key", display.NewPrettifierMsgpack(), []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'a', 0x02}},
+		{"msgpack_huge_array", display.NewPrettifierMsgpack(), []byte{0xdd, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"msgpack_json", display.NewPrettifierMsgpack(), []byte(`{"some":"json"}`)},
+		{"bson_empty", display.NewPrettifierBSON(), nil},
+		{"bson_invalid_length", display.NewPrettifierBSON(), []byte{0x06, 0x00, 0x00, 0x00, 0x00}},
+		{"bson_not_terminated", display.NewPrettifierBSON(), []byte{0x05, 0x00, 0x00, 0x00, 0x01}},
+		{"bson_invalid_bool", display.NewPrettifierBSON(), bsonDocument(bsonElement(0x08, "a", []byte{0x02}))},
+		{"bson_invalid_array_key", display.NewPrettifierBSON(), bsonDocument(bsonElement(0x04, "a", bsonDocument(bsonElement(0x0a, "1", nil))))},
+		{"bson_deprecated_type", display.NewPrettifierBSON(), bsonDocument(bsonElement(0x06, "a", nil))},
+		{"bson_unterminated_string", display.NewPrettifierBSON(), bsonDocument(bsonElement(0x02, "a", []byte{0x01, 0x00, 0x00, 0x00, 'a'}))},
+		{"bson_duplicate_key", display.NewPrettifierBSON(), bsonDocument(bsonElement(0x0a, "a", nil), bsonElement(0x0a, "a", nil))},
+		{"gob_empty", display.NewPrettifierGob(), nil},
+		{"gob_random", display.NewPrettifierGob(), []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"gob_undefined_type", display.NewPrettifierGob(), []byte{0x03, 0xff, 0x82, 0x00}},
+		{"gob_extraneous_data", display.NewPrettifierGob(), []byte{0x03, 0x04, 0x00, 0x02, 0x00}},
+		{"gob_non_minimal_integer", display.NewPrettifierGob(), []byte{0x04, 0x04, 0x00, 0xff, 0x02}},
+		{"gob_type_definition_only", display.NewPrettifierGob(), []byte{0x03, 0x7f, 0x01, 0x00}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := testCase.Prettifier.Prettify(testCase.Bytes)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPrettifierBSONTypes(t *testing.T) {
+	decimal := make([]byte, 16)
+	decimal[0] = 0x7b
+	decimal[14] = 0x3c
+	decimal[15] = 0x30
+
+	result, err := display.NewPrettifierBSON().Prettify(bsonDocument(
+		bsonElement(0x09, "date", []byte{0xe8, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}),
+		bsonElement(0x05, "binary", append([]byte{0x02, 0x00, 0x00, 0x00, 0x00}, 0x01, 0x02)),
+		bsonElement(0x13, "decimal", decimal),
+		bsonElement(0x12, "int64", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}),
+		bsonElement(0x08, "bool", []byte{0x01}),
+		bsonElement(0x03, "empty", bsonDocument()),
+	))
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "date": {
+    "$date": "1970-01-01T00:00:01Z"
+  },
+  "binary": {
+    "$binary": {
+      "base64": "AQI=",
+      "subType": "00"
+    }
+  },
+  "decimal": {
+    "$numberDecimal": "1.23"
+  },
+  "int64": -1,
+  "bool": true,
+  "empty": {}
+}`, result)
+}
+
+func TestPrettyDecompresses(t *testing.T) {
+	value := []byte(`{"some":"json"}`)
+
+	gzipBuf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(gzipBuf)
+	_, err := gzipWriter.Write(value)
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	zstdEncoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer zstdEncoder.Close()
+
+	snappyBuf := &bytes.Buffer{}
+	snappyWriter := snappy.NewBufferedWriter(snappyBuf)
+	_, err = snappyWriter.Write(value)
+	require.NoError(t, err)
+	require.NoError(t, snappyWriter.Close())
+
+	testCases := []struct {
+		Name  string
+		Bytes []byte
+		Type  string
+	}{
+		{
+			Name:  "gzip",
+			Bytes: gzipBuf.Bytes(),
+			Type:  "gzip+json",
+		},
+		{
+			Name:  "zstd",
+			Bytes: zstdEncoder.EncodeAll(value, nil),
+			Type:  "zstd+json",
+		},
+		{
+			Name:  "snappy",
+			Bytes: snappyBuf.Bytes(),
+			Type:  "snappy+json",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			result, err := display.NewPretty().Print(testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Type, result.Type.String())
+			require.Equal(t, "{\n  \"some\": \"json\"\n}", result.Value)
+
+			decoded, err := display.NewPretty().DecodeInBucket(nil, testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Type, decoded.Type.String())
+			require.Equal(t, map[string]interface{}{"some": "json"}, decoded.Value)
+		})
+	}
+}
+
+func TestPrettyDecompressesWithLimit(t *testing.T) {
+	zstdEncoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer zstdEncoder.Close()
+
+	value := zstdEncoder.EncodeAll(bytes.Repeat([]byte("a"), display.MaxDecompressedSize+1), nil)
+
+	_, err = display.NewPretty().Print(value)
+	require.Error(t, err, "value should be prettified as if it wasn't compressed")
+}
+
+func TestPrettyIgnoresInvalidCompressedValues(t *testing.T) {
+	_, err := display.NewPretty().Print([]byte{0x1f, 0x8b, 0x00})
+	require.Error(t, err, "value should be prettified as if it wasn't compressed")
+}
+
+func TestPrettyRegister(t *testing.T) {
+	p := display.NewPretty()
+
+	err := p.Register("upper", display.PriorityJSON+1, prettifierFunc(func(b []byte) (string, error) {
+		if !bytes.HasPrefix(b, []byte("{")) {
+			return "", errors.New("not upper")
+		}
+		return strings.ToUpper(string(b)), nil
+	}))
+	require.NoError(t, err)
+
+	err = p.Register("fallback", display.PriorityString-1, prettifierFunc(func(b []byte) (string, error) {
+		return "binary", nil
+	}))
+	require.NoError(t, err)
+
+	upper, err := display.NewContentType("upper")
+	require.NoError(t, err)
+
+	fallback, err := display.NewContentType("fallback")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name   string
+		Bytes  []byte
+		Result display.Prettified
+	}{
+		{
+			Name:  "higher_priority_than_json",
+			Bytes: []byte(`{"some":"json"}`),
+			Result: display.Prettified{
+				Type:  upper,
+				Value: `{"SOME":"JSON"}`,
+			},
+		},
+		{
+			Name:  "lower_priority_than_string",
+			Bytes: []byte("some_string"),
+			Result: display.Prettified{
+				Type:  display.ContentTypeString,
+				Value: "some_string",
+			},
+		},
+		{
+			Name:  "fallback",
+			Bytes: []byte{0xff, 0x00},
+			Result: display.Prettified{
+				Type:  fallback,
+				Value: "binary",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
 			result, err := p.Print(testCase.Bytes)
 			require.NoError(t, err)
 			require.Equal(t, testCase.Result, result)
 		})
 	}
+
+	_, err = display.NewPretty().Print([]byte{0xff, 0x00})
+	require.Error(t, err, "registering with an instance shouldn't affect other instances")
+}
+
+func TestPrettyRegisterValidatesNames(t *testing.T) {
+	p := display.NewPretty()
+	noop := prettifierFunc(func(b []byte) (string, error) {
+		return "", nil
+	})
+
+	require.Error(t, p.Register("", 0, noop))
+	require.Error(t, p.Register("json", 0, noop))
+	require.Error(t, p.Register("gzip", 0, noop))
+	require.Error(t, p.Register("gzip+custom", 0, noop))
+	require.Error(t, p.Register("custom", 0, nil))
+	require.NoError(t, p.Register("custom", 0, noop))
+	require.Error(t, p.Register("custom", 0, noop))
+}
+
+type gobValue struct {
+	Name   string
+	Age    int
+	Tags   []string
+	Scores map[string]float64
+}
+
+// msgpackValue returns {"name": "alice", "tags": ["a", -1], "ok": true, "bin":
+// 0x0102, "at": timestamp(1)}.
+func msgpackValue() []byte {
+	b := []byte{0x85}
+	b = append(b, 0xa4, 'n', 'a', 'm', 'e', 0xa5, 'a', 'l', 'i', 'c', 'e')
+	b = append(b, 0xa4, 't', 'a', 'g', 's', 0x92, 0xa1, 'a', 0xff)
+	b = append(b, 0xa2, 'o', 'k', 0xc3)
+	b = append(b, 0xa3, 'b', 'i', 'n', 0xc4, 0x02, 0x01, 0x02)
+	b = append(b, 0xa2, 'a', 't', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x01)
+	return b
+}
+
+func bsonDocument(elements ...[]byte) []byte {
+	body := bytes.Join(elements, nil)
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+5))
+	b = append(b, body...)
+	return append(b, 0x00)
+}
+
+func bsonElement(t byte, key string, value []byte) []byte {
+	b := append([]byte{t}, key...)
+	b = append(b, 0x00)
+	return append(b, value...)
+}
+
+func bsonString(s string) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(s)+1))
+	b = append(b, s...)
+	return append(b, 0x00)
+}
+
+type prettifierFunc func(b []byte) (string, error)
+
+func (f prettifierFunc) Prettify(b []byte) (string, error) {
+	return f(b)
 }
@@ -0,0 +1,278 @@
+package display
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxProtobufWireDepth limits how deeply nested messages are decoded by
+// PrettifierProtobufWire.
+const maxProtobufWireDepth = 32
+
+// ProtobufTypes maps bucket paths to the protobuf message types of the values
+// stored in those buckets.
+type ProtobufTypes struct {
+	types    *dynamicpb.Types
+	messages []protobufMessage
+}
+
+type protobufMessage struct {
+	bucket     []string
+	descriptor protoreflect.MessageDescriptor
+}
+
+// NewProtobufTypes loads the message types from a file containing a
+// FileDescriptorSet, for example created using protoc with the
+// --descriptor_set_out and --include_imports flags. The keys of the messages
+// map are paths to the buckets with the bucket names separated using slashes
+// and the values are fully qualified names of the messages stored in them.
+// Bucket names equal to RuleWildcard match any bucket. If multiple paths
+// match a bucket then the paths containing fewer wildcards are preferred.
+func NewProtobufTypes(descriptorSetFile string, messages map[string]string) (*ProtobufTypes, error) {
+	b, err := os.ReadFile(descriptorSetFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the descriptor set file")
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal the descriptor set")
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the file registry")
+	}
+
+	t := &ProtobufTypes{
+		types: dynamicpb.NewTypes(files),
+	}
+
+	for bucket, name := range messages {
+		descriptor, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find message '%s'", name)
+		}
+
+		messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a message", name)
+		}
+
+		t.messages = append(t.messages, protobufMessage{
+			bucket:     strings.Split(strings.Trim(bucket, "/"), "/"),
+			descriptor: messageDescriptor,
+		})
+	}
+
+	sort.Slice(t.messages, func(i, j int) bool {
+		a, b := t.messages[i].bucket, t.messages[j].bucket
+		if wildcards(a) != wildcards(b) {
+			return wildcards(a) < wildcards(b)
+		}
+		return strings.Join(a, "/") < strings.Join(b, "/")
+	})
+
+	return t, nil
+}
+
+func (t *ProtobufTypes) message(path [][]byte) (protoreflect.MessageDescriptor, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	for _, message := range t.messages {
+		if matchBucket(message.bucket, path) {
+			return message.descriptor, true
+		}
+	}
+	return nil, false
+}
+
+func wildcards(bucket []string) int {
+	var n int
+	for _, name := range bucket {
+		if name == RuleWildcard {
+			n++
+		}
+	}
+	return n
+}
+
+// PrettifierProtobuf decodes values stored in buckets for which a message
+// type was configured and displays them as protojson.
+type PrettifierProtobuf struct {
+	types *ProtobufTypes
+}
+
+// NewPrettifierProtobuf creates a prettifier using the provided types. Types
+// can be nil in which case no values are recognized.
+func NewPrettifierProtobuf(types *ProtobufTypes) *PrettifierProtobuf {
+	return &PrettifierProtobuf{
+		types: types,
+	}
+}
+
+func (p *PrettifierProtobuf) Prettify(b []byte) (string, error) {
+	return p.PrettifyInBucket(nil, b)
+}
+
+// PrettifyInBucket returns an error if a message type wasn't configured for
+// the bucket or if the value contains fields which aren't a part of that
+// message type.
+func (p *PrettifierProtobuf) PrettifyInBucket(path [][]byte, b []byte) (string, error) {
+	j, err := p.toJSON(path, b)
+	if err != nil {
+		return "", err
+	}
+
+	// protojson randomly inserts whitespace so it has to be normalized
+	buf := &bytes.Buffer{}
+	if err := json.Indent(buf, j, "", "  "); err != nil {
+		return "", errors.Wrap(err, "error indenting")
+	}
+	return buf.String(), nil
+}
+
+// decode returns the value using the JSON mapping of protobuf messages which
+// is only available through protojson.
+func (p *PrettifierProtobuf) decode(path [][]byte, b []byte) (interface{}, error) {
+	j, err := p.toJSON(path, b)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(j)
+}
+
+func (p *PrettifierProtobuf) toJSON(path [][]byte, b []byte) ([]byte, error) {
+	descriptor, ok := p.types.message(path)
+	if !ok {
+		return nil, errors.New("message type of this bucket is unknown")
+	}
+
+	message := dynamicpb.NewMessage(descriptor)
+
+	if err := (proto.UnmarshalOptions{Resolver: p.types.types}).Unmarshal(b, message); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal")
+	}
+
+	if len(message.GetUnknown()) > 0 {
+		return nil, errors.New("message contains unknown fields")
+	}
+
+	j, err := (protojson.MarshalOptions{Resolver: p.types.types}).Marshal(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal to json")
+	}
+	return j, nil
+}
+
+// PrettifierProtobufWire displays values encoded using the protobuf wire
+// format without knowing their schema. Each field is displayed using its
+// number and wire type. Length-delimited fields are displayed as strings,
+// nested messages or hex encoded bytes. Groups and invalid field numbers are
+// rejected so that most arbitrary binary values aren't recognized.
+type PrettifierProtobufWire struct {
+}
+
+func NewPrettifierProtobufWire() *PrettifierProtobufWire {
+	return &PrettifierProtobufWire{}
+}
+
+func (p *PrettifierProtobufWire) Prettify(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", errors.New("value is empty")
+	}
+
+	builder := &strings.Builder{}
+	if err := writeProtobufWire(builder, b, 0); err != nil {
+		return "", errors.Wrap(err, "invalid wire format")
+	}
+	return builder.String(), nil
+}
+
+func writeProtobufWire(builder *strings.Builder, b []byte, depth int) error {
+	indent := strings.Repeat("  ", depth)
+
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errors.Wrap(protowire.ParseError(n), "could not consume the tag")
+		}
+		b = b[n:]
+
+		if !num.IsValid() || (num >= protowire.FirstReservedNumber && num <= protowire.LastReservedNumber) {
+			return fmt.Errorf("invalid field number %d", num)
+		}
+
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return errors.Wrap(protowire.ParseError(n), "could not consume a varint")
+			}
+			b = b[n:]
+			fmt.Fprintf(builder, "%s%d (varint): %d\n", indent, num, v)
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(b)
+			if n < 0 {
+				return errors.Wrap(protowire.ParseError(n), "could not consume a fixed32")
+			}
+			b = b[n:]
+			fmt.Fprintf(builder, "%s%d (i32): 0x%08x\n", indent, num, v)
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return errors.Wrap(protowire.ParseError(n), "could not consume a fixed64")
+			}
+			b = b[n:]
+			fmt.Fprintf(builder, "%s%d (i64): 0x%016x\n", indent, num, v)
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return errors.Wrap(protowire.ParseError(n), "could not consume bytes")
+			}
+			b = b[n:]
+			writeProtobufWireBytes(builder, num, v, depth)
+		default:
+			return fmt.Errorf("unsupported wire type %d", typ)
+		}
+	}
+
+	return nil
+}
+
+func writeProtobufWireBytes(builder *strings.Builder, num protowire.Number, b []byte, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	if utf8.Valid(b) && CanDisplayAsString(b) {
+		fmt.Fprintf(builder, "%s%d (len): %s\n", indent, num, strconv.Quote(string(b)))
+		return
+	}
+
+	if len(b) > 0 && depth < maxProtobufWireDepth {
+		nested := &strings.Builder{}
+		if err := writeProtobufWire(nested, b, depth+1); err == nil {
+			fmt.Fprintf(builder, "%s%d (len): {\n%s%s}\n", indent, num, nested.String(), indent)
+			return
+		}
+	}
+
+	fmt.Fprintf(builder, "%s%d (len): 0x%s\n", indent, num, hex.EncodeToString(b))
+}
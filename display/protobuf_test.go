@@ -0,0 +1,220 @@
+package display_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boreq/bolt-ui/display"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestPrettifierProtobuf(t *testing.T) {
+	types, err := display.NewProtobufTypes(writeDescriptorSet(t), map[string]string{
+		"people/nested": "test.Person",
+	})
+	require.NoError(t, err)
+
+	p := display.NewPrettifierProtobuf(types)
+	path := [][]byte{[]byte("people"), []byte("nested")}
+
+	result, err := p.PrettifyInBucket(path, person())
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "name": "alice",
+  "id": 42,
+  "tags": [
+    "a",
+    "b"
+  ]
+}`, result)
+
+	_, err = p.PrettifyInBucket([][]byte{[]byte("people")}, person())
+	require.Error(t, err, "message type isn't configured for this bucket")
+
+	_, err = p.Prettify(person())
+	require.Error(t, err, "bucket is unknown")
+
+	unknownField := protowire.AppendTag(person(), 10, protowire.VarintType)
+	unknownField = protowire.AppendVarint(unknownField, 1)
+	_, err = p.PrettifyInBucket(path, unknownField)
+	require.Error(t, err, "value contains a field which isn't a part of the message")
+
+	_, err = display.NewPrettifierProtobuf(nil).PrettifyInBucket(path, person())
+	require.Error(t, err, "types weren't provided")
+}
+
+func TestNewProtobufTypes(t *testing.T) {
+	file := writeDescriptorSet(t)
+
+	_, err := display.NewProtobufTypes(file, map[string]string{"bucket": "test.Missing"})
+	require.Error(t, err)
+
+	_, err = display.NewProtobufTypes(file, map[string]string{"bucket": "test"})
+	require.Error(t, err)
+
+	_, err = display.NewProtobufTypes(filepath.Join(t.TempDir(), "missing.pb"), nil)
+	require.Error(t, err)
+}
+
+func TestPrettyUsesRegisteredProtobufTypes(t *testing.T) {
+	types, err := display.NewProtobufTypes(writeDescriptorSet(t), map[string]string{
+		"people": "test.Person",
+	})
+	require.NoError(t, err)
+
+	display.RegisterProtobufTypes(types)
+	t.Cleanup(func() {
+		display.RegisterProtobufTypes(nil)
+	})
+
+	p := display.NewPretty()
+
+	result, err := p.PrintInBucket([][]byte{[]byte("people")}, person())
+	require.NoError(t, err)
+	require.Equal(t, display.ContentTypeProtobuf, result.Type)
+
+	result, err = p.PrintInBucket([][]byte{[]byte("other")}, person())
+	require.NoError(t, err)
+	require.Equal(t, display.ContentTypeProtobufWire, result.Type)
+
+	result, err = p.Print(person())
+	require.NoError(t, err)
+	require.Equal(t, display.ContentTypeProtobufWire, result.Type)
+
+	decoded, err := p.DecodeInBucket([][]byte{[]byte("people")}, person())
+	require.NoError(t, err)
+	require.Equal(t, display.ContentTypeProtobuf, decoded.Type)
+	require.Equal(t, map[string]interface{}{"name": "alice", "id": json.Number("42"), "tags": []interface{}{"a", "b"}}, decoded.Value)
+}
+
+func TestPrettifierProtobufWire(t *testing.T) {
+	nested := protowire.AppendTag(nil, 1, protowire.VarintType)
+	nested = protowire.AppendVarint(nested, 1)
+
+	message := person()
+	message = protowire.AppendTag(message, 4, protowire.BytesType)
+	message = protowire.AppendBytes(message, nested)
+	message = protowire.AppendTag(message, 5, protowire.Fixed32Type)
+	message = protowire.AppendFixed32(message, 0x3f800000)
+	message = protowire.AppendTag(message, 6, protowire.Fixed64Type)
+	message = protowire.AppendFixed64(message, 1)
+	message = protowire.AppendTag(message, 7, protowire.BytesType)
+	message = protowire.AppendBytes(message, []byte{0xff, 0x00})
+
+	result, err := display.NewPrettifierProtobufWire().Prettify(message)
+	require.NoError(t, err)
+	require.Equal(t, `1 (len): "alice"
+2 (varint): 42
+3 (len): "a"
+3 (len): "b"
+4 (len): {
+  1 (varint): 1
+}
+5 (i32): 0x3f800000
+6 (i64): 0x0000000000000001
+7 (len): 0xff00
+`, result)
+}
+
+func TestPrettifierProtobufWireRejectsInvalidValues(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Bytes []byte
+	}{
+		{
+			Name:  "empty",
+			Bytes: nil,
+		},
+		{
+			Name:  "group",
+			Bytes: protowire.AppendTag(nil, 1, protowire.StartGroupType),
+		},
+		{
+			Name:  "field_number_zero",
+			Bytes: []byte{0x00, 0x01},
+		},
+		{
+			Name:  "reserved_field_number",
+			Bytes: protowire.AppendVarint(protowire.AppendTag(nil, 19000, protowire.VarintType), 1),
+		},
+		{
+			Name:  "truncated",
+			Bytes: protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.BytesType), 10),
+		},
+		{
+			Name:  "random",
+			Bytes: []byte{0xff, 0x00},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := display.NewPrettifierProtobufWire().Prettify(testCase.Bytes)
+			require.Error(t, err)
+		})
+	}
+}
+
+func person() []byte {
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendString(b, "alice")
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, 42)
+	for _, tag := range []string{"a", "b"} {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, tag)
+	}
+	return b
+}
+
+func writeDescriptorSet(t *testing.T) string {
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("test.proto"),
+				Package: proto.String("test"),
+				Syntax:  proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Person"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{
+								Name:     proto.String("name"),
+								JsonName: proto.String("name"),
+								Number:   proto.Int32(1),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+							},
+							{
+								Name:     proto.String("id"),
+								JsonName: proto.String("id"),
+								Number:   proto.Int32(2),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+							},
+							{
+								Name:     proto.String("tags"),
+								JsonName: proto.String("tags"),
+								Number:   proto.Int32(3),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	b, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "descriptors.pb")
+	require.NoError(t, os.WriteFile(file, b, 0600))
+	return file
+}
@@ -0,0 +1,253 @@
+package display
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/boreq/errors"
+	"github.com/oklog/ulid/v2"
+)
+
+// RuleWildcard matches any single bucket name in the bucket patterns of the
+// rules. It is meant to be used for buckets named after IDs.
+const RuleWildcard = "*"
+
+// RuleValueRaw disables decoding the values stored in the matching buckets.
+const RuleValueRaw = "raw"
+
+type KeyCodec struct {
+	s string
+}
+
+func (c KeyCodec) IsZero() bool {
+	return c == KeyCodec{}
+}
+
+func (c KeyCodec) String() string {
+	return c.s
+}
+
+var (
+	KeyCodecString KeyCodec = KeyCodec{"string"}
+	KeyCodecHex    KeyCodec = KeyCodec{"hex"}
+
+	// KeyCodecUint64 and KeyCodecInt64 decode big endian integers, for
+	// example keys created from the sequences of buckets.
+	KeyCodecUint64 KeyCodec = KeyCodec{"uint64"}
+	KeyCodecInt64  KeyCodec = KeyCodec{"int64"}
+
+	KeyCodecULID KeyCodec = KeyCodec{"ulid"}
+	KeyCodecUUID KeyCodec = KeyCodec{"uuid"}
+)
+
+var keyCodecs = []KeyCodec{KeyCodecString, KeyCodecHex, KeyCodecUint64, KeyCodecInt64, KeyCodecULID, KeyCodecUUID}
+
+func newKeyCodec(name string) (KeyCodec, error) {
+	for _, codec := range keyCodecs {
+		if codec.s == name {
+			return codec, nil
+		}
+	}
+	return KeyCodec{}, fmt.Errorf("unknown key codec '%s'", name)
+}
+
+// PrettifiedKey is a key displayed using a key codec. The value of keys
+// displayed using KeyCodecString is equal to the key and the value of keys
+// displayed using KeyCodecHex is equal to the hex encoded key.
+type PrettifiedKey struct {
+	Codec KeyCodec
+	Value string
+}
+
+// Rule forces keys or values stored in the matching buckets to be decoded in
+// a specific way instead of detecting their format.
+type Rule struct {
+	// Bucket is a path to a bucket with the bucket names separated using
+	// slashes. Bucket names equal to RuleWildcard match any bucket.
+	Bucket string `json:"bucket"`
+
+	// Key is the name of the codec used to display the keys, for example
+	// "uint64". The codec is detected if it is empty.
+	Key string `json:"key,omitempty"`
+
+	// Value is the name of the content type used to display the values,
+	// for example "json" or "gzip+cbor", or RuleValueRaw. The content type
+	// is detected if it is empty.
+	Value string `json:"value,omitempty"`
+}
+
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rules maps bucket paths to the rules applied to them. If multiple rules
+// match a bucket then the key codec and the value content type are taken from
+// the first rule which specifies them.
+type Rules struct {
+	rules []rule
+}
+
+type rule struct {
+	bucket []string
+	key    KeyCodec
+	value  string
+}
+
+// LoadRules reads the rules from a JSON file in the following format:
+//
+//	{
+//	    "rules": [
+//	        {"bucket": "users/*/sessions", "key": "uint64", "value": "protobuf"}
+//	    ]
+//	}
+func LoadRules(file string) (*Rules, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the rules file")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	var f rulesFile
+	if err := decoder.Decode(&f); err != nil {
+		return nil, errors.Wrap(err, "could not decode the rules file")
+	}
+
+	return NewRules(f.Rules)
+}
+
+func NewRules(rules []Rule) (*Rules, error) {
+	r := &Rules{}
+	for i, rule := range rules {
+		v, err := newRule(rule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rule %d", i)
+		}
+		r.rules = append(r.rules, v)
+	}
+	return r, nil
+}
+
+func newRule(r Rule) (rule, error) {
+	bucket := strings.Trim(r.Bucket, "/")
+	if bucket == "" {
+		return rule{}, errors.New("bucket is empty")
+	}
+
+	if r.Key == "" && r.Value == "" {
+		return rule{}, errors.New("key and value are empty")
+	}
+
+	result := rule{
+		bucket: strings.Split(bucket, "/"),
+		value:  r.Value,
+	}
+
+	if r.Key != "" {
+		codec, err := newKeyCodec(r.Key)
+		if err != nil {
+			return rule{}, errors.Wrap(err, "invalid key")
+		}
+		result.key = codec
+	}
+
+	return result, nil
+}
+
+// keyCodec returns the key codec of the bucket or false if the codec should be
+// detected.
+func (r *Rules) keyCodec(path [][]byte) (KeyCodec, bool) {
+	for _, rule := range r.matching(path) {
+		if !rule.key.IsZero() {
+			return rule.key, true
+		}
+	}
+	return KeyCodec{}, false
+}
+
+// value returns the name of the content type of the bucket or false if the
+// content type should be detected.
+func (r *Rules) value(path [][]byte) (string, bool) {
+	for _, rule := range r.matching(path) {
+		if rule.value != "" {
+			return rule.value, true
+		}
+	}
+	return "", false
+}
+
+func (r *Rules) matching(path [][]byte) []rule {
+	if r == nil {
+		return nil
+	}
+
+	var result []rule
+	for _, rule := range r.rules {
+		if rule.matches(path) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+func (r rule) matches(path [][]byte) bool {
+	return matchBucket(r.bucket, path)
+}
+
+// matchBucket returns true if the path matches the bucket names in which
+// names equal to RuleWildcard match any bucket.
+func matchBucket(bucket []string, path [][]byte) bool {
+	if len(bucket) != len(path) {
+		return false
+	}
+
+	for i, name := range bucket {
+		if name != RuleWildcard && name != string(path[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func printKey(codec KeyCodec, b []byte) (PrettifiedKey, error) {
+	switch codec {
+	case KeyCodecString:
+		if !CanDisplayAsString(b) {
+			return PrettifiedKey{}, errors.New("key can't be displayed as a string")
+		}
+		return PrettifiedKey{Codec: codec, Value: string(b)}, nil
+	case KeyCodecHex:
+		return PrettifiedKey{Codec: codec, Value: hex.EncodeToString(b)}, nil
+	case KeyCodecUint64:
+		if len(b) != 8 {
+			return PrettifiedKey{}, errors.New("key isn't 8 bytes long")
+		}
+		return PrettifiedKey{Codec: codec, Value: strconv.FormatUint(binary.BigEndian.Uint64(b), 10)}, nil
+	case KeyCodecInt64:
+		if len(b) != 8 {
+			return PrettifiedKey{}, errors.New("key isn't 8 bytes long")
+		}
+		return PrettifiedKey{Codec: codec, Value: strconv.FormatInt(int64(binary.BigEndian.Uint64(b)), 10)}, nil
+	case KeyCodecULID:
+		var id ulid.ULID
+		if err := id.UnmarshalBinary(b); err != nil {
+			return PrettifiedKey{}, errors.Wrap(err, "key isn't a ulid")
+		}
+		return PrettifiedKey{Codec: codec, Value: id.String()}, nil
+	case KeyCodecUUID:
+		if len(b) != 16 {
+			return PrettifiedKey{}, errors.New("key isn't 16 bytes long")
+		}
+		h := hex.EncodeToString(b)
+		return PrettifiedKey{Codec: codec, Value: h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]}, nil
+	default:
+		return PrettifiedKey{}, fmt.Errorf("unknown key codec '%s'", codec)
+	}
+}
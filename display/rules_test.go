@@ -0,0 +1,277 @@
+package display_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boreq/bolt-ui/display"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesForceValueContentType(t *testing.T) {
+	registerRules(t, []display.Rule{
+		{Bucket: "protobuf", Value: "protobuf-wire"},
+		{Bucket: "users/*/json", Value: "json"},
+		{Bucket: "compressed", Value: "gzip+string"},
+		{Bucket: "raw", Value: display.RuleValueRaw},
+	})
+
+	gzipBuf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(gzipBuf)
+	_, err := gzipWriter.Write([]byte("some_string"))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	testCases := []struct {
+		Name  string
+		Path  []string
+		Bytes []byte
+		Type  string
+	}{
+		{
+			Name:  "forced",
+			Path:  []string{"protobuf"},
+			Bytes: []byte("(A"),
+			Type:  "protobuf-wire",
+		},
+		{
+			Name:  "not_forced",
+			Path:  []string{"other"},
+			Bytes: []byte("(A"),
+			Type:  "string",
+		},
+		{
+			Name:  "wildcard",
+			Path:  []string{"users", "\x00\x01", "json"},
+			Bytes: []byte("1"),
+			Type:  "json",
+		},
+		{
+			Name:  "wildcard_matches_a_single_bucket",
+			Path:  []string{"users", "json"},
+			Bytes: []byte("1"),
+			Type:  "cbor",
+		},
+		{
+			Name:  "compressed",
+			Path:  []string{"compressed"},
+			Bytes: gzipBuf.Bytes(),
+			Type:  "gzip+string",
+		},
+		{
+			Name:  "fallback",
+			Path:  []string{"users", "id", "json"},
+			Bytes: []byte("(A"),
+			Type:  "string",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			result, err := display.NewPretty().PrintInBucket(bucketPath(testCase.Path...), testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Type, result.Type.String())
+
+			decoded, err := display.NewPretty().DecodeInBucket(bucketPath(testCase.Path...), testCase.Bytes)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Type, decoded.Type.String())
+		})
+	}
+
+	_, err = display.NewPretty().PrintInBucket(bucketPath("raw"), []byte("some_string"))
+	require.Error(t, err)
+
+	_, err = display.NewPretty().DecodeInBucket(bucketPath("raw"), []byte("some_string"))
+	require.Error(t, err)
+}
+
+func TestRulesForceProtobufInWildcardBuckets(t *testing.T) {
+	types, err := display.NewProtobufTypes(writeDescriptorSet(t), map[string]string{
+		"users/*/sessions": "test.Person",
+	})
+	require.NoError(t, err)
+
+	display.RegisterProtobufTypes(types)
+	t.Cleanup(func() {
+		display.RegisterProtobufTypes(nil)
+	})
+
+	registerRules(t, []display.Rule{
+		{Bucket: "users/*/sessions", Value: "protobuf"},
+	})
+
+	// a short protobuf which could also be displayed as a string
+	value := []byte("\n " + strings.Repeat("a", 32))
+
+	result, err := display.NewPretty().PrintInBucket(bucketPath("users", "\x00\x01", "sessions"), value)
+	require.NoError(t, err)
+	require.Equal(t, display.ContentTypeProtobuf, result.Type)
+	require.Equal(t, "{\n  \"name\": \""+strings.Repeat("a", 32)+"\"\n}", result.Value)
+
+	result, err = display.NewPretty().PrintInBucket(bucketPath("users", "sessions"), value)
+	require.NoError(t, err)
+	require.Equal(t, display.ContentTypeString, result.Type)
+}
+
+func TestRulesForceKeyCodec(t *testing.T) {
+	registerRules(t, []display.Rule{
+		{Bucket: "uint64", Key: "uint64"},
+		{Bucket: "int64", Key: "int64"},
+		{Bucket: "hex", Key: "hex"},
+		{Bucket: "ulid", Key: "ulid"},
+		{Bucket: "uuid", Key: "uuid"},
+		{Bucket: "*", Key: "string"},
+	})
+
+	id := []byte{0x01, 0x8f, 0x3c, 0x2a, 0x6b, 0x1e, 0x4f, 0x2d, 0x9a, 0x7c, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66}
+
+	testCases := []struct {
+		Name   string
+		Path   []string
+		Key    []byte
+		Result display.PrettifiedKey
+	}{
+		{
+			Name:   "uint64",
+			Path:   []string{"uint64"},
+			Key:    []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x30, 0x39},
+			Result: display.PrettifiedKey{Codec: display.KeyCodecUint64, Value: "12345"},
+		},
+		{
+			Name:   "int64",
+			Path:   []string{"int64"},
+			Key:    []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			Result: display.PrettifiedKey{Codec: display.KeyCodecInt64, Value: "-1"},
+		},
+		{
+			Name:   "hex",
+			Path:   []string{"hex"},
+			Key:    []byte("abc"),
+			Result: display.PrettifiedKey{Codec: display.KeyCodecHex, Value: "616263"},
+		},
+		{
+			Name:   "ulid",
+			Path:   []string{"ulid"},
+			Key:    id,
+			Result: display.PrettifiedKey{Codec: display.KeyCodecULID, Value: "01HWY2MTRY9WPSMZ0H48SM8NB6"},
+		},
+		{
+			Name:   "uuid",
+			Path:   []string{"uuid"},
+			Key:    id,
+			Result: display.PrettifiedKey{Codec: display.KeyCodecUUID, Value: "018f3c2a-6b1e-4f2d-9a7c-112233445566"},
+		},
+		{
+			Name:   "fallback",
+			Path:   []string{"uint64"},
+			Key:    []byte("abc"),
+			Result: display.PrettifiedKey{Codec: display.KeyCodecString, Value: "abc"},
+		},
+		{
+			Name:   "fallback_to_hex",
+			Path:   []string{"other"},
+			Key:    []byte{0x00},
+			Result: display.PrettifiedKey{Codec: display.KeyCodecHex, Value: "00"},
+		},
+		{
+			Name:   "no_rules",
+			Path:   []string{"other", "nested"},
+			Key:    []byte{0x00},
+			Result: display.PrettifiedKey{Codec: display.KeyCodecHex, Value: "00"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			result := display.NewPretty().PrintKeyInBucket(bucketPath(testCase.Path...), testCase.Key)
+			require.Equal(t, testCase.Result, result)
+		})
+	}
+}
+
+func TestRegisterRulesValidatesValues(t *testing.T) {
+	t.Cleanup(func() {
+		require.NoError(t, display.RegisterRules(nil))
+	})
+
+	for _, value := range []string{"missing", "gzip", "missing+json", "json+gzip"} {
+		rules, err := display.NewRules([]display.Rule{{Bucket: "bucket", Value: value}})
+		require.NoError(t, err)
+		require.Error(t, display.RegisterRules(rules), value)
+	}
+
+	for _, value := range []string{"json", "zstd+gzip+cbor", display.RuleValueRaw} {
+		rules, err := display.NewRules([]display.Rule{{Bucket: "bucket", Value: value}})
+		require.NoError(t, err)
+		require.NoError(t, display.RegisterRules(rules), value)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Content string
+		Valid   bool
+	}{
+		{
+			Name:    "valid",
+			Content: `{"rules": [{"bucket": "users/*", "key": "uint64", "value": "json"}]}`,
+			Valid:   true,
+		},
+		{
+			Name:    "empty_bucket",
+			Content: `{"rules": [{"bucket": "/", "key": "uint64"}]}`,
+		},
+		{
+			Name:    "empty_rule",
+			Content: `{"rules": [{"bucket": "users"}]}`,
+		},
+		{
+			Name:    "unknown_key_codec",
+			Content: `{"rules": [{"bucket": "users", "key": "missing"}]}`,
+		},
+		{
+			Name:    "unknown_field",
+			Content: `{"rules": [{"bucket": "users", "key": "uint64", "other": "json"}]}`,
+		},
+		{
+			Name:    "invalid_json",
+			Content: `{"rules": `,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "rules.json")
+			require.NoError(t, os.WriteFile(file, []byte(testCase.Content), 0600))
+
+			_, err := display.LoadRules(file)
+			if testCase.Valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func registerRules(t *testing.T, r []display.Rule) {
+	rules, err := display.NewRules(r)
+	require.NoError(t, err)
+	require.NoError(t, display.RegisterRules(rules))
+	t.Cleanup(func() {
+		require.NoError(t, display.RegisterRules(nil))
+	})
+}
+
+func bucketPath(names ...string) [][]byte {
+	var result [][]byte
+	for _, name := range names {
+		result = append(result, []byte(name))
+	}
+	return result
+}
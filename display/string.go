@@ -14,13 +14,15 @@ func NewPrettifierString() *PrettifierString {
 }
 
 func (p *PrettifierString) Prettify(b []byte) (string, error) {
-	if p.canDisplayAsString(b) {
+	if CanDisplayAsString(b) {
 		return string(b), nil
 	}
 	return "", errors.New("can't display as string")
 }
 
-func (p *PrettifierString) canDisplayAsString(b []byte) bool {
+// CanDisplayAsString returns true if the bytes contain only graphic characters
+// and whitespace.
+func CanDisplayAsString(b []byte) bool {
 	for _, rne := range string(b) {
 		if !unicode.IsGraphic(rne) && !unicode.IsSpace(rne) {
 			return false
@@ -0,0 +1,112 @@
+package display
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/boreq/errors"
+)
+
+// maxStructuredDepth limits how deeply nested values are decoded by the
+// prettifiers which decode binary formats.
+const maxStructuredDepth = 64
+
+// orderedMap is used to display decoded maps and structs without changing
+// the order of their keys.
+type orderedMap []orderedMapEntry
+
+type orderedMapEntry struct {
+	Key   string
+	Value interface{}
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("{")
+	for i, entry := range m {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		key, err := marshalJSON(entry.Key)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal a key")
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+
+		value, err := marshalJSON(entry.Value)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not marshal a value")
+		}
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func (m orderedMap) has(key string) bool {
+	for _, entry := range m {
+		if entry.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalStructured displays a decoded value as indented JSON.
+func marshalStructured(v interface{}) (string, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", errors.Wrap(err, "could not encode")
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// structuredKey converts a decoded map key to a string.
+func structuredKey(key interface{}) (string, error) {
+	if s, ok := key.(string); ok {
+		return s, nil
+	}
+
+	b, err := marshalJSON(key)
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal the key")
+	}
+	return string(b), nil
+}
+
+// structuredFloat returns a string for values which can't be represented in
+// JSON.
+func structuredFloat(f float64, bitSize int) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+func structuredBytes(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
@@ -135,6 +135,8 @@ export default class Tree extends Vue {
             .then(
                 result => {
                     this.tree = result.data;
+                    this.noMoreBefore = !result.data.has_prev;
+                    this.noMoreAfter = !result.data.has_next;
                     this.loadMoreEntriesIfNeeded();
                     this.tryEmitPath();
                     this.tryEmitSelected();
@@ -186,9 +188,7 @@ export default class Tree extends Vue {
                 result => {
                     const currentFirstKey = this.firstKey;
                     if (currentFirstKey.hex === firstKey.hex) {
-                        if (result.data.entries.length === 0) {
-                            this.noMoreBefore = true;
-                        }
+                        this.noMoreBefore = !result.data.has_prev;
                         this.tree.entries = [
                             ...this.tree.entries,
                             ...result.data.entries,
@@ -226,9 +226,7 @@ export default class Tree extends Vue {
                 result => {
                     const currentLastKey = this.lastKey;
                     if (currentLastKey.hex === lastKey.hex) {
-                        if (result.data.entries.length === 0) {
-                            this.noMoreAfter = true;
-                        }
+                        this.noMoreAfter = !result.data.has_next;
                         this.tree.entries = [
                             ...this.tree.entries,
                             ...result.data.entries,
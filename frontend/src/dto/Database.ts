@@ -0,0 +1,6 @@
+import { SourceInfo } from '@/dto/SourceInfo';
+
+export class Database {
+    name: string;
+    source: SourceInfo;
+}
@@ -7,6 +7,12 @@ export class Entry {
 export class Key {
     hex: string;
     str: string;
+    decoded: DecodedKey;
+}
+
+export class DecodedKey {
+    codec: string;
+    value: string;
 }
 
 export class Value {
@@ -0,0 +1,4 @@
+export class SourceInfo {
+    snapshot_mode: boolean;
+    opened_at: string;
+}
@@ -3,4 +3,6 @@ import { Key, Entry } from '@/dto/Entry';
 export class Tree {
     path: Key[];
     entries: Entry[];
+    has_prev: boolean;
+    has_next: boolean;
 }
@@ -2,6 +2,8 @@ import { Vue } from 'vue-property-decorator';
 import axios, { AxiosResponse } from 'axios'; // do not add { }, some webshit bs?
 import { Mutation } from '@/store';
 import { Tree } from '@/dto/Tree';
+import { SourceInfo } from '@/dto/SourceInfo';
+import { Database } from '@/dto/Database';
 
 const authTokenHeaderName = 'Access-Token';
 
@@ -35,16 +37,39 @@ export class ApiService {
             });
     }
 
+    databases(): Promise<AxiosResponse<Database[]>> {
+        return this.axios.get<Database[]>(
+            process.env.VUE_APP_API_PREFIX + 'db',
+        );
+    }
+
     browse(path: string, before: string, after: string, from: string): Promise<AxiosResponse<Tree>> {
         const url = path ? `browse/${path}` : `browse/`;
         return this.axios.get<Tree>(
-            process.env.VUE_APP_API_PREFIX + url,
+            this.databasePrefix() + url,
             {
                 params: this.browseParams(before, after, from),
             },
         );
     }
 
+    sourceInfo(): Promise<AxiosResponse<SourceInfo>> {
+        return this.axios.get<SourceInfo>(
+            this.databasePrefix() + 'source',
+        );
+    }
+
+    takeSnapshot(): Promise<AxiosResponse<void>> {
+        return this.axios.post<void>(
+            this.databasePrefix() + 'snapshot',
+        );
+    }
+
+    private databasePrefix(): string {
+        const database = this.vue.$store.state.database;
+        return `${process.env.VUE_APP_API_PREFIX}db/${encodeURIComponent(database)}/`;
+    }
+
     private browseParams(before: string, after: string, from: string): { before: string } | { after: string } | { from: string} | null {
         if (before) {
             return {
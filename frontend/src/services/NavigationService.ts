@@ -3,8 +3,8 @@ import { Key as KeyDTO } from '@/dto/Entry';
 
 export class NavigationService {
 
-    getBrowse(path: KeyDTO[], value: KeyDTO): Location {
-        const query = this.getQuery(value);
+    getBrowse(database: string, path: KeyDTO[], value: KeyDTO): Location {
+        const query = this.getQuery(database, value);
 
         if (path.length === 0) {
             return {
@@ -23,12 +23,15 @@ export class NavigationService {
         };
     }
 
-    private getQuery(value: KeyDTO): { value: string } {
+    private getQuery(database: string, value: KeyDTO): { database: string, value?: string } {
         if (!value) {
-            return null;
+            return {
+                database: database,
+            };
         }
 
         return {
+            database: database,
             value: value.hex,
         };
     }
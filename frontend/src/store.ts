@@ -5,20 +5,26 @@ Vue.use(Vuex);
 
 export enum Mutation {
     SetToken = 'setToken',
+    SetDatabase = 'setDatabase',
 }
 
 export class State {
     token: string;
+    database: string;
 }
 
 export default new Vuex.Store<State>({
     state: {
         token: undefined,
+        database: undefined,
     },
     mutations: {
         [Mutation.SetToken](state: State, token: string): void {
             state.token = token;
         },
+        [Mutation.SetDatabase](state: State, database: string): void {
+            state.database = database;
+        },
     },
 });
 
@@ -1,6 +1,9 @@
 import { Component, Vue, Watch } from 'vue-property-decorator';
 import { Mutation } from '@/store';
 import { Entry as EntryDTO, Key as KeyDTO } from '@/dto/Entry';
+import { SourceInfo as SourceInfoDTO } from '@/dto/SourceInfo';
+import { Database as DatabaseDTO } from '@/dto/Database';
+import { ApiService } from '@/services/ApiService';
 import { NavigationService } from '@/services/NavigationService';
 import { PathService } from '@/services/PathService';
 
@@ -26,6 +29,12 @@ export default class Browse extends Vue {
     editingSelectedPath = false;
     editedPath: string = null;
 
+    databases: DatabaseDTO[] = [];
+    sourceInfo: SourceInfoDTO = null;
+    takingSnapshot = false;
+    snapshotsTaken = 0;
+
+    private readonly apiService = new ApiService(this);
     private readonly navigationService = new NavigationService();
     private readonly pathService = new PathService();
 
@@ -53,15 +62,34 @@ export default class Browse extends Vue {
         return path;
     }
 
+    get database(): string {
+        return this.$store.state.database;
+    }
+
     @Watch('$route')
     onRouteChanged(): void {
         this.setToken();
+        this.setDatabase();
         this.loadFromRoute();
     }
 
+    @Watch('database')
+    onDatabaseChanged(): void {
+        this.loadSourceInfo();
+    }
+
+    get snapshotTakenAt(): string {
+        if (!this.sourceInfo) {
+            return null;
+        }
+        return new Date(this.sourceInfo.opened_at).toLocaleString();
+    }
+
     created(): void {
         this.setToken();
+        this.setDatabase();
         this.loadFromRoute();
+        this.loadDatabases();
 
         document.body.addEventListener('click', this.cancelEditing);
     }
@@ -71,13 +99,39 @@ export default class Browse extends Vue {
     }
 
     treeKey(path: KeyDTO[]): string {
-        return path.map(v => v.hex).join('-');
+        const key = path.map(v => v.hex).join('-');
+        return `${this.database}-${this.snapshotsTaken}-${key}`;
+    }
+
+    takeSnapshot(): void {
+        if (this.takingSnapshot) {
+            return;
+        }
+
+        this.takingSnapshot = true;
+        this.apiService.takeSnapshot()
+            .then(() => {
+                Notifications.pushSuccess(this, 'Snapshot taken.');
+                this.snapshotsTaken++;
+                this.loadSourceInfo();
+            })
+            .catch(error => {
+                Notifications.pushError(this, 'Could not take a snapshot.', error);
+            })
+            .finally(() => {
+                this.takingSnapshot = false;
+            });
     }
 
     onHeaderClick(): void {
         this.loadBlank();
     }
 
+    onDatabaseChange(database: string): void {
+        const next = this.navigationService.getBrowse(database, [], null);
+        this.$router.push(next);
+    }
+
     onEntry(path: KeyDTO[], entry: EntryDTO): void {
         const index = this.paths.indexOf(path);
         if (index >= 0) {
@@ -89,7 +143,7 @@ export default class Browse extends Vue {
             this.paths.push(childPath);
             this.selectedValueKey = null;
 
-            const next = this.navigationService.getBrowse(childPath, null);
+            const next = this.navigationService.getBrowse(this.database, childPath, null);
             this.$router.push(next);
         } else {
             const shouldNavigate = this.selectedValueKey?.hex !== entry.key?.hex;
@@ -97,7 +151,7 @@ export default class Browse extends Vue {
             this.selectedValueKey = entry.key;
 
             if (shouldNavigate) {
-                const next = this.navigationService.getBrowse(path, entry.key);
+                const next = this.navigationService.getBrowse(this.database, path, entry.key);
                 this.$router.push(next);
             }
         }
@@ -152,6 +206,36 @@ export default class Browse extends Vue {
         }
     }
 
+    private setDatabase(): void {
+        const database = this.$route.query.database;
+        if (database && database !== this.database) {
+            this.$store.commit(Mutation.SetDatabase, database);
+        }
+    }
+
+    private loadDatabases(): void {
+        this.apiService.databases()
+            .then(response => {
+                this.databases = response.data;
+                if (!this.database && this.databases.length > 0) {
+                    this.$store.commit(Mutation.SetDatabase, this.databases[0].name);
+                }
+            })
+            .catch(error => {
+                Notifications.pushError(this, 'Could not query the backend.', error);
+            });
+    }
+
+    private loadSourceInfo(): void {
+        this.apiService.sourceInfo()
+            .then(response => {
+                this.sourceInfo = response.data;
+            })
+            .catch(error => {
+                Notifications.pushError(this, 'Could not query the backend.', error);
+            });
+    }
+
     private loadBlank(): void {
         this.paths = [
             [],
@@ -163,7 +247,8 @@ export default class Browse extends Vue {
     private loadFromRoute(): void {
         this.loadBlank();
 
-        const path: KeyDTO[] = this.$route.params.pathMatch
+        const pathMatch = this.$route.params.pathMatch || '';
+        const path: KeyDTO[] = pathMatch
             .split('/')
             .filter(v => v !== "")
             .map(
@@ -1,6 +1,9 @@
 var webpack = require('webpack');
 
 module.exports = {
+    // The paths are relative to the base element of the index so that the
+    // frontend can be served under a prefix.
+    publicPath: '',
     css: {
         loaderOptions: {
             sass: {
@@ -10,12 +10,14 @@ require (
 	github.com/google/wire v0.6.0
 	github.com/inconshreveable/log15 v0.0.0-20180818164646-67afb5ed74ec
 	github.com/julienschmidt/httprouter v1.2.0
+	github.com/klauspost/compress v1.18.0
 	github.com/oklog/ulid/v2 v2.0.2
 	github.com/pkg/errors v0.8.1
 	github.com/polydawn/refmt v0.89.0
 	github.com/rs/cors v1.6.0
-	github.com/stretchr/testify v1.7.0
-	go.etcd.io/bbolt v1.3.3
+	github.com/stretchr/testify v1.8.1
+	go.etcd.io/bbolt v1.3.8
+	google.golang.org/protobuf v1.36.11
 )
 
 require (
@@ -28,7 +30,7 @@ require (
 	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/sys v0.23.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 go 1.24
@@ -5,10 +5,47 @@ import "crypto/tls"
 
 type Config struct {
 	ServeAddress  string
-	DatabaseFile  string
 	Token         string
 	Certificate   tls.Certificate
 	InsecureCORS  bool
 	InsecureToken bool
 	InsecureTLS   bool
+	EnableWrites  bool
+	ReadOnly      bool
+	Snapshot      bool
+
+	EnableCompaction bool
+	EnableDiff       bool
+
+	// PageSize is the number of entries returned when browsing if the
+	// client doesn't specify it. The default page size is used if it is
+	// zero.
+	PageSize int
+
+	// ProtobufDescriptors is a path to a file containing a protobuf
+	// FileDescriptorSet. It is empty if message types weren't configured.
+	ProtobufDescriptors string
+
+	// ProtobufMessages maps slash separated bucket paths to the names of
+	// the protobuf messages stored in those buckets.
+	ProtobufMessages map[string]string
+
+	// DecodingRules is a path to a file containing the rules which specify
+	// how the keys and values stored in buckets are decoded. It is empty if
+	// the rules weren't configured.
+	DecodingRules string
+
+	// URLPrefix is the path under which the HTTP handler is mounted, for
+	// example "/debug/bolt". It is empty if the handler is mounted at the
+	// root.
+	URLPrefix string
+
+	// Databases are served by the HTTP server.
+	Databases []Database
+}
+
+type Database struct {
+	// Name is used to refer to the database in the API.
+	Name string
+	File string
 }
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boreq/errors"
+)
+
+// DatabaseExtensions lists the extensions of the files which are served when
+// a directory is specified.
+var DatabaseExtensions = []string{".db", ".bolt"}
+
+// FindDatabases returns the databases located at the specified paths. Paths
+// pointing to directories are scanned for files with one of the
+// DatabaseExtensions, the directories are not scanned recursively. Other paths
+// are used as they are. The databases are named after their files without
+// the extension and the names have to be unique.
+func FindDatabases(paths []string) ([]Database, error) {
+	var databases []Database
+	names := make(map[string]string)
+
+	for _, path := range paths {
+		files, err := findDatabaseFiles(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find the databases in '%s'", path)
+		}
+
+		for _, file := range files {
+			database := NewDatabase(file)
+			if database.Name == "" {
+				return nil, fmt.Errorf("could not create a name for '%s'", file)
+			}
+
+			if other, ok := names[database.Name]; ok {
+				return nil, fmt.Errorf("files '%s' and '%s' would both be served as '%s'", other, file, database.Name)
+			}
+			names[database.Name] = file
+
+			databases = append(databases, database)
+		}
+	}
+
+	if len(databases) == 0 {
+		return nil, errors.New("no databases found")
+	}
+
+	return databases, nil
+}
+
+// NewDatabase creates a database named after its file without the extension.
+func NewDatabase(file string) Database {
+	return Database{
+		Name: databaseName(file),
+		File: file,
+	}
+}
+
+func findDatabaseFiles(path string) ([]string, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Wrap(err, "database file does not exist")
+		}
+		return nil, errors.Wrap(err, "stat failed")
+	}
+
+	if !fileInfo.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the directory")
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && hasDatabaseExtension(entry.Name()) {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	return files, nil
+}
+
+func hasDatabaseExtension(name string) bool {
+	for _, extension := range DatabaseExtensions {
+		if strings.HasSuffix(name, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+func databaseName(file string) string {
+	name := filepath.Base(file)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,70 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/errors"
+)
+
+type SourceMock struct {
+	SnapshotsTaken int
+	Reopens        int
+	SourceInfo     application.SourceInfo
+	DatabaseInfos  []application.DatabaseInfo
+	Compactions    []string
+	Diffs          []string
+	DiffEntries    []application.DiffEntry
+}
+
+func NewSourceMock() *SourceMock {
+	return &SourceMock{}
+}
+
+func (s *SourceMock) TakeSnapshot() error {
+	if !s.SourceInfo.SnapshotMode {
+		return application.ErrSnapshotsDisabled
+	}
+	s.SnapshotsTaken++
+	return nil
+}
+
+func (s *SourceMock) Reopen() error {
+	s.Reopens++
+	s.SourceInfo.ReopenedAt = time.Now()
+	return nil
+}
+
+func (s *SourceMock) Info() application.SourceInfo {
+	return s.SourceInfo
+}
+
+// DatabaseInfo returns the first element of DatabaseInfos and removes it from
+// the slice. The last element is returned repeatedly.
+func (s *SourceMock) DatabaseInfo() (application.DatabaseInfo, error) {
+	if len(s.DatabaseInfos) == 0 {
+		return application.DatabaseInfo{}, errors.New("no database info")
+	}
+
+	info := s.DatabaseInfos[0]
+	if len(s.DatabaseInfos) > 1 {
+		s.DatabaseInfos = s.DatabaseInfos[1:]
+	}
+	return info, nil
+}
+
+func (s *SourceMock) Compact(destination string, fn application.CompactionProgressFn) (application.CompactionResult, error) {
+	s.Compactions = append(s.Compactions, destination)
+	return application.CompactionResult{}, nil
+}
+
+// Diff records the other path and calls fn for every element of DiffEntries.
+func (s *SourceMock) Diff(other string, path []application.Key, fn application.DiffFn) error {
+	s.Diffs = append(s.Diffs, other)
+	for _, entry := range s.DiffEntries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
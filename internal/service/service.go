@@ -1,15 +1,27 @@
 package service
 
 import (
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
 	httpPort "github.com/boreq/bolt-ui/ports/http"
 )
 
 type Service struct {
 	HTTPServer *httpPort.Server
+	Databases  []Database
 }
 
-func NewService(httpServer *httpPort.Server) *Service {
+func NewService(httpServer *httpPort.Server, databases []Database) *Service {
 	return &Service{
 		HTTPServer: httpServer,
+		Databases:  databases,
 	}
 }
+
+// Database is an application serving a single database file.
+type Database struct {
+	Name        string
+	Application *application.Application
+	Sampler     *application.DatabaseInfoSampler
+	Source      *boltadapters.Source
+}
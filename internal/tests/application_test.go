@@ -5,6 +5,7 @@ import (
 	"sort"
 	"testing"
 
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
 	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/bolt-ui/internal/fixture"
 	"github.com/boreq/bolt-ui/internal/wire"
@@ -36,46 +37,46 @@ func TestBrowseRoot(t *testing.T) {
 
 	// initial
 	tree, err := testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, nil, nil, nil),
+		application.MustNewBrowse(nil, nil, nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (firstPage), tree.Entries)
 
 	// first page
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, keyPointer(firstPage[0].Key), nil, nil),
+		application.MustNewBrowse(nil, keyPointer(firstPage[0].Key), nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Empty(t, tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, nil, keyPointer(firstPage[len(firstPage)-1].Key), nil),
+		application.MustNewBrowse(nil, nil, keyPointer(firstPage[len(firstPage)-1].Key), nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (secondPage), tree.Entries)
 
 	// second page
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, keyPointer(secondPage[0].Key), nil, nil),
+		application.MustNewBrowse(nil, keyPointer(secondPage[0].Key), nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (firstPage), tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, nil, keyPointer(secondPage[len(secondPage)-1].Key), nil),
+		application.MustNewBrowse(nil, nil, keyPointer(secondPage[len(secondPage)-1].Key), nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (thirdPage), tree.Entries)
 
 	// third page
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, keyPointer(thirdPage[0].Key), nil, nil),
+		application.MustNewBrowse(nil, keyPointer(thirdPage[0].Key), nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (secondPage), tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, nil, keyPointer(thirdPage[len(thirdPage)-1].Key), nil),
+		application.MustNewBrowse(nil, nil, keyPointer(thirdPage[len(thirdPage)-1].Key), nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Empty(t, tree.Entries)
@@ -127,57 +128,197 @@ func TestBrowse(t *testing.T) {
 
 	// initial
 	tree, err := testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, nil, nil, nil),
+		application.MustNewBrowse(path, nil, nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (firstPage), tree.Entries)
 
 	// first page
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, keyPointer(firstPage[0].Key), nil, nil),
+		application.MustNewBrowse(path, keyPointer(firstPage[0].Key), nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Empty(t, tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, nil, keyPointer(firstPage[len(firstPage)-1].Key), nil),
+		application.MustNewBrowse(path, nil, keyPointer(firstPage[len(firstPage)-1].Key), nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (secondPage), tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, nil, nil, keyPointer(firstPage[0].Key)),
+		application.MustNewBrowse(path, nil, nil, keyPointer(firstPage[0].Key), nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, firstPage, tree.Entries)
 
 	// second page
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, keyPointer(secondPage[0].Key), nil, nil),
+		application.MustNewBrowse(path, keyPointer(secondPage[0].Key), nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (firstPage), tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, nil, keyPointer(secondPage[len(secondPage)-1].Key), nil),
+		application.MustNewBrowse(path, nil, keyPointer(secondPage[len(secondPage)-1].Key), nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (thirdPage), tree.Entries)
 
 	// third page
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, keyPointer(thirdPage[0].Key), nil, nil),
+		application.MustNewBrowse(path, keyPointer(thirdPage[0].Key), nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t, (secondPage), tree.Entries)
 
 	tree, err = testApp.Application.Browse.Execute(
-		application.MustNewBrowse(nil, nil, keyPointer(thirdPage[len(thirdPage)-1].Key), nil),
+		application.MustNewBrowse(nil, nil, keyPointer(thirdPage[len(thirdPage)-1].Key), nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Empty(t, tree.Entries)
 }
 
+func TestBrowseLimit(t *testing.T) {
+	testApp := NewTracker(t)
+
+	expectedEntries := bucketEntries(25)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		for _, entry := range expectedEntries {
+			_, err := tx.CreateBucketIfNotExists(entry.Key.Bytes())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = application.NewBrowse(nil, nil, nil, nil, nil, 0)
+	require.Error(t, err)
+
+	_, err = application.NewBrowse(nil, nil, nil, nil, nil, application.MaxLimit+1)
+	require.Error(t, err)
+
+	tree, err := testApp.Application.Browse.Execute(
+		application.MustNewBrowse(nil, nil, nil, nil, nil, 20),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expectedEntries[0:20], tree.Entries)
+	require.False(t, tree.HasPrevious)
+	require.True(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(nil, nil, keyPointer(expectedEntries[9].Key), nil, nil, 10),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expectedEntries[10:20], tree.Entries)
+	require.True(t, tree.HasPrevious)
+	require.True(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(nil, nil, keyPointer(expectedEntries[19].Key), nil, nil, 10),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expectedEntries[20:25], tree.Entries)
+	require.True(t, tree.HasPrevious)
+	require.False(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(nil, keyPointer(expectedEntries[0].Key), nil, nil, nil, 10),
+	)
+	require.NoError(t, err)
+	require.Empty(t, tree.Entries)
+	require.False(t, tree.HasPrevious)
+	require.True(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(nil, nil, keyPointer(expectedEntries[24].Key), nil, nil, 10),
+	)
+	require.NoError(t, err)
+	require.Empty(t, tree.Entries)
+	require.True(t, tree.HasPrevious)
+	require.False(t, tree.HasNext)
+}
+
+func TestBrowsePrefix(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+
+	var keys []string
+	for _, user := range []string{"user:1:", "user:2:", "user:3:"} {
+		for _, suffix := range []string{"a", "b", "c", "d", "e"} {
+			keys = append(keys, user+suffix)
+		}
+	}
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := bucket.Put([]byte(key), []byte("value")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+	prefix := application.MustNewKey([]byte("user:2:"))
+
+	_, err = application.NewBrowse(path, nil, nil, keyPointer(application.MustNewKey([]byte("user:1:a"))), &prefix, 10)
+	require.Error(t, err)
+
+	tree, err := testApp.Application.Browse.Execute(
+		application.MustNewBrowse(path, nil, nil, nil, &prefix, 3),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user:2:a", "user:2:b", "user:2:c"}, entryKeys(tree.Entries))
+	require.False(t, tree.HasPrevious)
+	require.True(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(path, nil, keyPointer(tree.Entries[2].Key), nil, &prefix, 3),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user:2:d", "user:2:e"}, entryKeys(tree.Entries))
+	require.True(t, tree.HasPrevious)
+	require.False(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(path, keyPointer(tree.Entries[0].Key), nil, nil, &prefix, 2),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user:2:b", "user:2:c"}, entryKeys(tree.Entries))
+	require.True(t, tree.HasPrevious)
+	require.True(t, tree.HasNext)
+
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(path, nil, nil, keyPointer(application.MustNewKey([]byte("user:2:c"))), &prefix, 10),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user:2:c", "user:2:d", "user:2:e"}, entryKeys(tree.Entries))
+	require.True(t, tree.HasPrevious)
+	require.False(t, tree.HasNext)
+
+	missingPrefix := application.MustNewKey([]byte("user:4:"))
+	tree, err = testApp.Application.Browse.Execute(
+		application.MustNewBrowse(path, nil, nil, nil, &missingPrefix, 10),
+	)
+	require.NoError(t, err)
+	require.Empty(t, tree.Entries)
+	require.False(t, tree.HasPrevious)
+	require.False(t, tree.HasNext)
+}
+
 func TestBrowseNilValues(t *testing.T) {
 	testApp := NewTracker(t)
 
@@ -215,7 +356,7 @@ func TestBrowseNilValues(t *testing.T) {
 	}
 
 	tree, err := testApp.Application.Browse.Execute(
-		application.MustNewBrowse(path, nil, nil, nil),
+		application.MustNewBrowse(path, nil, nil, nil, nil, 10),
 	)
 	require.NoError(t, err)
 	require.Equal(t,
@@ -239,11 +380,332 @@ func TestBrowseNilValues(t *testing.T) {
 		tree.Entries)
 }
 
+func TestPut(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	key := []byte("key")
+	nestedBucketName := []byte("nested")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = bucket.CreateBucket(nestedBucketName)
+		return err
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	for _, value := range []string{"value1", "value2"} {
+		err = testApp.Application.Put.Execute(
+			application.MustNewPut(path, application.MustNewKey(key), application.MustNewValue([]byte(value))),
+		)
+		require.NoError(t, err)
+
+		err = testApp.DB.View(func(tx *bbolt.Tx) error {
+			require.Equal(t, []byte(value), tx.Bucket(bucketName).Get(key))
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	err = testApp.Application.Put.Execute(
+		application.MustNewPut(path, application.MustNewKey(nestedBucketName), application.MustNewValue([]byte("value"))),
+	)
+	require.ErrorIs(t, err, application.ErrKeyIsABucket)
+
+	err = testApp.Application.Put.Execute(
+		application.MustNewPut(
+			[]application.Key{application.MustNewKey([]byte("missing"))},
+			application.MustNewKey(key),
+			application.MustNewValue([]byte("value")),
+		),
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestDelete(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	key := []byte("key")
+	nestedBucketName := []byte("nested")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket(nestedBucketName); err != nil {
+			return err
+		}
+
+		return bucket.Put(key, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	err = testApp.Application.Delete.Execute(
+		application.MustNewDelete(path, application.MustNewKey(key)),
+	)
+	require.NoError(t, err)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket(bucketName).Get(key))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = testApp.Application.Delete.Execute(
+		application.MustNewDelete(path, application.MustNewKey(key)),
+	)
+	require.ErrorIs(t, err, application.ErrKeyNotFound)
+
+	err = testApp.Application.Delete.Execute(
+		application.MustNewDelete(path, application.MustNewKey(nestedBucketName)),
+	)
+	require.ErrorIs(t, err, application.ErrKeyIsABucket)
+}
+
+func TestWritesDisabled(t *testing.T) {
+	testApp := NewTrackerWithPermissions(t, application.Permissions{Write: false})
+
+	bucketName := []byte("bucket")
+	key := []byte("key")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	err = testApp.Application.Put.Execute(
+		application.MustNewPut(path, application.MustNewKey(key), application.MustNewValue([]byte("new value"))),
+	)
+	require.ErrorIs(t, err, application.ErrWritesDisabled)
+
+	err = testApp.Application.Delete.Execute(
+		application.MustNewDelete(path, application.MustNewKey(key)),
+	)
+	require.ErrorIs(t, err, application.ErrWritesDisabled)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.Equal(t, []byte("value"), tx.Bucket(bucketName).Get(key))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	nestedBucketName := []byte("nested")
+	key := []byte("key")
+
+	err := testApp.Application.CreateBucket.Execute(
+		application.MustNewCreateBucket([]application.Key{application.MustNewKey(bucketName)}),
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.MustNewCreateBucket([]application.Key{application.MustNewKey(bucketName)}),
+	)
+	require.ErrorIs(t, err, application.ErrKeyAlreadyExists)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.MustNewCreateBucket([]application.Key{application.MustNewKey(bucketName), application.MustNewKey(nestedBucketName)}),
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.Put.Execute(
+		application.MustNewPut([]application.Key{application.MustNewKey(bucketName)}, application.MustNewKey(key), application.MustNewValue([]byte("value"))),
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.MustNewCreateBucket([]application.Key{application.MustNewKey(bucketName), application.MustNewKey(key)}),
+	)
+	require.ErrorIs(t, err, application.ErrKeyAlreadyExists)
+
+	err = testApp.Application.CreateBucket.Execute(
+		application.MustNewCreateBucket([]application.Key{application.MustNewKey([]byte("missing")), application.MustNewKey(nestedBucketName)}),
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket(bucketName).Bucket(nestedBucketName))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDeleteBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	bucketName := []byte("bucket")
+	nestedBucketName := []byte("nested")
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket(bucketName)
+		if err != nil {
+			return err
+		}
+
+		nested, err := bucket.CreateBucket(nestedBucketName)
+		if err != nil {
+			return err
+		}
+
+		return nested.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey(bucketName),
+	}
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.MustNewDeleteBucket(path),
+	)
+	require.NoError(t, err)
+
+	err = testApp.Application.DeleteBucket.Execute(
+		application.MustNewDeleteBucket(path),
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket(bucketName))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMoveBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		source, err := tx.CreateBucket([]byte("source"))
+		if err != nil {
+			return err
+		}
+
+		if err := source.SetSequence(10); err != nil {
+			return err
+		}
+
+		if err := source.Put([]byte("key1"), []byte("value1")); err != nil {
+			return err
+		}
+
+		nested, err := source.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+
+		if err := nested.SetSequence(20); err != nil {
+			return err
+		}
+
+		if err := nested.Put([]byte("key2"), []byte("value2")); err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket([]byte("parent"))
+		return err
+	})
+	require.NoError(t, err)
+
+	source := []application.Key{
+		application.MustNewKey([]byte("source")),
+	}
+
+	destination := []application.Key{
+		application.MustNewKey([]byte("parent")),
+		application.MustNewKey([]byte("destination")),
+	}
+
+	_, err = application.NewMoveBucket(source, append(source, application.MustNewKey([]byte("nested"))))
+	require.Error(t, err)
+
+	err = testApp.Application.MoveBucket.Execute(
+		application.MustNewMoveBucket(source, destination),
+	)
+	require.NoError(t, err)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket([]byte("source")))
+
+		moved := tx.Bucket([]byte("parent")).Bucket([]byte("destination"))
+		require.NotNil(t, moved)
+		require.Equal(t, uint64(10), moved.Sequence())
+		require.Equal(t, []byte("value1"), moved.Get([]byte("key1")))
+
+		nested := moved.Bucket([]byte("nested"))
+		require.NotNil(t, nested)
+		require.Equal(t, uint64(20), nested.Sequence())
+		require.Equal(t, []byte("value2"), nested.Get([]byte("key2")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = testApp.Application.MoveBucket.Execute(
+		application.MustNewMoveBucket(source, destination),
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestReadOnlyDatabaseCanBeOpenedMultipleTimes(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	db, err := boltadapters.NewBolt(file, false)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db1, err := boltadapters.NewBolt(file, true)
+	require.NoError(t, err)
+	defer db1.Close()
+
+	db2, err := boltadapters.NewBolt(file, true)
+	require.NoError(t, err)
+	defer db2.Close()
+
+	err = db1.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("bucket"))
+		return err
+	})
+	require.ErrorIs(t, err, bbolt.ErrDatabaseReadOnly)
+}
+
 func NewTracker(t *testing.T) wire.TestApplication {
+	return NewTrackerWithPermissions(t, application.Permissions{Write: true})
+}
+
+func NewTrackerWithPermissions(t *testing.T, permissions application.Permissions) wire.TestApplication {
 	db, cleanup := fixture.Bolt(t)
 	t.Cleanup(cleanup)
 
-	application, err := wire.BuildApplicationForTest(db)
+	application, err := wire.BuildApplicationForTest(db, permissions)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -314,6 +776,14 @@ func randString() string {
 	return string(b)
 }
 
+func entryKeys(entries []application.Entry) []string {
+	var result []string
+	for _, entry := range entries {
+		result = append(result, string(entry.Key.Bytes()))
+	}
+	return result
+}
+
 func keyPointer(v application.Key) *application.Key {
 	return &v
 }
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBackup(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	var size int64
+
+	err = testApp.Application.Backup.Execute(application.Backup{}, func(s int64) (io.Writer, error) {
+		size = s
+		return buf, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, size, int64(buf.Len()))
+
+	file := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, os.WriteFile(file, buf.Bytes(), 0600))
+
+	db, err := boltadapters.NewBolt(file, true)
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("bucket"))
+		require.NotNil(t, bucket)
+		require.Equal(t, []byte("value"), bucket.Get([]byte("key")))
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte("key"), []byte("value"))
+	})
+	require.NoError(t, err)
+
+	var problems []error
+	n, err := testApp.Application.CheckConsistency.Execute(context.Background(), application.CheckConsistency{}, func(problem error) error {
+		problems = append(problems, problem)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	require.Empty(t, problems)
+}
+
+func TestCheckConsistencyReportsProblems(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createFreePages(t, file)
+	removeFreePage(t, file)
+
+	db, err := boltadapters.NewBolt(file, true)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	testApp, err := wire.BuildApplicationForTest(db, application.Permissions{})
+	require.NoError(t, err)
+
+	var problems []error
+	n, err := testApp.Application.CheckConsistency.Execute(context.Background(), application.CheckConsistency{}, func(problem error) error {
+		problems = append(problems, problem)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0].Error(), "unreachable unfreed")
+}
+
+func TestCheckConsistencyCanBeInterrupted(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createFreePages(t, file)
+	removeFreePage(t, file)
+
+	db, err := boltadapters.NewBolt(file, true)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	testApp, err := wire.BuildApplicationForTest(db, application.Permissions{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = testApp.Application.CheckConsistency.Execute(ctx, application.CheckConsistency{}, func(problem error) error {
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// createFreePages creates a database with pages on the freelist.
+func createFreePages(t *testing.T, file string) {
+	db, err := boltadapters.NewBolt(file, false)
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < 1000; i++ {
+			if err := bucket.Put([]byte(fmt.Sprintf("key%d", i)), make([]byte, 100)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		return tx.DeleteBucket([]byte("bucket"))
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Close())
+}
+
+// removeFreePage corrupts the database by decrementing the number of elements
+// stored on the freelist page used by the most recent meta page which makes
+// one of the free pages unreachable.
+func removeFreePage(t *testing.T, file string) {
+	const (
+		pageHeaderSize      = 16
+		pageCountOffset     = 10
+		metaPageSizeOffset  = pageHeaderSize + 8
+		metaFreelistOffset  = pageHeaderSize + 32
+		metaTxidOffset      = pageHeaderSize + 48
+		maxInlineCountValue = 0xFFFF
+	)
+
+	f, err := os.OpenFile(file, os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	meta := make([]byte, 4096)
+	_, err = f.ReadAt(meta, 0)
+	require.NoError(t, err)
+
+	pageSize := int64(binary.LittleEndian.Uint32(meta[metaPageSizeOffset:]))
+
+	var freelist uint64
+	var txid uint64
+	for i := int64(0); i < 2; i++ {
+		meta := make([]byte, pageSize)
+		_, err := f.ReadAt(meta, i*pageSize)
+		require.NoError(t, err)
+
+		if metaTxid := binary.LittleEndian.Uint64(meta[metaTxidOffset:]); metaTxid >= txid {
+			txid = metaTxid
+			freelist = binary.LittleEndian.Uint64(meta[metaFreelistOffset:])
+		}
+	}
+
+	count := make([]byte, 2)
+	_, err = f.ReadAt(count, int64(freelist)*pageSize+pageCountOffset)
+	require.NoError(t, err)
+
+	n := binary.LittleEndian.Uint16(count)
+	require.True(t, n > 1 && n < maxInlineCountValue, "unexpected number of free pages: %d", n)
+
+	binary.LittleEndian.PutUint16(count, n-1)
+	_, err = f.WriteAt(count, int64(freelist)*pageSize+pageCountOffset)
+	require.NoError(t, err)
+}
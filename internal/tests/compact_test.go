@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestCompact(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	db, err := boltadapters.NewBolt(file, false)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		err = db.Update(func(tx *bbolt.Tx) error {
+			bucket, err := tx.CreateBucket([]byte(fmt.Sprintf("bucket%d", i)))
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.SetSequence(uint64(i + 1)); err != nil {
+				return err
+			}
+
+			for j := 0; j < 1000; j++ {
+				if err := bucket.Put([]byte(fmt.Sprintf("key%d", j)), make([]byte, 100)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for i := 1; i < 5; i++ {
+			if err := tx.DeleteBucket([]byte(fmt.Sprintf("bucket%d", i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	source, err := boltadapters.NewSource(file, true)
+	require.NoError(t, err)
+
+	destination := filepath.Join(t.TempDir(), "compacted.db")
+
+	var progress []application.CompactionProgress
+	result, err := source.Compact(destination, func(p application.CompactionProgress) error {
+		progress = append(progress, p)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, progress)
+	last := progress[len(progress)-1]
+	require.Equal(t, 1, last.CopiedBuckets)
+	require.Equal(t, 1, last.TotalBuckets)
+
+	require.Less(t, result.SizeAfter, result.SizeBefore)
+
+	compacted, err := boltadapters.NewBolt(destination, true)
+	require.NoError(t, err)
+	defer compacted.Close()
+
+	err = compacted.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("bucket0"))
+		require.NotNil(t, bucket)
+		require.Equal(t, 1000, bucket.Stats().KeyN)
+		require.Equal(t, uint64(1), bucket.Sequence())
+		require.Nil(t, tx.Bucket([]byte("bucket1")))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCompactRefusesToOverwriteFiles(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	source, err := boltadapters.NewSource(file, false)
+	require.NoError(t, err)
+
+	destination := filepath.Join(t.TempDir(), "compacted.db")
+	require.NoError(t, os.WriteFile(destination, []byte("data"), 0600))
+
+	_, err = source.Compact(destination, func(p application.CompactionProgress) error {
+		return nil
+	})
+	require.ErrorIs(t, err, application.ErrDestinationExists)
+
+	b, err := os.ReadFile(destination)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), b)
+}
+
+func TestCompactionDisabled(t *testing.T) {
+	testApp := NewTrackerWithPermissions(t, application.Permissions{})
+
+	_, err := testApp.Application.Compact.Execute(application.MustNewCompact("destination"), func(p application.CompactionProgress) error {
+		return nil
+	})
+	require.ErrorIs(t, err, application.ErrCompactionDisabled)
+	require.Empty(t, testApp.Mocks.Source.Compactions)
+
+	testApp = NewTrackerWithPermissions(t, application.Permissions{Compact: true})
+
+	_, err = testApp.Application.Compact.Execute(application.MustNewCompact("destination"), func(p application.CompactionProgress) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"destination"}, testApp.Mocks.Source.Compactions)
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestSourceDatabaseInfo(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	source, err := boltadapters.NewSource(file, false)
+	require.NoError(t, err)
+
+	err = source.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("bucket"))
+		return err
+	})
+	require.NoError(t, err)
+
+	info, err := source.DatabaseInfo()
+	require.NoError(t, err)
+	require.NotZero(t, info.PageSize)
+	require.NotZero(t, info.FileSize)
+	require.NotZero(t, info.Tx.Write)
+}
+
+func TestGetDatabaseInfo(t *testing.T) {
+	testApp := NewTracker(t)
+
+	testApp.Mocks.Source.DatabaseInfos = []application.DatabaseInfo{
+		{FreePageN: 1},
+		{FreePageN: 2},
+		{FreePageN: 3},
+	}
+
+	require.NoError(t, testApp.Sampler.Sample())
+	require.NoError(t, testApp.Sampler.Sample())
+
+	report, err := testApp.Application.GetDatabaseInfo.Execute(application.GetDatabaseInfo{})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Current.Info.FreePageN)
+	require.Len(t, report.History, 2)
+	require.Equal(t, 1, report.History[0].Info.FreePageN)
+	require.Equal(t, 2, report.History[1].Info.FreePageN)
+}
+
+func TestDatabaseInfoSamplerKeepsLimitedHistory(t *testing.T) {
+	testApp := NewTracker(t)
+
+	testApp.Mocks.Source.DatabaseInfos = []application.DatabaseInfo{{FreePageN: 1}}
+
+	for i := 0; i < application.DatabaseInfoHistoryLength+10; i++ {
+		require.NoError(t, testApp.Sampler.Sample())
+	}
+
+	require.Len(t, testApp.Sampler.Samples(), application.DatabaseInfoHistoryLength)
+}
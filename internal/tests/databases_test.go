@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boreq/bolt-ui/internal/config"
+	httpport "github.com/boreq/bolt-ui/ports/http"
+	"github.com/boreq/rest"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestFindDatabases(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"orders.db", "users.bolt", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.db"), 0700))
+
+	other := filepath.Join(t.TempDir(), "payments.database")
+	require.NoError(t, os.WriteFile(other, nil, 0600))
+
+	databases, err := config.FindDatabases([]string{dir, other})
+	require.NoError(t, err)
+	require.Equal(t,
+		[]config.Database{
+			{Name: "orders", File: filepath.Join(dir, "orders.db")},
+			{Name: "users", File: filepath.Join(dir, "users.bolt")},
+			{Name: "payments", File: other},
+		},
+		databases,
+	)
+
+	_, err = config.FindDatabases([]string{dir, filepath.Join(dir, "orders.db")})
+	require.EqualError(t, err, "files '"+filepath.Join(dir, "orders.db")+"' and '"+filepath.Join(dir, "orders.db")+"' would both be served as 'orders'")
+
+	_, err = config.FindDatabases([]string{t.TempDir()})
+	require.EqualError(t, err, "no databases found")
+
+	_, err = config.FindDatabases([]string{filepath.Join(dir, "missing.db")})
+	require.Error(t, err)
+}
+
+func TestHandlerServesMultipleDatabases(t *testing.T) {
+	first := NewTracker(t)
+	second := NewTracker(t)
+
+	for _, testApp := range []struct {
+		DB     *bbolt.DB
+		Bucket string
+	}{
+		{first.DB, "first"},
+		{second.DB, "second"},
+	} {
+		err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucket([]byte(testApp.Bucket))
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	handler, err := httpport.NewHandler(
+		[]httpport.Database{
+			{Name: "first", Application: first.Application},
+			{Name: "second", Application: second.Application},
+		},
+		httpport.NewTokenAuthProvider(&config.Config{InsecureToken: true}),
+		&config.Config{},
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Path   string
+		Status int
+		Bucket string
+	}{
+		{"/api/browse/", http.StatusOK, "first"},
+		{"/api/db/first/browse/", http.StatusOK, "first"},
+		{"/api/db/second/browse/", http.StatusOK, "second"},
+		{"/api/db/third/browse/", rest.ErrNotFound.StatusCode(), ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, testCase.Path, nil))
+			require.Equal(t, testCase.Status, rec.Code)
+
+			if testCase.Bucket != "" {
+				var tree httpport.Tree
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tree))
+				require.Len(t, tree.Entries, 1)
+				require.Equal(t, testCase.Bucket, tree.Entries[0].Key.Str)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/db", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var databases []httpport.NamedDatabase
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &databases))
+	require.Len(t, databases, 2)
+	require.Equal(t, "first", databases[0].Name)
+	require.Equal(t, "second", databases[1].Name)
+}
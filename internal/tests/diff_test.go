@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestDiff(t *testing.T) {
+	oldFile, oldCleanup := fixture.File(t)
+	t.Cleanup(oldCleanup)
+
+	newFile, newCleanup := fixture.File(t)
+	t.Cleanup(newCleanup)
+
+	update(t, oldFile, func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for _, key := range []string{"unchanged", "modified", "removed"} {
+			if err := bucket.Put([]byte(key), []byte(key)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := bucket.CreateBucket([]byte("removed-bucket")); err != nil {
+			return err
+		}
+
+		nested, err := bucket.CreateBucket([]byte("sequence"))
+		if err != nil {
+			return err
+		}
+
+		if err := nested.SetSequence(1); err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket([]byte("type")); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	update(t, newFile, func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for key, value := range map[string]string{"unchanged": "unchanged", "modified": "changed", "added": "added", "type": "value"} {
+			if err := bucket.Put([]byte(key), []byte(value)); err != nil {
+				return err
+			}
+		}
+
+		nested, err := bucket.CreateBucket([]byte("sequence"))
+		if err != nil {
+			return err
+		}
+
+		if err := nested.SetSequence(2); err != nil {
+			return err
+		}
+
+		added, err := bucket.CreateBucket([]byte("added-bucket"))
+		if err != nil {
+			return err
+		}
+
+		return added.Put([]byte("key"), []byte("value"))
+	})
+
+	source, err := boltadapters.NewSource(oldFile, true)
+	require.NoError(t, err)
+
+	var entries []string
+	err = source.Diff(newFile, nil, func(entry application.DiffEntry) error {
+		entries = append(entries, entry.Kind.String()+" "+formatDiffPath(entry))
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]string{
+			"added bucket/added",
+			"added bucket/added-bucket",
+			"added bucket/added-bucket/key",
+			"modified bucket/modified",
+			"removed bucket/removed",
+			"removed bucket/removed-bucket",
+			"modified bucket/sequence",
+			"modified bucket/type",
+		},
+		entries,
+	)
+}
+
+func TestDiffOfSubtree(t *testing.T) {
+	oldFile, oldCleanup := fixture.File(t)
+	t.Cleanup(oldCleanup)
+
+	newFile, newCleanup := fixture.File(t)
+	t.Cleanup(newCleanup)
+
+	createBucket(t, oldFile, "a")
+	createBucket(t, newFile, "b")
+
+	source, err := boltadapters.NewSource(oldFile, true)
+	require.NoError(t, err)
+
+	var entries []string
+	collect := func(entry application.DiffEntry) error {
+		entries = append(entries, entry.Kind.String()+" "+formatDiffPath(entry))
+		return nil
+	}
+
+	err = source.Diff(newFile, []application.Key{application.MustNewKey([]byte("a"))}, collect)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	err = source.Diff(newFile, []application.Key{application.MustNewKey([]byte("c"))}, collect)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+
+	err = source.Diff("", nil, collect)
+	require.ErrorIs(t, err, application.ErrSnapshotsDisabled)
+}
+
+func TestDiffOfSnapshot(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createBucket(t, file, "a")
+
+	source, err := boltadapters.NewSnapshotSource(file)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, source.Close())
+	})
+
+	createBucket(t, file, "b")
+
+	var entries []string
+	err = source.Diff("", nil, func(entry application.DiffEntry) error {
+		entries = append(entries, entry.Kind.String()+" "+formatDiffPath(entry))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"added b"}, entries)
+}
+
+func TestDiffHandler(t *testing.T) {
+	testApp := NewTrackerWithPermissions(t, application.Permissions{Diff: true})
+
+	key := application.MustNewKey([]byte("key"))
+	testApp.Source.DiffEntries = []application.DiffEntry{
+		{
+			Key:  key,
+			Kind: application.DiffKindModified,
+			Old:  &application.DiffEntryState{Value: application.MustNewValue([]byte(`{"status":"pending","tags":["a"],"removed":1}`))},
+			New:  &application.DiffEntryState{Value: application.MustNewValue([]byte(`{"status":"done","tags":["a","b"]}`))},
+		},
+		{
+			Key:  key,
+			Kind: application.DiffKindModified,
+			Old:  &application.DiffEntryState{Value: application.MustNewValue([]byte("not json"))},
+			New:  &application.DiffEntryState{Value: application.MustNewValue([]byte(`{}`))},
+		},
+	}
+
+	var entries []application.DiffEntry
+	err := testApp.Application.Diff.Execute(context.Background(), application.MustNewDiff("other.db", nil), func(entry application.DiffEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"other.db"}, testApp.Source.Diffs)
+	require.Len(t, entries, 2)
+
+	var differences []string
+	for _, difference := range entries[0].Differences {
+		differences = append(differences, difference.Kind.String()+" "+difference.Path.String())
+	}
+	require.Equal(t,
+		[]string{
+			"removed $.removed",
+			"modified $.status",
+			"added $.tags[1]",
+		},
+		differences,
+	)
+	require.Equal(t, display.DifferenceKindModified, entries[0].Differences[1].Kind)
+	require.Equal(t, "pending", entries[0].Differences[1].Old)
+	require.Equal(t, "done", entries[0].Differences[1].New)
+
+	require.Empty(t, entries[1].Differences)
+}
+
+func TestDiffHandlerRequiresPermission(t *testing.T) {
+	testApp := NewTrackerWithPermissions(t, application.Permissions{})
+
+	noop := func(entry application.DiffEntry) error {
+		return nil
+	}
+
+	err := testApp.Application.Diff.Execute(context.Background(), application.MustNewDiff("other.db", nil), noop)
+	require.ErrorIs(t, err, application.ErrDiffDisabled)
+	require.Empty(t, testApp.Source.Diffs)
+
+	err = testApp.Application.Diff.Execute(context.Background(), application.MustNewDiff("", nil), noop)
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, testApp.Source.Diffs)
+}
+
+func update(t *testing.T, file string, fn func(tx *bbolt.Tx) error) {
+	db, err := boltadapters.NewBolt(file, false)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Update(fn))
+	require.NoError(t, db.Close())
+}
+
+func formatDiffPath(entry application.DiffEntry) string {
+	var parts []string
+	for _, key := range entry.Path {
+		parts = append(parts, string(key.Bytes()))
+	}
+	parts = append(parts, string(entry.Key.Bytes()))
+	return strings.Join(parts, "/")
+}
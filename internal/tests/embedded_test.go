@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boreq/bolt-ui/boltui"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	httpport "github.com/boreq/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestEmbeddedHandler(t *testing.T) {
+	db, cleanup := fixture.Bolt(t)
+	t.Cleanup(cleanup)
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("bucket"))
+		return err
+	})
+	require.NoError(t, err)
+
+	auth := boltui.AuthProviderFunc(func(r *http.Request) (bool, error) {
+		return r.Header.Get("X-Allowed") != "", nil
+	})
+
+	handler, err := boltui.NewHandler(db, auth, boltui.Options{Prefix: "/debug/bolt/"})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/bolt/", handler)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("X-Allowed", "true")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := request(http.MethodGet, "/debug/bolt/api/browse/")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var tree httpport.Tree
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tree))
+	require.Len(t, tree.Entries, 1)
+	require.Equal(t, "bucket", tree.Entries[0].Key.Str)
+
+	rec = request(http.MethodPost, "/debug/bolt/api/buckets/"+hex.EncodeToString([]byte("other")))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/bolt/api/browse/", nil))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	for _, path := range []string{"/debug/bolt/", "/debug/bolt/6275636b6574"} {
+		rec = request(http.MethodGet, path)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `<base href="/debug/bolt/">`)
+	}
+
+	assets := regexp.MustCompile(`<(?:link|script)[^>]* (?:href|src)="([^"]+)"`).FindAllStringSubmatch(rec.Body.String(), -1)
+	require.NotEmpty(t, assets)
+
+	for _, asset := range assets {
+		if strings.HasPrefix(asset[1], "https://") {
+			continue
+		}
+
+		require.False(t, strings.HasPrefix(asset[1], "/"), "asset path '%s' isn't relative", asset[1])
+
+		rec := request(http.MethodGet, "/debug/bolt/"+asset[1])
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, strings.Contains(rec.Body.String(), "<!DOCTYPE html>"), "asset '%s' wasn't found", asset[1])
+		require.False(t, strings.Contains(rec.Body.String(), `"/api/`), "asset '%s' uses an absolute API path", asset[1])
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/debug/bolt/"+assets[len(assets)-1][1], nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, r)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+}
+
+func TestEmbeddedHandlerIsReadOnlyByDefault(t *testing.T) {
+	db, cleanup := fixture.Bolt(t)
+	t.Cleanup(cleanup)
+
+	auth := boltui.AuthProviderFunc(func(r *http.Request) (bool, error) {
+		return true, nil
+	})
+
+	handler, err := boltui.NewHandler(db, auth, boltui.Options{})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/buckets/"+hex.EncodeToString([]byte("bucket")), nil))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket([]byte("bucket")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = boltui.NewHandler(db, auth, boltui.Options{PageSize: -1})
+	require.Error(t, err)
+
+	_, err = boltui.NewHandler(db, auth, boltui.Options{Prefix: "debug"})
+	require.Error(t, err)
+}
+
+func TestEmbeddedHandlerWithWritesEnabled(t *testing.T) {
+	db, cleanup := fixture.Bolt(t)
+	t.Cleanup(cleanup)
+
+	auth := boltui.AuthProviderFunc(func(r *http.Request) (bool, error) {
+		return true, nil
+	})
+
+	handler, err := boltui.NewHandler(db, auth, boltui.Options{EnableWrites: true})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/buckets/"+hex.EncodeToString([]byte("bucket")), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket([]byte("bucket")))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestEmbeddedHandlerRecordsDatabaseInfo(t *testing.T) {
+	db, cleanup := fixture.Bolt(t)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	auth := boltui.AuthProviderFunc(func(r *http.Request) (bool, error) {
+		return true, nil
+	})
+
+	handler, err := boltui.NewHandler(db, auth, boltui.Options{Context: ctx})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/database", nil))
+		if rec.Code != http.StatusOK {
+			return false
+		}
+
+		var report httpport.DatabaseInfoReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			return false
+		}
+		return len(report.History) > 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestExport(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		a, err := tx.CreateBucket([]byte("a"))
+		if err != nil {
+			return err
+		}
+		if err := a.SetSequence(1); err != nil {
+			return err
+		}
+		if err := a.Put([]byte("key1"), []byte("value1")); err != nil {
+			return err
+		}
+
+		nested, err := a.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		if err := nested.SetSequence(2); err != nil {
+			return err
+		}
+		if err := nested.Put([]byte("key2"), []byte("value2")); err != nil {
+			return err
+		}
+
+		if err := a.Put([]byte("key3"), nil); err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket([]byte("b"))
+		return err
+	})
+	require.NoError(t, err)
+
+	keyA := application.MustNewKey([]byte("a"))
+	keyB := application.MustNewKey([]byte("b"))
+	keyNested := application.MustNewKey([]byte("nested"))
+
+	bucketA := application.ExportedEntry{
+		Path:     nil,
+		Entry:    application.Entry{Bucket: true, Key: keyA, Value: application.MustNewValue(nil)},
+		Sequence: 1,
+	}
+	key1 := application.ExportedEntry{
+		Path:  []application.Key{keyA},
+		Entry: application.Entry{Key: application.MustNewKey([]byte("key1")), Value: application.MustNewValue([]byte("value1"))},
+	}
+	key3 := application.ExportedEntry{
+		Path:  []application.Key{keyA},
+		Entry: application.Entry{Key: application.MustNewKey([]byte("key3")), Value: application.MustNewValue(nil)},
+	}
+	bucketNested := application.ExportedEntry{
+		Path:     []application.Key{keyA},
+		Entry:    application.Entry{Bucket: true, Key: keyNested, Value: application.MustNewValue(nil)},
+		Sequence: 2,
+	}
+	key2 := application.ExportedEntry{
+		Path:  []application.Key{keyA, keyNested},
+		Entry: application.Entry{Key: application.MustNewKey([]byte("key2")), Value: application.MustNewValue([]byte("value2"))},
+	}
+	bucketB := application.ExportedEntry{
+		Path:  nil,
+		Entry: application.Entry{Bucket: true, Key: keyB, Value: application.MustNewValue(nil)},
+	}
+
+	testCases := []struct {
+		Name   string
+		Path   []application.Key
+		Result []application.ExportedEntry
+	}{
+		{
+			Name:   "database",
+			Path:   nil,
+			Result: []application.ExportedEntry{bucketA, key1, key3, bucketNested, key2, bucketB},
+		},
+		{
+			Name: "bucket",
+			Path: []application.Key{keyA, keyNested},
+			Result: []application.ExportedEntry{
+				{
+					Path:     []application.Key{keyA},
+					Entry:    application.Entry{Bucket: true, Key: keyNested},
+					Sequence: 2,
+				},
+				key2,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var result []application.ExportedEntry
+			err := testApp.Application.Export.Execute(context.Background(), application.MustNewExport(testCase.Path), func(entry application.ExportedEntry) error {
+				result = append(result, entry)
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, testCase.Result, result)
+		})
+	}
+}
+
+func TestExportMissingBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	query := application.MustNewExport([]application.Key{application.MustNewKey([]byte("missing"))})
+	err := testApp.Application.Export.Execute(context.Background(), query, func(entry application.ExportedEntry) error {
+		return nil
+	})
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
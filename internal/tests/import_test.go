@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/wire"
+	"github.com/boreq/bolt-ui/jsonlines"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestImportRoundTrip(t *testing.T) {
+	source := NewTracker(t)
+
+	err := source.DB.Update(func(tx *bbolt.Tx) error {
+		a, err := tx.CreateBucket([]byte("a"))
+		if err != nil {
+			return err
+		}
+		if err := a.SetSequence(10); err != nil {
+			return err
+		}
+		if err := a.Put([]byte("key1"), []byte(`{"some":"json"}`)); err != nil {
+			return err
+		}
+		if err := a.Put([]byte("key2"), nil); err != nil {
+			return err
+		}
+
+		nested, err := a.CreateBucket([]byte{0xff, 0x00})
+		if err != nil {
+			return err
+		}
+		if err := nested.SetSequence(20); err != nil {
+			return err
+		}
+		if err := nested.Put([]byte{0x00}, []byte{0x01, 0x02}); err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket([]byte("empty"))
+		return err
+	})
+	require.NoError(t, err)
+
+	for _, encoding := range []jsonlines.Encoding{jsonlines.EncodingHex, jsonlines.EncodingBase64} {
+		exported := export(t, source, nil, encoding)
+
+		destination := NewTracker(t)
+
+		result, err := destination.Application.Import.Execute(
+			context.Background(),
+			application.MustNewImport(nil, false, 2),
+			jsonlines.NewDecoder(strings.NewReader(exported)),
+			func(change application.ImportChange) error { return nil },
+		)
+		require.NoError(t, err)
+		require.Equal(t, application.ImportResult{Created: 6}, result)
+
+		require.Equal(t, export(t, source, nil, jsonlines.EncodingHex), export(t, destination, nil, jsonlines.EncodingHex))
+	}
+}
+
+func TestImportIntoBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	input := `{"path":[{"hex":"61"}],"key":{"hex":"6b6579"},"value":{"hex":"76616c7565"}}` + "\n"
+
+	target := []application.Key{
+		application.MustNewKey([]byte("target")),
+		application.MustNewKey([]byte("nested")),
+	}
+
+	result, err := testApp.Application.Import.Execute(
+		context.Background(),
+		application.MustNewImport(target, false, application.DefaultImportBatchSize),
+		jsonlines.NewDecoder(strings.NewReader(input)),
+		func(change application.ImportChange) error { return nil },
+	)
+	require.NoError(t, err)
+	require.Equal(t, application.ImportResult{Created: 1}, result)
+
+	err = testApp.DB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("target")).Bucket([]byte("nested")).Bucket([]byte("a"))
+		require.NotNil(t, bucket)
+		require.Equal(t, []byte("value"), bucket.Get([]byte("key")))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestImportDryRun(t *testing.T) {
+	testApp := NewTrackerWithPermissions(t, application.Permissions{})
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		a, err := tx.CreateBucket([]byte("a"))
+		if err != nil {
+			return err
+		}
+		if err := a.SetSequence(1); err != nil {
+			return err
+		}
+		if err := a.Put([]byte("unchanged"), []byte("value")); err != nil {
+			return err
+		}
+		return a.Put([]byte("updated"), []byte("old"))
+	})
+	require.NoError(t, err)
+
+	before := export(t, testApp, nil, jsonlines.EncodingHex)
+
+	input := strings.Join([]string{
+		`{"path":[],"key":{"hex":"61"},"bucket":true,"sequence":2}`,
+		`{"path":[{"hex":"61"}],"key":{"hex":"756e6368616e676564"},"value":{"hex":"76616c7565"}}`,
+		`{"path":[{"hex":"61"}],"key":{"hex":"75706461746564"},"value":{"hex":"6e6577"}}`,
+		`{"path":[{"hex":"61"}],"key":{"hex":"63726561746564"},"value":{"hex":"6e6577"}}`,
+		`{"path":[{"hex":"61"}],"key":{"hex":"62"},"bucket":true,"sequence":0}`,
+		`{"path":[{"hex":"61"},{"hex":"62"}],"key":{"hex":"6b6579"},"value":{"hex":"6e6577"}}`,
+	}, "\n")
+
+	var kinds []application.ChangeKind
+	result, err := testApp.Application.Import.Execute(
+		context.Background(),
+		application.MustNewImport(nil, true, 2),
+		jsonlines.NewDecoder(strings.NewReader(input)),
+		func(change application.ImportChange) error {
+			kinds = append(kinds, change.Kind)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, application.ImportResult{Created: 3, Updated: 2, Unchanged: 1}, result)
+	require.Equal(t, []application.ChangeKind{
+		application.ChangeKindUpdated,
+		application.ChangeKindUnchanged,
+		application.ChangeKindUpdated,
+		application.ChangeKindCreated,
+		application.ChangeKindCreated,
+		application.ChangeKindCreated,
+	}, kinds)
+
+	require.Equal(t, before, export(t, testApp, nil, jsonlines.EncodingHex))
+}
+
+func TestImportWritesDisabled(t *testing.T) {
+	testApp := NewTrackerWithPermissions(t, application.Permissions{})
+
+	_, err := testApp.Application.Import.Execute(
+		context.Background(),
+		application.MustNewImport(nil, false, application.DefaultImportBatchSize),
+		jsonlines.NewDecoder(strings.NewReader("")),
+		func(change application.ImportChange) error { return nil },
+	)
+	require.ErrorIs(t, err, application.ErrWritesDisabled)
+}
+
+func TestImportInvalidEntries(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Input string
+		Err   error
+	}{
+		{
+			Name:  "value_in_root",
+			Input: `{"path":[],"key":{"hex":"6b6579"},"value":{"hex":"00"}}`,
+			Err:   application.ErrInvalidEntry,
+		},
+		{
+			Name:  "malformed",
+			Input: `{"path":`,
+			Err:   application.ErrInvalidEntry,
+		},
+		{
+			Name: "bucket_replacing_value",
+			Input: strings.Join([]string{
+				`{"path":[{"hex":"61"}],"key":{"hex":"6b6579"},"value":{"hex":"00"}}`,
+				`{"path":[{"hex":"61"}],"key":{"hex":"6b6579"},"bucket":true}`,
+			}, "\n"),
+			Err: application.ErrKeyAlreadyExists,
+		},
+		{
+			Name: "value_replacing_bucket",
+			Input: strings.Join([]string{
+				`{"path":[{"hex":"61"}],"key":{"hex":"6b6579"},"bucket":true}`,
+				`{"path":[{"hex":"61"}],"key":{"hex":"6b6579"},"value":{"hex":"00"}}`,
+			}, "\n"),
+			Err: application.ErrKeyIsABucket,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			testApp := NewTracker(t)
+
+			_, err := testApp.Application.Import.Execute(
+				context.Background(),
+				application.MustNewImport(nil, false, application.DefaultImportBatchSize),
+				jsonlines.NewDecoder(strings.NewReader(testCase.Input)),
+				func(change application.ImportChange) error { return nil },
+			)
+			require.ErrorIs(t, err, testCase.Err)
+		})
+	}
+}
+
+func export(t *testing.T, testApp wire.TestApplication, path []application.Key, encoding jsonlines.Encoding) string {
+	buf := &bytes.Buffer{}
+	encoder := jsonlines.NewEncoder(buf, encoding)
+
+	err := testApp.Application.Export.Execute(context.Background(), application.MustNewExport(path), func(entry application.ExportedEntry) error {
+		return encoder.Encode(entry)
+	})
+	require.NoError(t, err)
+
+	return buf.String()
+}
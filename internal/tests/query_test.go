@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/jsonpath"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestQuery(t *testing.T) {
+	testApp := NewTracker(t)
+
+	cborValue, err := cbor.Marshal(map[string]interface{}{
+		"id":     3,
+		"status": "failed",
+	})
+	require.NoError(t, err)
+
+	err = testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("jobs"))
+		if err != nil {
+			return err
+		}
+
+		values := map[string][]byte{
+			"job1": []byte(`{"id": 1, "status": "failed"}`),
+			"job2": []byte(`{"id": 2, "status": "done"}`),
+			"job3": cborValue,
+			"job4": []byte{0xff, 0xfe},
+		}
+
+		for key, value := range values {
+			if err := bucket.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey([]byte("jobs")),
+	}
+
+	filter := jsonpath.MustParseExpression(`$.status == "failed"`)
+	fields := []jsonpath.Path{
+		jsonpath.MustParsePath("$.id"),
+		jsonpath.MustParsePath("$.missing"),
+	}
+
+	var keys []string
+	var values [][]interface{}
+
+	err = testApp.Application.Query.Execute(
+		context.Background(),
+		application.MustNewQuery(path, &filter, fields, false, 10),
+		func(result application.QueryResult) error {
+			keys = append(keys, string(result.Key.Bytes()))
+			values = append(values, result.Values)
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"job1", "job3"}, keys)
+	require.Equal(t,
+		[][]interface{}{
+			{json.Number("1"), nil},
+			{uint64(3), nil},
+		},
+		values,
+	)
+
+	keys = nil
+
+	err = testApp.Application.Query.Execute(
+		context.Background(),
+		application.MustNewQuery(path, nil, nil, false, 2),
+		func(result application.QueryResult) error {
+			keys = append(keys, string(result.Key.Bytes()))
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"job1", "job2"}, keys)
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestSearch(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		users, err := tx.CreateBucket([]byte("users"))
+		if err != nil {
+			return err
+		}
+
+		if err := users.Put([]byte("user:1"), []byte(`{"email":"alice@example.com"}`)); err != nil {
+			return err
+		}
+
+		if err := users.Put([]byte("user:2"), []byte(`{"email":"bob@example.com"}`)); err != nil {
+			return err
+		}
+
+		archived, err := users.CreateBucket([]byte("archived"))
+		if err != nil {
+			return err
+		}
+
+		if err := archived.Put([]byte("user:3"), []byte(`{"email":"carol@example.com"}`)); err != nil {
+			return err
+		}
+
+		return archived.Put([]byte{0xde, 0xad, 0xbe, 0xef}, []byte{0x00, 0x01})
+	})
+	require.NoError(t, err)
+
+	usersPath := []application.Key{
+		application.MustNewKey([]byte("users")),
+	}
+
+	archivedPath := []application.Key{
+		application.MustNewKey([]byte("users")),
+		application.MustNewKey([]byte("archived")),
+	}
+
+	substring := func(s string) application.Matcher {
+		m, err := application.NewSubstringMatcher(s)
+		require.NoError(t, err)
+		return m
+	}
+
+	testCases := []struct {
+		Name          string
+		Search        application.Search
+		ExpectedPaths [][]application.Key
+		ExpectedKeys  []string
+	}{
+		{
+			Name:          "values",
+			Search:        application.MustNewSearch(usersPath, substring("example.com"), false, true, false, 10),
+			ExpectedPaths: [][]application.Key{usersPath, usersPath},
+			ExpectedKeys:  []string{"user:1", "user:2"},
+		},
+		{
+			Name:          "values_recursive",
+			Search:        application.MustNewSearch(usersPath, substring("example.com"), false, true, true, 10),
+			ExpectedPaths: [][]application.Key{archivedPath, usersPath, usersPath},
+			ExpectedKeys:  []string{"user:3", "user:1", "user:2"},
+		},
+		{
+			Name:          "values_do_not_match_keys",
+			Search:        application.MustNewSearch(usersPath, substring("user:"), false, true, true, 10),
+			ExpectedPaths: nil,
+			ExpectedKeys:  nil,
+		},
+		{
+			Name:          "keys",
+			Search:        application.MustNewSearch(nil, substring("arch"), true, false, true, 10),
+			ExpectedPaths: [][]application.Key{usersPath},
+			ExpectedKeys:  []string{"archived"},
+		},
+		{
+			Name: "regexp",
+			Search: application.MustNewSearch(usersPath, func() application.Matcher {
+				m, err := application.NewRegexpMatcher(`"email":"(alice|carol)@`)
+				require.NoError(t, err)
+				return m
+			}(), true, true, true, 10),
+			ExpectedPaths: [][]application.Key{archivedPath, usersPath},
+			ExpectedKeys:  []string{"user:3", "user:1"},
+		},
+		{
+			Name: "bytes",
+			Search: application.MustNewSearch(usersPath, func() application.Matcher {
+				m, err := application.NewBytesMatcher([]byte{0xbe, 0xef})
+				require.NoError(t, err)
+				return m
+			}(), true, true, true, 10),
+			ExpectedPaths: [][]application.Key{archivedPath},
+			ExpectedKeys:  []string{string([]byte{0xde, 0xad, 0xbe, 0xef})},
+		},
+		{
+			Name:          "limit",
+			Search:        application.MustNewSearch(usersPath, substring("example.com"), false, true, true, 2),
+			ExpectedPaths: [][]application.Key{archivedPath, usersPath},
+			ExpectedKeys:  []string{"user:3", "user:1"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var paths [][]application.Key
+			var keys []string
+
+			err := testApp.Application.Search.Execute(context.Background(), testCase.Search, func(result application.SearchResult) error {
+				paths = append(paths, result.Path)
+				keys = append(keys, string(result.Entry.Key.Bytes()))
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedPaths, paths)
+			require.Equal(t, testCase.ExpectedKeys, keys)
+		})
+	}
+}
+
+func TestSearchCancelled(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("bucket"))
+		return err
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	matcher, err := application.NewSubstringMatcher("bucket")
+	require.NoError(t, err)
+
+	err = testApp.Application.Search.Execute(ctx, application.MustNewSearch(nil, matcher, true, true, true, 10), func(result application.SearchResult) error {
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSearchBucketNotFound(t *testing.T) {
+	testApp := NewTracker(t)
+
+	matcher, err := application.NewSubstringMatcher("a")
+	require.NoError(t, err)
+
+	path := []application.Key{
+		application.MustNewKey([]byte("missing")),
+	}
+
+	err = testApp.Application.Search.Execute(context.Background(), application.MustNewSearch(path, matcher, true, true, true, 10), func(result application.SearchResult) error {
+		return nil
+	})
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestSnapshotSource(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createBucket(t, file, "a")
+
+	source, err := boltadapters.NewSnapshotSource(file)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, source.Close())
+	})
+
+	createBucket(t, file, "b")
+
+	err = source.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket([]byte("a")))
+		require.Nil(t, tx.Bucket([]byte("b")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = source.Update(func(tx *bbolt.Tx) error {
+		return nil
+	})
+	require.ErrorIs(t, err, bbolt.ErrDatabaseReadOnly)
+
+	info := source.Info()
+	require.True(t, info.SnapshotMode)
+
+	err = source.TakeSnapshot()
+	require.NoError(t, err)
+
+	err = source.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket([]byte("a")))
+		require.NotNil(t, tx.Bucket([]byte("b")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.True(t, source.Info().OpenedAt.After(info.OpenedAt))
+}
+
+func TestSnapshotSourceCloseRemovesTheSnapshot(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createBucket(t, file, "a")
+
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	source, err := boltadapters.NewSnapshotSource(file)
+	require.NoError(t, err)
+
+	require.NoError(t, source.TakeSnapshot())
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, source.Close())
+
+	entries, err = os.ReadDir(tmp)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSourceWithoutSnapshots(t *testing.T) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	source, err := boltadapters.NewSource(file, false)
+	require.NoError(t, err)
+
+	require.False(t, source.Info().SnapshotMode)
+
+	err = source.TakeSnapshot()
+	require.ErrorIs(t, err, application.ErrSnapshotsDisabled)
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.Application.TakeSnapshot.Execute(application.TakeSnapshot{})
+	require.ErrorIs(t, err, application.ErrSnapshotsDisabled)
+
+	testApp.Mocks.Source.SourceInfo.SnapshotMode = true
+
+	err = testApp.Application.TakeSnapshot.Execute(application.TakeSnapshot{})
+	require.NoError(t, err)
+	require.Equal(t, 1, testApp.Mocks.Source.SnapshotsTaken)
+
+	info, err := testApp.Application.GetSourceInfo.Execute(application.GetSourceInfo{})
+	require.NoError(t, err)
+	require.True(t, info.SnapshotMode)
+}
+
+func createBucket(t *testing.T, file string, name string) {
+	db, err := boltadapters.NewBolt(file, false)
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucket([]byte(name))
+		return err
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Close())
+}
+
+func TestSourceReopen(t *testing.T) {
+	for _, readOnly := range []bool{true, false} {
+		t.Run(fmt.Sprintf("read_only=%t", readOnly), func(t *testing.T) {
+			testSourceReopen(t, readOnly)
+		})
+	}
+}
+
+func testSourceReopen(t *testing.T, readOnly bool) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createBucket(t, file, "a")
+
+	source, err := boltadapters.NewSource(file, readOnly)
+	require.NoError(t, err)
+	require.True(t, source.Info().ReopenedAt.IsZero())
+
+	replaceFile(t, file, "b")
+
+	txStarted := make(chan struct{})
+	finishTx := make(chan struct{})
+	txFinished := make(chan error)
+
+	go func() {
+		txFinished <- source.View(func(tx *bbolt.Tx) error {
+			close(txStarted)
+			<-finishTx
+			if tx.Bucket([]byte("a")) == nil {
+				return errors.New("the old database should be used")
+			}
+			return nil
+		})
+	}()
+
+	<-txStarted
+
+	reopened := make(chan error)
+	go func() {
+		reopened <- source.Reopen()
+	}()
+
+	select {
+	case <-reopened:
+		t.Fatal("reopen should wait for the open transactions")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(finishTx)
+	require.NoError(t, <-txFinished)
+	require.NoError(t, <-reopened)
+
+	err = source.View(func(tx *bbolt.Tx) error {
+		require.Nil(t, tx.Bucket([]byte("a")))
+		require.NotNil(t, tx.Bucket([]byte("b")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	info := source.Info()
+	require.False(t, info.ReopenedAt.IsZero())
+	require.Equal(t, info.OpenedAt, info.ReopenedAt)
+}
+
+func TestSourceReopenWithoutReplacingTheFile(t *testing.T) {
+	for _, readOnly := range []bool{true, false} {
+		t.Run(fmt.Sprintf("read_only=%t", readOnly), func(t *testing.T) {
+			file, cleanup := fixture.File(t)
+			t.Cleanup(cleanup)
+
+			createBucket(t, file, "a")
+
+			source, err := boltadapters.NewSource(file, readOnly)
+			require.NoError(t, err)
+
+			require.NoError(t, source.Reopen())
+			require.True(t, source.Info().ReopenedAt.IsZero())
+
+			err = source.View(func(tx *bbolt.Tx) error {
+				require.NotNil(t, tx.Bucket([]byte("a")))
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSourceWatch(t *testing.T) {
+	for _, readOnly := range []bool{true, false} {
+		t.Run(fmt.Sprintf("read_only=%t", readOnly), func(t *testing.T) {
+			testSourceWatch(t, readOnly)
+		})
+	}
+}
+
+func testSourceWatch(t *testing.T, readOnly bool) {
+	file, cleanup := fixture.File(t)
+	t.Cleanup(cleanup)
+
+	createBucket(t, file, "a")
+
+	source, err := boltadapters.NewSource(file, readOnly)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go source.Watch(ctx)
+
+	replaceFile(t, file, "b")
+
+	require.Eventually(t, func() bool {
+		return !source.Info().ReopenedAt.IsZero()
+	}, 5*boltadapters.WatchInterval, 10*time.Millisecond)
+
+	err = source.View(func(tx *bbolt.Tx) error {
+		require.NotNil(t, tx.Bucket([]byte("b")))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestReopen(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.Application.Reopen.Execute(application.Reopen{})
+	require.NoError(t, err)
+	require.Equal(t, 1, testApp.Mocks.Source.Reopens)
+
+	info, err := testApp.Application.GetSourceInfo.Execute(application.GetSourceInfo{})
+	require.NoError(t, err)
+	require.False(t, info.ReopenedAt.IsZero())
+}
+
+// replaceFile atomically replaces the file with a new database containing a
+// single bucket.
+func replaceFile(t *testing.T, file string, name string) {
+	replacement := filepath.Join(t.TempDir(), "replacement.db")
+
+	db, err := boltadapters.NewDestinationBolt(replacement)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	createBucket(t, replacement, name)
+
+	require.NoError(t, os.Rename(replacement, file))
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestStats(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		for i, name := range []string{"a", "b"} {
+			bucket, err := tx.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+
+			for j := 0; j < (i+1)*10; j++ {
+				if err := bucket.Put([]byte(fmt.Sprintf("key%d", j)), []byte("value")); err != nil {
+					return err
+				}
+			}
+
+			if _, err := bucket.CreateBucket([]byte("nested")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	stats, err := testApp.Application.GetBucketStats.Execute(
+		application.MustNewGetBucketStats([]application.Key{application.MustNewKey([]byte("a"))}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, 11, stats.KeyN)
+	require.Equal(t, 2, stats.BucketN)
+
+	_, err = testApp.Application.GetBucketStats.Execute(
+		application.MustNewGetBucketStats([]application.Key{application.MustNewKey([]byte("missing"))}),
+	)
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+
+	databaseStats, err := testApp.Application.GetDatabaseStats.Execute(application.GetDatabaseStats{})
+	require.NoError(t, err)
+	require.Len(t, databaseStats.Buckets, 2)
+	require.Equal(t, application.MustNewKey([]byte("a")), databaseStats.Buckets[0].Key)
+	require.Equal(t, 11, databaseStats.Buckets[0].Stats.KeyN)
+	require.Equal(t, application.MustNewKey([]byte("b")), databaseStats.Buckets[1].Key)
+	require.Equal(t, 21, databaseStats.Buckets[1].Stats.KeyN)
+	require.Equal(t, 32, databaseStats.Total.KeyN)
+	require.Equal(t, 4, databaseStats.Total.BucketN)
+}
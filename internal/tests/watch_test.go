@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
+	httpport "github.com/boreq/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestWatchBucket(t *testing.T) {
+	testApp := NewTracker(t)
+
+	err := testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+
+		for _, key := range []string{"modified", "removed", "unchanged"} {
+			if err := bucket.Put([]byte(key), []byte("value")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := make(chan []application.BucketChange)
+	done := make(chan error)
+
+	go func() {
+		path := []application.Key{application.MustNewKey([]byte("bucket"))}
+		done <- testApp.Application.WatchBucket.Execute(ctx, application.MustNewWatchBucket(path, application.MinWatchInterval), func(changes []application.BucketChange) error {
+			batches <- changes
+			return nil
+		})
+	}()
+
+	require.Empty(t, <-batches)
+
+	err = testApp.DB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("bucket"))
+
+		if err := bucket.Put([]byte("added"), []byte("value")); err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte("modified"), []byte("new value")); err != nil {
+			return err
+		}
+
+		return bucket.Delete([]byte("removed"))
+	})
+	require.NoError(t, err)
+
+	changes := <-batches
+
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Kind.String()+" "+string(change.Entry.Key.Bytes())+" "+string(change.Entry.Value.Bytes()))
+	}
+	require.Equal(t,
+		[]string{
+			"added added value",
+			"modified modified new value",
+			"removed removed ",
+		},
+		result,
+	)
+
+	err = testApp.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.DeleteBucket([]byte("bucket"))
+	})
+	require.NoError(t, err)
+
+	require.Len(t, <-batches, 3)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchBucketNotFound(t *testing.T) {
+	testApp := NewTracker(t)
+
+	path := []application.Key{application.MustNewKey([]byte("bucket"))}
+	err := testApp.Application.WatchBucket.Execute(context.Background(), application.MustNewWatchBucket(path, application.MinWatchInterval), func(changes []application.BucketChange) error {
+		return nil
+	})
+	require.ErrorIs(t, err, application.ErrBucketNotFound)
+}
+
+func TestNewWatchBucket(t *testing.T) {
+	_, err := application.NewWatchBucket(nil, time.Millisecond)
+	require.Error(t, err)
+
+	_, err = application.NewWatchBucket(nil, application.DefaultWatchInterval)
+	require.NoError(t, err)
+}
+
+func TestTokenQueryParameterIsOnlyAcceptedByWatch(t *testing.T) {
+	testApp := NewTracker(t)
+
+	handler, err := httpport.NewHandler(
+		[]httpport.Database{
+			{Name: "database", Application: testApp.Application},
+		},
+		httpport.NewTokenAuthProvider(&config.Config{Token: "secret"}),
+		&config.Config{},
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name      string
+		Target    string
+		Header    string
+		Forbidden bool
+	}{
+		{Name: "watch_header", Target: "/api/watch/", Header: "secret", Forbidden: false},
+		{Name: "watch_query", Target: "/api/watch/?token=secret", Forbidden: false},
+		{Name: "watch_invalid_query", Target: "/api/watch/?token=invalid", Forbidden: true},
+		{Name: "watch_invalid_header", Target: "/api/watch/?token=secret", Header: "invalid", Forbidden: true},
+		{Name: "watch_missing", Target: "/api/watch/", Forbidden: true},
+		{Name: "browse_header", Target: "/api/browse/", Header: "secret", Forbidden: false},
+		{Name: "browse_query", Target: "/api/browse/?token=secret", Forbidden: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			// the watch never ends unless the context is cancelled
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			r := httptest.NewRequest(http.MethodGet, testCase.Target, nil).WithContext(ctx)
+			if testCase.Header != "" {
+				r.Header.Set("Access-Token", testCase.Header)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			require.Equal(t, testCase.Forbidden, rec.Code == http.StatusForbidden, "status code %d", rec.Code)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package wire
 import (
 	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
 	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/mocks"
 	"github.com/google/wire"
 	bolt "go.etcd.io/bbolt"
 )
@@ -23,6 +24,9 @@ var testAdaptersSet = wire.NewSet(
 
 	newTestAdaptersProvider,
 	wire.Bind(new(boltadapters.AdaptersProvider), new(*testAdaptersProvider)),
+
+	mocks.NewSourceMock,
+	wire.Bind(new(application.Source), new(*mocks.SourceMock)),
 )
 
 //lint:ignore U1000 because
@@ -2,6 +2,7 @@ package wire
 
 import (
 	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/config"
 	"github.com/google/wire"
 )
 
@@ -9,4 +10,33 @@ import (
 var appSet = wire.NewSet(
 	wire.Struct(new(application.Application), "*"),
 	application.NewBrowseHandler,
+	application.NewPutHandler,
+	application.NewDeleteHandler,
+	application.NewCreateBucketHandler,
+	application.NewDeleteBucketHandler,
+	application.NewMoveBucketHandler,
+	application.NewGetSourceInfoHandler,
+	application.NewTakeSnapshotHandler,
+	application.NewReopenHandler,
+	application.NewSearchHandler,
+	application.NewQueryHandler,
+	application.NewGetBucketStatsHandler,
+	application.NewGetDatabaseStatsHandler,
+	application.NewGetDatabaseInfoHandler,
+	application.NewDatabaseInfoSampler,
+	application.NewCheckConsistencyHandler,
+	application.NewCompactHandler,
+	application.NewBackupHandler,
+	application.NewExportHandler,
+	application.NewImportHandler,
+	application.NewDiffHandler,
+	application.NewWatchBucketHandler,
 )
+
+func newPermissions(conf *config.Config) application.Permissions {
+	return application.Permissions{
+		Write:   conf.EnableWrites && !conf.ReadOnly && !conf.Snapshot,
+		Compact: conf.EnableCompaction,
+		Diff:    conf.EnableDiff,
+	}
+}
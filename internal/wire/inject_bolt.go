@@ -2,16 +2,48 @@ package wire
 
 import (
 	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
+	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/logging"
 	"github.com/google/wire"
-	bolt "go.etcd.io/bbolt"
 )
 
+var log = logging.New("internal/wire")
+
 //lint:ignore U1000 because
 var boltSet = wire.NewSet(
-	newBolt,
+	newSource,
+	wire.Bind(new(boltadapters.DB), new(*boltadapters.Source)),
+	wire.Bind(new(application.Source), new(*boltadapters.Source)),
+)
+
+//lint:ignore U1000 because
+var externalBoltSet = wire.NewSet(
+	boltadapters.NewExternalSource,
+	wire.Bind(new(boltadapters.DB), new(*boltadapters.Source)),
+	wire.Bind(new(application.Source), new(*boltadapters.Source)),
 )
 
-func newBolt(conf *config.Config) (*bolt.DB, error) {
-	return boltadapters.NewBolt(conf.DatabaseFile)
+// newSource opens the database. The returned cleanup function closes it so
+// that the file lock is released and the snapshot is removed.
+func newSource(conf *config.Config, database config.Database) (*boltadapters.Source, func(), error) {
+	source, err := openSource(conf, database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		if err := source.Close(); err != nil {
+			log.Error("could not close the database", "file", database.File, "err", err)
+		}
+	}
+
+	return source, cleanup, nil
+}
+
+func openSource(conf *config.Config, database config.Database) (*boltadapters.Source, error) {
+	if conf.Snapshot {
+		return boltadapters.NewSnapshotSource(database.File)
+	}
+	return boltadapters.NewSource(database.File, conf.ReadOnly)
 }
@@ -3,6 +3,8 @@ package wire
 import (
 	"net/http"
 
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/internal/service"
 	httpport "github.com/boreq/bolt-ui/ports/http"
 	"github.com/google/wire"
 )
@@ -12,6 +14,27 @@ var httpSet = wire.NewSet(
 	httpport.NewServer,
 	httpport.NewHandler,
 	httpport.NewTokenAuthProvider,
+	newHTTPDatabases,
 	wire.Bind(new(http.Handler), new(*httpport.Handler)),
 	wire.Bind(new(httpport.AuthProvider), new(*httpport.TokenAuthProvider)),
 )
+
+func newHTTPDatabases(databases []service.Database) []httpport.Database {
+	var result []httpport.Database
+	for _, database := range databases {
+		result = append(result, httpport.Database{
+			Name:        database.Name,
+			Application: database.Application,
+		})
+	}
+	return result
+}
+
+func newExternalDatabases(app *application.Application) []httpport.Database {
+	return []httpport.Database{
+		{
+			Name:        "default",
+			Application: app,
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package wire
+
+import (
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/service"
+	"github.com/boreq/errors"
+)
+
+// newDatabases builds a separate application for each served database. The
+// returned cleanup function closes all of them.
+func newDatabases(conf *config.Config) ([]service.Database, func(), error) {
+	var databases []service.Database
+	var cleanups []func()
+
+	cleanup := func() {
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+	}
+
+	for _, database := range conf.Databases {
+		built, databaseCleanup, err := BuildDatabase(conf, database)
+		if err != nil {
+			cleanup()
+			return nil, nil, errors.Wrapf(err, "could not build database '%s'", database.Name)
+		}
+
+		built.Name = database.Name
+		databases = append(databases, *built)
+		cleanups = append(cleanups, databaseCleanup)
+	}
+
+	return databases, cleanup, nil
+}
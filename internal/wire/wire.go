@@ -4,9 +4,12 @@
 package wire
 
 import (
+	boltadapters "github.com/boreq/bolt-ui/adapters/bolt"
 	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/mocks"
 	"github.com/boreq/bolt-ui/internal/service"
+	httpport "github.com/boreq/bolt-ui/ports/http"
 	"github.com/google/wire"
 	bolt "go.etcd.io/bbolt"
 )
@@ -27,10 +30,11 @@ func BuildTestTransactableAdapters(_ *bolt.Tx, _ Mocks) (*application.Transactab
 	return nil, nil
 }
 
-func BuildApplicationForTest(db *bolt.DB) (TestApplication, error) {
+func BuildApplicationForTest(db *bolt.DB, permissions application.Permissions) (TestApplication, error) {
 	wire.Build(
 		appSet,
 		testAdaptersSet,
+		wire.Bind(new(boltadapters.DB), new(*bolt.DB)),
 
 		wire.Struct(new(TestApplication), "*"),
 		wire.Struct(new(Mocks), "*"),
@@ -41,21 +45,69 @@ func BuildApplicationForTest(db *bolt.DB) (TestApplication, error) {
 
 type TestApplication struct {
 	Application *application.Application
+	Sampler     *application.DatabaseInfoSampler
 	Mocks
 	DB *bolt.DB
 }
 
 type Mocks struct {
+	Source *mocks.SourceMock
 }
 
-func BuildService(conf *config.Config) (*service.Service, error) {
+func BuildApplication(conf *config.Config, database config.Database) (*application.Application, func(), error) {
 	wire.Build(
-		service.NewService,
-		httpSet,
 		appSet,
+		newPermissions,
 		boltSet,
 		adaptersSet,
 	)
 
+	return nil, nil, nil
+}
+
+// BuildDatabase builds an application serving the database file. The name of
+// the database isn't set.
+func BuildDatabase(conf *config.Config, database config.Database) (*service.Database, func(), error) {
+	wire.Build(
+		wire.Struct(new(service.Database), "Application", "Sampler", "Source"),
+		appSet,
+		newPermissions,
+		boltSet,
+		adaptersSet,
+	)
+
+	return nil, nil, nil
+}
+
+// BuildHandler builds an HTTP handler serving a database which was opened by
+// someone else.
+func BuildHandler(db *bolt.DB, authProvider httpport.AuthProvider, conf *config.Config) (*EmbeddedHandler, error) {
+	wire.Build(
+		wire.Struct(new(EmbeddedHandler), "*"),
+		httpport.NewHandler,
+		newExternalDatabases,
+		appSet,
+		newPermissions,
+		externalBoltSet,
+		adaptersSet,
+	)
+
 	return nil, nil
 }
+
+// EmbeddedHandler is a handler serving a database which was opened by someone
+// else. The sampler is not started.
+type EmbeddedHandler struct {
+	Handler *httpport.Handler
+	Sampler *application.DatabaseInfoSampler
+}
+
+func BuildService(conf *config.Config) (*service.Service, func(), error) {
+	wire.Build(
+		service.NewService,
+		httpSet,
+		newDatabases,
+	)
+
+	return nil, nil, nil
+}
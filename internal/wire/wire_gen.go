@@ -10,6 +10,7 @@ import (
 	"github.com/boreq/bolt-ui/adapters/bolt"
 	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/mocks"
 	"github.com/boreq/bolt-ui/internal/service"
 	"github.com/boreq/bolt-ui/ports/http"
 	"go.etcd.io/bbolt"
@@ -33,50 +34,292 @@ func BuildTestTransactableAdapters(tx *bbolt.Tx, mocks Mocks) (*application.Tran
 	return transactableAdapters, nil
 }
 
-func BuildApplicationForTest(db *bbolt.DB) (TestApplication, error) {
-	mocks := Mocks{}
-	wireTestAdaptersProvider := newTestAdaptersProvider(mocks)
+func BuildApplicationForTest(db *bbolt.DB, permissions application.Permissions) (TestApplication, error) {
+	sourceMock := mocks.NewSourceMock()
+	wireMocks := Mocks{
+		Source: sourceMock,
+	}
+	wireTestAdaptersProvider := newTestAdaptersProvider(wireMocks)
 	transactionProvider := bolt.NewTransactionProvider(db, wireTestAdaptersProvider)
 	browseHandler := application.NewBrowseHandler(transactionProvider)
+	putHandler := application.NewPutHandler(transactionProvider, permissions)
+	deleteHandler := application.NewDeleteHandler(transactionProvider, permissions)
+	createBucketHandler := application.NewCreateBucketHandler(transactionProvider, permissions)
+	deleteBucketHandler := application.NewDeleteBucketHandler(transactionProvider, permissions)
+	moveBucketHandler := application.NewMoveBucketHandler(transactionProvider, permissions)
+	getSourceInfoHandler := application.NewGetSourceInfoHandler(sourceMock)
+	takeSnapshotHandler := application.NewTakeSnapshotHandler(sourceMock)
+	reopenHandler := application.NewReopenHandler(sourceMock)
+	searchHandler := application.NewSearchHandler(transactionProvider)
+	queryHandler := application.NewQueryHandler(transactionProvider)
+	getBucketStatsHandler := application.NewGetBucketStatsHandler(transactionProvider)
+	getDatabaseStatsHandler := application.NewGetDatabaseStatsHandler(transactionProvider)
+	databaseInfoSampler := application.NewDatabaseInfoSampler(sourceMock)
+	getDatabaseInfoHandler := application.NewGetDatabaseInfoHandler(sourceMock, databaseInfoSampler)
+	checkConsistencyHandler := application.NewCheckConsistencyHandler(transactionProvider)
+	compactHandler := application.NewCompactHandler(sourceMock, permissions)
+	backupHandler := application.NewBackupHandler(transactionProvider)
+	exportHandler := application.NewExportHandler(transactionProvider)
+	importHandler := application.NewImportHandler(transactionProvider, permissions)
+	diffHandler := application.NewDiffHandler(sourceMock, permissions)
+	watchBucketHandler := application.NewWatchBucketHandler(transactionProvider, sourceMock)
 	applicationApplication := &application.Application{
-		Browse: browseHandler,
+		Browse:           browseHandler,
+		Put:              putHandler,
+		Delete:           deleteHandler,
+		CreateBucket:     createBucketHandler,
+		DeleteBucket:     deleteBucketHandler,
+		MoveBucket:       moveBucketHandler,
+		GetSourceInfo:    getSourceInfoHandler,
+		TakeSnapshot:     takeSnapshotHandler,
+		Reopen:           reopenHandler,
+		Search:           searchHandler,
+		Query:            queryHandler,
+		GetBucketStats:   getBucketStatsHandler,
+		GetDatabaseStats: getDatabaseStatsHandler,
+		GetDatabaseInfo:  getDatabaseInfoHandler,
+		CheckConsistency: checkConsistencyHandler,
+		Compact:          compactHandler,
+		Backup:           backupHandler,
+		Export:           exportHandler,
+		Import:           importHandler,
+		Diff:             diffHandler,
+		WatchBucket:      watchBucketHandler,
 	}
 	testApplication := TestApplication{
 		Application: applicationApplication,
-		Mocks:       mocks,
+		Sampler:     databaseInfoSampler,
+		Mocks:       wireMocks,
 		DB:          db,
 	}
 	return testApplication, nil
 }
 
-func BuildService(conf *config.Config) (*service.Service, error) {
-	db, err := newBolt(conf)
+func BuildApplication(conf *config.Config, database config.Database) (*application.Application, func(), error) {
+	source, cleanup, err := newSource(conf, database)
+	if err != nil {
+		return nil, nil, err
+	}
+	wireAdaptersProvider := newAdaptersProvider()
+	transactionProvider := bolt.NewTransactionProvider(source, wireAdaptersProvider)
+	browseHandler := application.NewBrowseHandler(transactionProvider)
+	permissions := newPermissions(conf)
+	putHandler := application.NewPutHandler(transactionProvider, permissions)
+	deleteHandler := application.NewDeleteHandler(transactionProvider, permissions)
+	createBucketHandler := application.NewCreateBucketHandler(transactionProvider, permissions)
+	deleteBucketHandler := application.NewDeleteBucketHandler(transactionProvider, permissions)
+	moveBucketHandler := application.NewMoveBucketHandler(transactionProvider, permissions)
+	getSourceInfoHandler := application.NewGetSourceInfoHandler(source)
+	takeSnapshotHandler := application.NewTakeSnapshotHandler(source)
+	reopenHandler := application.NewReopenHandler(source)
+	searchHandler := application.NewSearchHandler(transactionProvider)
+	queryHandler := application.NewQueryHandler(transactionProvider)
+	getBucketStatsHandler := application.NewGetBucketStatsHandler(transactionProvider)
+	getDatabaseStatsHandler := application.NewGetDatabaseStatsHandler(transactionProvider)
+	databaseInfoSampler := application.NewDatabaseInfoSampler(source)
+	getDatabaseInfoHandler := application.NewGetDatabaseInfoHandler(source, databaseInfoSampler)
+	checkConsistencyHandler := application.NewCheckConsistencyHandler(transactionProvider)
+	compactHandler := application.NewCompactHandler(source, permissions)
+	backupHandler := application.NewBackupHandler(transactionProvider)
+	exportHandler := application.NewExportHandler(transactionProvider)
+	importHandler := application.NewImportHandler(transactionProvider, permissions)
+	diffHandler := application.NewDiffHandler(source, permissions)
+	watchBucketHandler := application.NewWatchBucketHandler(transactionProvider, source)
+	applicationApplication := &application.Application{
+		Browse:           browseHandler,
+		Put:              putHandler,
+		Delete:           deleteHandler,
+		CreateBucket:     createBucketHandler,
+		DeleteBucket:     deleteBucketHandler,
+		MoveBucket:       moveBucketHandler,
+		GetSourceInfo:    getSourceInfoHandler,
+		TakeSnapshot:     takeSnapshotHandler,
+		Reopen:           reopenHandler,
+		Search:           searchHandler,
+		Query:            queryHandler,
+		GetBucketStats:   getBucketStatsHandler,
+		GetDatabaseStats: getDatabaseStatsHandler,
+		GetDatabaseInfo:  getDatabaseInfoHandler,
+		CheckConsistency: checkConsistencyHandler,
+		Compact:          compactHandler,
+		Backup:           backupHandler,
+		Export:           exportHandler,
+		Import:           importHandler,
+		Diff:             diffHandler,
+		WatchBucket:      watchBucketHandler,
+	}
+	return applicationApplication, func() {
+		cleanup()
+	}, nil
+}
+
+// BuildDatabase builds an application serving the database file. The name of
+// the database isn't set.
+func BuildDatabase(conf *config.Config, database config.Database) (*service.Database, func(), error) {
+	source, cleanup, err := newSource(conf, database)
+	if err != nil {
+		return nil, nil, err
+	}
+	wireAdaptersProvider := newAdaptersProvider()
+	transactionProvider := bolt.NewTransactionProvider(source, wireAdaptersProvider)
+	browseHandler := application.NewBrowseHandler(transactionProvider)
+	permissions := newPermissions(conf)
+	putHandler := application.NewPutHandler(transactionProvider, permissions)
+	deleteHandler := application.NewDeleteHandler(transactionProvider, permissions)
+	createBucketHandler := application.NewCreateBucketHandler(transactionProvider, permissions)
+	deleteBucketHandler := application.NewDeleteBucketHandler(transactionProvider, permissions)
+	moveBucketHandler := application.NewMoveBucketHandler(transactionProvider, permissions)
+	getSourceInfoHandler := application.NewGetSourceInfoHandler(source)
+	takeSnapshotHandler := application.NewTakeSnapshotHandler(source)
+	reopenHandler := application.NewReopenHandler(source)
+	searchHandler := application.NewSearchHandler(transactionProvider)
+	queryHandler := application.NewQueryHandler(transactionProvider)
+	getBucketStatsHandler := application.NewGetBucketStatsHandler(transactionProvider)
+	getDatabaseStatsHandler := application.NewGetDatabaseStatsHandler(transactionProvider)
+	databaseInfoSampler := application.NewDatabaseInfoSampler(source)
+	getDatabaseInfoHandler := application.NewGetDatabaseInfoHandler(source, databaseInfoSampler)
+	checkConsistencyHandler := application.NewCheckConsistencyHandler(transactionProvider)
+	compactHandler := application.NewCompactHandler(source, permissions)
+	backupHandler := application.NewBackupHandler(transactionProvider)
+	exportHandler := application.NewExportHandler(transactionProvider)
+	importHandler := application.NewImportHandler(transactionProvider, permissions)
+	diffHandler := application.NewDiffHandler(source, permissions)
+	watchBucketHandler := application.NewWatchBucketHandler(transactionProvider, source)
+	applicationApplication := &application.Application{
+		Browse:           browseHandler,
+		Put:              putHandler,
+		Delete:           deleteHandler,
+		CreateBucket:     createBucketHandler,
+		DeleteBucket:     deleteBucketHandler,
+		MoveBucket:       moveBucketHandler,
+		GetSourceInfo:    getSourceInfoHandler,
+		TakeSnapshot:     takeSnapshotHandler,
+		Reopen:           reopenHandler,
+		Search:           searchHandler,
+		Query:            queryHandler,
+		GetBucketStats:   getBucketStatsHandler,
+		GetDatabaseStats: getDatabaseStatsHandler,
+		GetDatabaseInfo:  getDatabaseInfoHandler,
+		CheckConsistency: checkConsistencyHandler,
+		Compact:          compactHandler,
+		Backup:           backupHandler,
+		Export:           exportHandler,
+		Import:           importHandler,
+		Diff:             diffHandler,
+		WatchBucket:      watchBucketHandler,
+	}
+	serviceDatabase := &service.Database{
+		Application: applicationApplication,
+		Sampler:     databaseInfoSampler,
+		Source:      source,
+	}
+	return serviceDatabase, func() {
+		cleanup()
+	}, nil
+}
+
+// BuildHandler builds an HTTP handler serving a database which was opened by
+// someone else.
+func BuildHandler(db *bbolt.DB, authProvider http.AuthProvider, conf *config.Config) (*EmbeddedHandler, error) {
+	source, err := bolt.NewExternalSource(db)
 	if err != nil {
 		return nil, err
 	}
 	wireAdaptersProvider := newAdaptersProvider()
-	transactionProvider := bolt.NewTransactionProvider(db, wireAdaptersProvider)
+	transactionProvider := bolt.NewTransactionProvider(source, wireAdaptersProvider)
 	browseHandler := application.NewBrowseHandler(transactionProvider)
+	permissions := newPermissions(conf)
+	putHandler := application.NewPutHandler(transactionProvider, permissions)
+	deleteHandler := application.NewDeleteHandler(transactionProvider, permissions)
+	createBucketHandler := application.NewCreateBucketHandler(transactionProvider, permissions)
+	deleteBucketHandler := application.NewDeleteBucketHandler(transactionProvider, permissions)
+	moveBucketHandler := application.NewMoveBucketHandler(transactionProvider, permissions)
+	getSourceInfoHandler := application.NewGetSourceInfoHandler(source)
+	takeSnapshotHandler := application.NewTakeSnapshotHandler(source)
+	reopenHandler := application.NewReopenHandler(source)
+	searchHandler := application.NewSearchHandler(transactionProvider)
+	queryHandler := application.NewQueryHandler(transactionProvider)
+	getBucketStatsHandler := application.NewGetBucketStatsHandler(transactionProvider)
+	getDatabaseStatsHandler := application.NewGetDatabaseStatsHandler(transactionProvider)
+	databaseInfoSampler := application.NewDatabaseInfoSampler(source)
+	getDatabaseInfoHandler := application.NewGetDatabaseInfoHandler(source, databaseInfoSampler)
+	checkConsistencyHandler := application.NewCheckConsistencyHandler(transactionProvider)
+	compactHandler := application.NewCompactHandler(source, permissions)
+	backupHandler := application.NewBackupHandler(transactionProvider)
+	exportHandler := application.NewExportHandler(transactionProvider)
+	importHandler := application.NewImportHandler(transactionProvider, permissions)
+	diffHandler := application.NewDiffHandler(source, permissions)
+	watchBucketHandler := application.NewWatchBucketHandler(transactionProvider, source)
 	applicationApplication := &application.Application{
-		Browse: browseHandler,
+		Browse:           browseHandler,
+		Put:              putHandler,
+		Delete:           deleteHandler,
+		CreateBucket:     createBucketHandler,
+		DeleteBucket:     deleteBucketHandler,
+		MoveBucket:       moveBucketHandler,
+		GetSourceInfo:    getSourceInfoHandler,
+		TakeSnapshot:     takeSnapshotHandler,
+		Reopen:           reopenHandler,
+		Search:           searchHandler,
+		Query:            queryHandler,
+		GetBucketStats:   getBucketStatsHandler,
+		GetDatabaseStats: getDatabaseStatsHandler,
+		GetDatabaseInfo:  getDatabaseInfoHandler,
+		CheckConsistency: checkConsistencyHandler,
+		Compact:          compactHandler,
+		Backup:           backupHandler,
+		Export:           exportHandler,
+		Import:           importHandler,
+		Diff:             diffHandler,
+		WatchBucket:      watchBucketHandler,
 	}
-	tokenAuthProvider := http.NewTokenAuthProvider(conf)
-	handler, err := http.NewHandler(applicationApplication, tokenAuthProvider)
+	v := newExternalDatabases(applicationApplication)
+	handler, err := http.NewHandler(v, authProvider, conf)
 	if err != nil {
 		return nil, err
 	}
+	embeddedHandler := &EmbeddedHandler{
+		Handler: handler,
+		Sampler: databaseInfoSampler,
+	}
+	return embeddedHandler, nil
+}
+
+func BuildService(conf *config.Config) (*service.Service, func(), error) {
+	v, cleanup, err := newDatabases(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	v2 := newHTTPDatabases(v)
+	tokenAuthProvider := http.NewTokenAuthProvider(conf)
+	handler, err := http.NewHandler(v2, tokenAuthProvider, conf)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	server := http.NewServer(handler, conf)
-	serviceService := service.NewService(server)
-	return serviceService, nil
+	serviceService := service.NewService(server, v)
+	return serviceService, func() {
+		cleanup()
+	}, nil
 }
 
 // wire.go:
 
 type TestApplication struct {
 	Application *application.Application
+	Sampler     *application.DatabaseInfoSampler
 	Mocks
 	DB *bbolt.DB
 }
 
 type Mocks struct {
+	Source *mocks.SourceMock
+}
+
+// EmbeddedHandler is a handler serving a database which was opened by someone
+// else. The sampler is not started.
+type EmbeddedHandler struct {
+	Handler *http.Handler
+	Sampler *application.DatabaseInfoSampler
 }
@@ -0,0 +1,298 @@
+// Package jsonlines implements the JSON Lines format used to export and import
+// the contents of a database. Every line contains a single record describing
+// a bucket or a key/value pair together with the path to the bucket
+// containing it. Records describing buckets always precede the records
+// describing their contents.
+package jsonlines
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/errors"
+)
+
+type Record struct {
+	Path     []Key   `json:"path"`
+	Key      Key     `json:"key"`
+	Bucket   bool    `json:"bucket,omitempty"`
+	Sequence *uint64 `json:"sequence,omitempty"`
+	Value    *Value  `json:"value,omitempty"`
+}
+
+type Key struct {
+	Hex string `json:"hex"`
+	Str string `json:"str,omitempty"`
+}
+
+// Value contains the raw value encoded using exactly one of the supported
+// encodings. Decoded is informational and ignored when importing.
+type Value struct {
+	Hex     *string  `json:"hex,omitempty"`
+	Base64  *string  `json:"base64,omitempty"`
+	Decoded *Decoded `json:"decoded,omitempty"`
+}
+
+type Decoded struct {
+	ContentType string          `json:"content_type"`
+	Value       json.RawMessage `json:"value"`
+}
+
+type Encoding struct {
+	s string
+}
+
+var (
+	EncodingHex    = Encoding{"hex"}
+	EncodingBase64 = Encoding{"base64"}
+)
+
+func NewEncoding(s string) (Encoding, error) {
+	switch s {
+	case EncodingHex.s:
+		return EncodingHex, nil
+	case EncodingBase64.s:
+		return EncodingBase64, nil
+	default:
+		return Encoding{}, errors.New("unknown encoding")
+	}
+}
+
+type Encoder struct {
+	encoder  *json.Encoder
+	encoding Encoding
+	pretty   *display.Pretty
+}
+
+func NewEncoder(w io.Writer, encoding Encoding) *Encoder {
+	return &Encoder{
+		encoder:  json.NewEncoder(w),
+		encoding: encoding,
+		pretty:   display.NewPretty(),
+	}
+}
+
+func (e *Encoder) Encode(entry application.ExportedEntry) error {
+	record := Record{
+		Path:   toKeys(entry.Path),
+		Key:    toKey(entry.Entry.Key),
+		Bucket: entry.Entry.Bucket,
+	}
+
+	if entry.Entry.Bucket {
+		sequence := entry.Sequence
+		record.Sequence = &sequence
+	} else {
+		value, err := e.toValue(entry.Path, entry.Entry.Value)
+		if err != nil {
+			return errors.Wrap(err, "could not convert the value")
+		}
+		record.Value = value
+	}
+
+	return e.encoder.Encode(record)
+}
+
+func (e *Encoder) toValue(path []application.Key, value application.Value) (*Value, error) {
+	b := value.Bytes()
+
+	var result Value
+
+	switch e.encoding {
+	case EncodingHex:
+		s := hex.EncodeToString(b)
+		result.Hex = &s
+	case EncodingBase64:
+		s := base64.StdEncoding.EncodeToString(b)
+		result.Base64 = &s
+	default:
+		return nil, errors.New("unknown encoding")
+	}
+
+	result.Decoded = e.toDecoded(path, b)
+	return &result, nil
+}
+
+// toDecoded returns nil if the value isn't recognized by any of the
+// prettifiers.
+func (e *Encoder) toDecoded(path []application.Key, b []byte) *Decoded {
+	if len(b) == 0 {
+		return nil
+	}
+
+	decoded, err := e.pretty.DecodeInBucket(toBucketPath(path), b)
+	if err != nil {
+		return nil
+	}
+
+	// some decoded values such as NaN can't be represented in JSON
+	j, err := json.Marshal(decoded.Value)
+	if err != nil {
+		return nil
+	}
+
+	return &Decoded{
+		ContentType: decoded.Type.String(),
+		Value:       j,
+	}
+}
+
+type Decoder struct {
+	decoder *json.Decoder
+	n       int
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		decoder: json.NewDecoder(r),
+	}
+}
+
+// Next returns io.EOF once there are no more records and an error wrapping
+// application.ErrInvalidEntry if a record is malformed.
+func (d *Decoder) Next() (application.ImportedEntry, error) {
+	var record Record
+	if err := d.decoder.Decode(&record); err != nil {
+		if errors.Is(err, io.EOF) {
+			return application.ImportedEntry{}, io.EOF
+		}
+
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return application.ImportedEntry{}, fmt.Errorf("%w: could not decode record %d: %w", application.ErrInvalidEntry, d.n+1, err)
+		}
+
+		return application.ImportedEntry{}, errors.Wrapf(err, "could not read record %d", d.n+1)
+	}
+
+	d.n++
+
+	entry, err := fromRecord(record)
+	if err != nil {
+		return application.ImportedEntry{}, fmt.Errorf("%w: record %d: %w", application.ErrInvalidEntry, d.n, err)
+	}
+
+	return entry, nil
+}
+
+func fromRecord(record Record) (application.ImportedEntry, error) {
+	path, err := fromKeys(record.Path)
+	if err != nil {
+		return application.ImportedEntry{}, errors.Wrap(err, "invalid path")
+	}
+
+	key, err := fromKey(record.Key)
+	if err != nil {
+		return application.ImportedEntry{}, errors.Wrap(err, "invalid key")
+	}
+
+	entry := application.ImportedEntry{
+		Path: path,
+		Entry: application.Entry{
+			Bucket: record.Bucket,
+			Key:    key,
+		},
+	}
+
+	if record.Bucket {
+		if record.Value != nil {
+			return application.ImportedEntry{}, errors.New("buckets can not have values")
+		}
+		entry.Sequence = record.Sequence
+		return entry, nil
+	}
+
+	if record.Sequence != nil {
+		return application.ImportedEntry{}, errors.New("only buckets can have sequences")
+	}
+
+	if record.Value == nil {
+		return application.ImportedEntry{}, errors.New("missing value")
+	}
+
+	value, err := fromValue(*record.Value)
+	if err != nil {
+		return application.ImportedEntry{}, errors.Wrap(err, "invalid value")
+	}
+
+	entry.Entry.Value = value
+	return entry, nil
+}
+
+func fromValue(value Value) (application.Value, error) {
+	var b []byte
+	var err error
+
+	switch {
+	case value.Hex != nil && value.Base64 != nil:
+		return application.Value{}, errors.New("value has more than one encoding")
+	case value.Hex != nil:
+		b, err = hex.DecodeString(*value.Hex)
+	case value.Base64 != nil:
+		b, err = base64.StdEncoding.DecodeString(*value.Base64)
+	default:
+		return application.Value{}, errors.New("value has no encoding")
+	}
+
+	if err != nil {
+		return application.Value{}, errors.Wrap(err, "decoding failed")
+	}
+
+	return application.NewValue(b)
+}
+
+func fromKeys(keys []Key) ([]application.Key, error) {
+	var result []application.Key
+	for _, key := range keys {
+		k, err := fromKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid key")
+		}
+		result = append(result, k)
+	}
+	return result, nil
+}
+
+func fromKey(key Key) (application.Key, error) {
+	b, err := hex.DecodeString(key.Hex)
+	if err != nil {
+		return application.Key{}, errors.Wrap(err, "decoding failed")
+	}
+	return application.NewKey(b)
+}
+
+func toBucketPath(keys []application.Key) [][]byte {
+	result := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, key.Bytes())
+	}
+	return result
+}
+
+func toKeys(keys []application.Key) []Key {
+	result := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, toKey(key))
+	}
+	return result
+}
+
+func toKey(key application.Key) Key {
+	b := key.Bytes()
+
+	result := Key{
+		Hex: hex.EncodeToString(b),
+	}
+
+	if display.CanDisplayAsString(b) {
+		result.Str = string(b)
+	}
+
+	return result
+}
@@ -0,0 +1,359 @@
+package jsonlines_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/bolt-ui/jsonlines"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	cborValue, err := cbor.Marshal(map[string]int{"a": 1})
+	require.NoError(t, err)
+
+	gzippedJSON := gzipValue(t, []byte(`{"a":1}`))
+	gzippedCBOR := gzipValue(t, cborValue)
+
+	path := []application.Key{
+		application.MustNewKey([]byte("bucket")),
+		application.MustNewKey([]byte{0xff, 0x00}),
+	}
+
+	testCases := []struct {
+		Name     string
+		Encoding jsonlines.Encoding
+		Entry    application.ExportedEntry
+		Result   string
+	}{
+		{
+			Name:     "bucket",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: path,
+				Entry: application.Entry{
+					Bucket: true,
+					Key:    application.MustNewKey([]byte("nested")),
+				},
+				Sequence: 10,
+			},
+			Result: `{"path":[{"hex":"6275636b6574","str":"bucket"},{"hex":"ff00"}],"key":{"hex":"6e6573746564","str":"nested"},"bucket":true,"sequence":10}`,
+		},
+		{
+			Name:     "bucket_without_sequence",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Bucket: true,
+					Key:    application.MustNewKey([]byte("bucket")),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6275636b6574","str":"bucket"},"bucket":true,"sequence":0}`,
+		},
+		{
+			Name:     "json_hex",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte(`{"a":1}`)),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"7b2261223a317d","decoded":{"content_type":"json","value":{"a":1}}}}`,
+		},
+		{
+			Name:     "cbor_base64",
+			Encoding: jsonlines.EncodingBase64,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue(cborValue),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"base64":"oWFhAQ==","decoded":{"content_type":"cbor","value":{"a":1}}}}`,
+		},
+		{
+			Name:     "string",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte("value")),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"76616c7565","decoded":{"content_type":"string","value":"value"}}}`,
+		},
+		{
+			Name:     "msgpack",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte{0x81, 0xa1, 'a', 0x01}),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"81a16101","decoded":{"content_type":"msgpack","value":{"a":1}}}}`,
+		},
+		{
+			Name:     "protobuf_wire",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte{0x08, 0x01}),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"0801","decoded":{"content_type":"protobuf-wire","value":"1 (varint): 1\n"}}}`,
+		},
+		{
+			Name:     "gzip_json",
+			Encoding: jsonlines.EncodingBase64,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue(gzippedJSON),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"base64":"` + base64.StdEncoding.EncodeToString(gzippedJSON) + `","decoded":{"content_type":"gzip+json","value":{"a":1}}}}`,
+		},
+		{
+			Name:     "gzip_cbor",
+			Encoding: jsonlines.EncodingBase64,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue(gzippedCBOR),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"base64":"` + base64.StdEncoding.EncodeToString(gzippedCBOR) + `","decoded":{"content_type":"gzip+cbor","value":{"a":1}}}}`,
+		},
+		{
+			Name:     "binary",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte{0x00, 0xff}),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"00ff"}}`,
+		},
+		{
+			Name:     "empty",
+			Encoding: jsonlines.EncodingHex,
+			Entry: application.ExportedEntry{
+				Path: nil,
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue(nil),
+				},
+			},
+			Result: `{"path":[],"key":{"hex":"6b6579","str":"key"},"value":{"hex":""}}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			encoder := jsonlines.NewEncoder(buf, testCase.Encoding)
+			require.NoError(t, encoder.Encode(testCase.Entry))
+			require.Equal(t, testCase.Result+"\n", buf.String())
+		})
+	}
+}
+
+func TestEncoderUsesRulesOfBuckets(t *testing.T) {
+	rules, err := display.NewRules([]display.Rule{
+		{Bucket: "strings", Value: "string"},
+		{Bucket: "compressed", Value: "gzip+string"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, display.RegisterRules(rules))
+	t.Cleanup(func() {
+		require.NoError(t, display.RegisterRules(nil))
+	})
+
+	buf := &bytes.Buffer{}
+	encoder := jsonlines.NewEncoder(buf, jsonlines.EncodingHex)
+
+	gzipped := gzipValue(t, []byte(`{"a":1}`))
+
+	for _, entry := range []struct {
+		Bucket string
+		Value  []byte
+	}{
+		{"strings", []byte(`{"a":1}`)},
+		{"other", []byte(`{"a":1}`)},
+		{"compressed", gzipped},
+	} {
+		err := encoder.Encode(application.ExportedEntry{
+			Path: []application.Key{application.MustNewKey([]byte(entry.Bucket))},
+			Entry: application.Entry{
+				Key:   application.MustNewKey([]byte("key")),
+				Value: application.MustNewValue(entry.Value),
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t,
+		`{"path":[{"hex":"737472696e6773","str":"strings"}],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"7b2261223a317d","decoded":{"content_type":"string","value":"{\"a\":1}"}}}`+"\n"+
+			`{"path":[{"hex":"6f74686572","str":"other"}],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"7b2261223a317d","decoded":{"content_type":"json","value":{"a":1}}}}`+"\n"+
+			`{"path":[{"hex":"636f6d70726573736564","str":"compressed"}],"key":{"hex":"6b6579","str":"key"},"value":{"hex":"`+hex.EncodeToString(gzipped)+`","decoded":{"content_type":"gzip+string","value":"{\"a\":1}"}}}`+"\n",
+		buf.String(),
+	)
+}
+
+func TestDecoder(t *testing.T) {
+	sequence := uint64(10)
+
+	testCases := []struct {
+		Name   string
+		Line   string
+		Result application.ImportedEntry
+	}{
+		{
+			Name: "bucket",
+			Line: `{"path":[{"hex":"6275636b6574","str":"bucket"},{"hex":"ff00"}],"key":{"hex":"6e6573746564"},"bucket":true,"sequence":10}`,
+			Result: application.ImportedEntry{
+				Path: []application.Key{
+					application.MustNewKey([]byte("bucket")),
+					application.MustNewKey([]byte{0xff, 0x00}),
+				},
+				Entry: application.Entry{
+					Bucket: true,
+					Key:    application.MustNewKey([]byte("nested")),
+				},
+				Sequence: &sequence,
+			},
+		},
+		{
+			Name: "bucket_without_sequence",
+			Line: `{"path":[],"key":{"hex":"6275636b6574"},"bucket":true}`,
+			Result: application.ImportedEntry{
+				Entry: application.Entry{
+					Bucket: true,
+					Key:    application.MustNewKey([]byte("bucket")),
+				},
+			},
+		},
+		{
+			Name: "hex",
+			Line: `{"path":[{"hex":"6275636b6574"}],"key":{"hex":"6b6579"},"value":{"hex":"76616c7565","decoded":{"content_type":"string","value":"ignored"}}}`,
+			Result: application.ImportedEntry{
+				Path: []application.Key{
+					application.MustNewKey([]byte("bucket")),
+				},
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte("value")),
+				},
+			},
+		},
+		{
+			Name: "base64",
+			Line: `{"path":[{"hex":"6275636b6574"}],"key":{"hex":"6b6579"},"value":{"base64":"dmFsdWU="}}`,
+			Result: application.ImportedEntry{
+				Path: []application.Key{
+					application.MustNewKey([]byte("bucket")),
+				},
+				Entry: application.Entry{
+					Key:   application.MustNewKey([]byte("key")),
+					Value: application.MustNewValue([]byte("value")),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			decoder := jsonlines.NewDecoder(strings.NewReader(testCase.Line + "\n"))
+
+			entry, err := decoder.Next()
+			require.NoError(t, err)
+			require.Equal(t, testCase.Result, entry)
+
+			_, err = decoder.Next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestDecoderInvalidRecords(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Line string
+	}{
+		{
+			Name: "malformed_json",
+			Line: `{"path":`,
+		},
+		{
+			Name: "invalid_type",
+			Line: `{"path":"bucket","key":{"hex":"6b6579"},"bucket":true}`,
+		},
+		{
+			Name: "invalid_key",
+			Line: `{"path":[],"key":{"hex":"zz"},"bucket":true}`,
+		},
+		{
+			Name: "empty_key",
+			Line: `{"path":[],"key":{"hex":""},"bucket":true}`,
+		},
+		{
+			Name: "bucket_with_value",
+			Line: `{"path":[],"key":{"hex":"6b6579"},"bucket":true,"value":{"hex":"00"}}`,
+		},
+		{
+			Name: "value_with_sequence",
+			Line: `{"path":[{"hex":"6b6579"}],"key":{"hex":"6b6579"},"sequence":1,"value":{"hex":"00"}}`,
+		},
+		{
+			Name: "missing_value",
+			Line: `{"path":[{"hex":"6b6579"}],"key":{"hex":"6b6579"}}`,
+		},
+		{
+			Name: "value_without_encoding",
+			Line: `{"path":[{"hex":"6b6579"}],"key":{"hex":"6b6579"},"value":{}}`,
+		},
+		{
+			Name: "value_with_two_encodings",
+			Line: `{"path":[{"hex":"6b6579"}],"key":{"hex":"6b6579"},"value":{"hex":"00","base64":"AA=="}}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			decoder := jsonlines.NewDecoder(strings.NewReader(testCase.Line + "\n"))
+
+			_, err := decoder.Next()
+			require.ErrorIs(t, err, application.ErrInvalidEntry)
+		})
+	}
+}
+
+func gzipValue(t *testing.T, b []byte) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	_, err := w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,166 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+type node interface {
+	eval(v interface{}) bool
+}
+
+type operand interface {
+	Get(v interface{}) (interface{}, bool)
+}
+
+type literal struct {
+	v interface{}
+}
+
+func (l literal) Get(v interface{}) (interface{}, bool) {
+	return l.v, true
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(v interface{}) bool {
+	return n.left.eval(v) || n.right.eval(v)
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(v interface{}) bool {
+	return n.left.eval(v) && n.right.eval(v)
+}
+
+type notNode struct {
+	n node
+}
+
+func (n notNode) eval(v interface{}) bool {
+	return !n.n.eval(v)
+}
+
+type truthyNode struct {
+	operand operand
+}
+
+func (n truthyNode) eval(v interface{}) bool {
+	value, ok := n.operand.Get(v)
+	if !ok || value == nil {
+		return false
+	}
+
+	if b, ok := value.(bool); ok {
+		return b
+	}
+
+	return true
+}
+
+type comparisonNode struct {
+	left  operand
+	op    string
+	right operand
+}
+
+func (n comparisonNode) eval(v interface{}) bool {
+	left, ok := n.left.Get(v)
+	if !ok {
+		return false
+	}
+
+	right, ok := n.right.Get(v)
+	if !ok {
+		return false
+	}
+
+	if n.op == "==" {
+		return equal(left, right)
+	}
+
+	if n.op == "!=" {
+		return !equal(left, right)
+	}
+
+	cmp, ok := compare(left, right)
+	if !ok {
+		return false
+	}
+
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+func equal(a, b interface{}) bool {
+	if cmp, ok := compare(a, b); ok {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare returns false if the values are not both numbers or both strings.
+func compare(a, b interface{}) (int, bool) {
+	if aFloat, ok := toFloat(a); ok {
+		if bFloat, ok := toFloat(b); ok {
+			switch {
+			case aFloat < bFloat:
+				return -1, true
+			case aFloat > bFloat:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+		return 0, false
+	}
+
+	if aString, ok := a.(string); ok {
+		if bString, ok := b.(string); ok {
+			switch {
+			case aString < bString:
+				return -1, true
+			case aString > bString:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch typed := v.(type) {
+	case float64:
+		return typed, true
+	case float32:
+		return float64(typed), true
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case json.Number:
+		f, err := typed.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
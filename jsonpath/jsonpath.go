@@ -0,0 +1,352 @@
+// Package jsonpath implements a small JSONPath-style expression language
+// which is used to filter and project decoded values.
+//
+// Paths start with "$" which refers to the entire value and select nested
+// elements using ".field", ["field"] or [index], for example
+// $.jobs[0]["status"]. Expressions compare paths and literals (strings,
+// numbers, true, false and null) using ==, !=, <, <=, > and >= and combine
+// the comparisons using &&, || and !. A path used on its own is true if it
+// exists and is neither null nor false.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/boreq/errors"
+)
+
+type Path struct {
+	s     string
+	steps []step
+}
+
+type step struct {
+	field   string
+	index   int
+	isIndex bool
+}
+
+func ParsePath(s string) (Path, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return Path{}, errors.Wrap(err, "lexing failed")
+	}
+
+	p := newParser(tokens)
+
+	path, err := p.parsePath()
+	if err != nil {
+		return Path{}, errors.Wrap(err, "parsing failed")
+	}
+
+	if err := p.expect(tokenEOF); err != nil {
+		return Path{}, errors.Wrap(err, "parsing failed")
+	}
+
+	path.s = s
+	return path, nil
+}
+
+func MustParsePath(s string) Path {
+	p, err := ParsePath(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Root returns a path selecting the entire value.
+func Root() Path {
+	return Path{s: "$"}
+}
+
+// Field returns a path selecting the field of the element selected by this
+// path.
+func (p Path) Field(name string) Path {
+	s := p.s + "[" + strconv.Quote(name) + "]"
+	if isIdentifier(name) {
+		s = p.s + "." + name
+	}
+
+	return Path{
+		s:     s,
+		steps: appendStep(p.steps, step{field: name}),
+	}
+}
+
+// Index returns a path selecting the array element of the element selected by
+// this path.
+func (p Path) Index(index int) Path {
+	return Path{
+		s:     p.s + "[" + strconv.Itoa(index) + "]",
+		steps: appendStep(p.steps, step{index: index, isIndex: true}),
+	}
+}
+
+func appendStep(steps []step, s step) []step {
+	tmp := make([]step, len(steps), len(steps)+1)
+	copy(tmp, steps)
+	return append(tmp, s)
+}
+
+// Get returns the element of the value selected by the path. It returns false
+// if the element doesn't exist.
+func (p Path) Get(v interface{}) (interface{}, bool) {
+	for _, step := range p.steps {
+		switch typed := v.(type) {
+		case map[string]interface{}:
+			if step.isIndex {
+				return nil, false
+			}
+			elem, ok := typed[step.field]
+			if !ok {
+				return nil, false
+			}
+			v = elem
+		case []interface{}:
+			if !step.isIndex || step.index < 0 || step.index >= len(typed) {
+				return nil, false
+			}
+			v = typed[step.index]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func (p Path) String() string {
+	return p.s
+}
+
+type Expression struct {
+	s    string
+	root node
+}
+
+func ParseExpression(s string) (Expression, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return Expression{}, errors.Wrap(err, "lexing failed")
+	}
+
+	p := newParser(tokens)
+
+	root, err := p.parseOr()
+	if err != nil {
+		return Expression{}, errors.Wrap(err, "parsing failed")
+	}
+
+	if err := p.expect(tokenEOF); err != nil {
+		return Expression{}, errors.Wrap(err, "parsing failed")
+	}
+
+	return Expression{
+		s:    s,
+		root: root,
+	}, nil
+}
+
+func MustParseExpression(s string) Expression {
+	e, err := ParseExpression(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Evaluate returns true if the value matches the expression.
+func (e Expression) Evaluate(v interface{}) bool {
+	return e.root.eval(v)
+}
+
+func (e Expression) String() string {
+	return e.s
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func newParser(tokens []token) *parser {
+	return &parser{tokens: tokens}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(typ tokenType) error {
+	t := p.next()
+	if t.typ != typ {
+		return unexpectedToken(t)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().typ == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().typ == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().typ == tokenNot {
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n: n}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.peek().typ == tokenLeftParen {
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().typ != tokenOperator {
+		return truthyNode{operand: left}, nil
+	}
+
+	op := p.next().s
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return comparisonNode{left: left, op: op, right: right}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	switch t.typ {
+	case tokenRoot:
+		return p.parsePath()
+	case tokenString:
+		p.next()
+		return literal{v: t.s}, nil
+	case tokenNumber:
+		p.next()
+		f, err := strconv.ParseFloat(t.s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", t.s, t.pos)
+		}
+		return literal{v: f}, nil
+	case tokenIdentifier:
+		p.next()
+		switch t.s {
+		case "true":
+			return literal{v: true}, nil
+		case "false":
+			return literal{v: false}, nil
+		case "null":
+			return literal{v: nil}, nil
+		}
+	}
+	return nil, unexpectedToken(t)
+}
+
+func (p *parser) parsePath() (Path, error) {
+	if err := p.expect(tokenRoot); err != nil {
+		return Path{}, err
+	}
+
+	var path Path
+
+	for {
+		switch p.peek().typ {
+		case tokenDot:
+			p.next()
+			t := p.next()
+			if t.typ != tokenIdentifier {
+				return Path{}, unexpectedToken(t)
+			}
+			path.steps = append(path.steps, step{field: t.s})
+		case tokenLeftBracket:
+			p.next()
+			t := p.next()
+			switch t.typ {
+			case tokenString:
+				path.steps = append(path.steps, step{field: t.s})
+			case tokenNumber:
+				index, err := strconv.Atoi(t.s)
+				if err != nil {
+					return Path{}, fmt.Errorf("invalid index '%s' at position %d", t.s, t.pos)
+				}
+				path.steps = append(path.steps, step{index: index, isIndex: true})
+			default:
+				return Path{}, unexpectedToken(t)
+			}
+			if err := p.expect(tokenRightBracket); err != nil {
+				return Path{}, err
+			}
+		default:
+			return path, nil
+		}
+	}
+}
+
+func unexpectedToken(t token) error {
+	if t.typ == tokenEOF {
+		return errors.New("unexpected end of input")
+	}
+	return fmt.Errorf("unexpected '%s' at position %d", t.s, t.pos)
+}
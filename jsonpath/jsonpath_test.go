@@ -0,0 +1,152 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/boreq/bolt-ui/jsonpath"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `{
+	"status": "failed",
+	"attempts": 3,
+	"done": false,
+	"owner": null,
+	"tags": ["a", "b"],
+	"job": {"name": "export", "retry-count": 1.5},
+	"quote": "a\"b'c\u00e9"
+}`
+
+func TestExpression(t *testing.T) {
+	testCases := []struct {
+		Expression string
+		Result     bool
+	}{
+		{`$.status == "failed"`, true},
+		{`$.status == 'failed'`, true},
+		{`$.quote == "a\"b'c\u00e9"`, true},
+		{`$.quote == 'a\"b\'c\u00e9'`, true},
+		{`$.quote == 'a"b\'cé'`, true},
+		{`$['job'].name == 'export'`, true},
+		{`$.status != "failed"`, false},
+		{`$.status == "done"`, false},
+		{`$.attempts == 3`, true},
+		{`$.attempts >= 3`, true},
+		{`$.attempts > 3`, false},
+		{`$.attempts < 10 && $.status == "failed"`, true},
+		{`$.attempts > 10 || $.status == "failed"`, true},
+		{`!($.attempts > 10)`, true},
+		{`$.done == false`, true},
+		{`$.owner == null`, true},
+		{`$.owner`, false},
+		{`$.done`, false},
+		{`$.status`, true},
+		{`$.missing`, false},
+		{`$.missing == null`, false},
+		{`$.tags[1] == "b"`, true},
+		{`$.tags[2] == "c"`, false},
+		{`$["job"].name == "export"`, true},
+		{`$.job.retry-count > 1`, true},
+		{`$.status > "a"`, true},
+		{`$.status > 1`, false},
+	}
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &v))
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Expression, func(t *testing.T) {
+			expression, err := jsonpath.ParseExpression(testCase.Expression)
+			require.NoError(t, err)
+			require.Equal(t, testCase.Result, expression.Evaluate(v))
+		})
+	}
+}
+
+func TestExpressionInvalid(t *testing.T) {
+	testCases := []string{
+		``,
+		`$.`,
+		`$.status =`,
+		`$.status = "failed"`,
+		`$.status == "failed`,
+		`$.status == "failed" &&`,
+		`($.status == "failed"`,
+		`$[`,
+		`status == "failed"`,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			_, err := jsonpath.ParseExpression(testCase)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	testCases := []struct {
+		Path   string
+		Result interface{}
+		Ok     bool
+	}{
+		{`$`, nil, true},
+		{`$.status`, "failed", true},
+		{`$.tags[0]`, "a", true},
+		{`$.job["name"]`, "export", true},
+		{`$.job.missing`, nil, false},
+		{`$.tags.name`, nil, false},
+	}
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &v))
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Path, func(t *testing.T) {
+			path, err := jsonpath.ParsePath(testCase.Path)
+			require.NoError(t, err)
+
+			result, ok := path.Get(v)
+			require.Equal(t, testCase.Ok, ok)
+			if testCase.Path == "$" {
+				require.Equal(t, v, result)
+			} else {
+				require.Equal(t, testCase.Result, result)
+			}
+		})
+	}
+}
+
+func TestPathBuilders(t *testing.T) {
+	testCases := []struct {
+		Path   jsonpath.Path
+		String string
+	}{
+		{jsonpath.Root(), `$`},
+		{jsonpath.Root().Field("status"), `$.status`},
+		{jsonpath.Root().Field("tags").Index(1), `$.tags[1]`},
+		{jsonpath.Root().Field("job").Field("retry-count"), `$.job.retry-count`},
+		{jsonpath.Root().Field("with space"), `$["with space"]`},
+		{jsonpath.Root().Field("1st"), `$["1st"]`},
+		{jsonpath.Root().Field(`"quoted"`), `$["\"quoted\""]`},
+		{jsonpath.Root().Field(""), `$[""]`},
+	}
+
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &v))
+
+	for _, testCase := range testCases {
+		t.Run(testCase.String, func(t *testing.T) {
+			require.Equal(t, testCase.String, testCase.Path.String())
+
+			parsed, err := jsonpath.ParsePath(testCase.Path.String())
+			require.NoError(t, err)
+
+			expected, expectedOk := parsed.Get(v)
+			result, ok := testCase.Path.Get(v)
+			require.Equal(t, expectedOk, ok)
+			require.Equal(t, expected, result)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/boreq/errors"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenRoot
+	tokenDot
+	tokenLeftBracket
+	tokenRightBracket
+	tokenLeftParen
+	tokenRightParen
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type token struct {
+	typ tokenType
+	s   string
+	pos int
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '$':
+			tokens = append(tokens, token{typ: tokenRoot, s: "$", pos: i})
+			i++
+		case r == '.':
+			tokens = append(tokens, token{typ: tokenDot, s: ".", pos: i})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{typ: tokenLeftBracket, s: "[", pos: i})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{typ: tokenRightBracket, s: "]", pos: i})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{typ: tokenLeftParen, s: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{typ: tokenRightParen, s: ")", pos: i})
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("expected '%c%c' at position %d", r, r, i)
+			}
+			typ := tokenAnd
+			if r == '|' {
+				typ = tokenOr
+			}
+			tokens = append(tokens, token{typ: typ, s: string([]rune{r, r}), pos: i})
+			i += 2
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{typ: tokenOperator, s: string([]rune{r, '='}), pos: i})
+				i += 2
+				continue
+			}
+			switch r {
+			case '=':
+				return nil, fmt.Errorf("expected '==' at position %d", i)
+			case '!':
+				tokens = append(tokens, token{typ: tokenNot, s: "!", pos: i})
+			default:
+				tokens = append(tokens, token{typ: tokenOperator, s: string(r), pos: i})
+			}
+			i++
+		case r == '"' || r == '\'':
+			str, n, err := lexString(runes[i:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid string at position %d", i)
+			}
+			tokens = append(tokens, token{typ: tokenString, s: str, pos: i})
+			i += n
+		case r == '-' || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || strings.ContainsRune(".eE+-", runes[j])) {
+				j++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, s: string(runes[i:j]), pos: i})
+			i = j
+		case isIdentifierRune(r):
+			j := i + 1
+			for j < len(runes) && isIdentifierRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{typ: tokenIdentifier, s: string(runes[i:j]), pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, token{typ: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// lexString reads a quoted string and returns its unquoted value and the
+// number of consumed runes.
+func lexString(runes []rune) (string, int, error) {
+	quote := runes[0]
+
+	for i := 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case quote:
+			s := string(runes[1:i])
+			unquote := strconv.Unquote
+			if quote == '\'' {
+				unquote = unquoteSingle
+			} else {
+				s = `"` + s + `"`
+			}
+			unquoted, err := unquote(s)
+			if err != nil {
+				return "", 0, errors.Wrap(err, "could not unquote")
+			}
+			return unquoted, i + 1, nil
+		}
+	}
+
+	return "", 0, errors.New("unterminated string")
+}
+
+// unquoteSingle unescapes the contents of a single-quoted string. The escape
+// sequences are the same as in double-quoted strings except that both quotes
+// can be escaped.
+func unquoteSingle(s string) (string, error) {
+	var b strings.Builder
+	for len(s) > 0 {
+		if strings.HasPrefix(s, `\'`) || strings.HasPrefix(s, `\"`) {
+			b.WriteByte(s[1])
+			s = s[2:]
+			continue
+		}
+
+		r, multibyte, tail, err := strconv.UnquoteChar(s, '\'')
+		if err != nil {
+			return "", err
+		}
+
+		if r < utf8.RuneSelf || !multibyte {
+			b.WriteByte(byte(r))
+		} else {
+			b.WriteRune(r)
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
+
+// isIdentifier returns true if the string would be lexed as a single
+// identifier.
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if !isIdentifierRune(r) || (i == 0 && (unicode.IsDigit(r) || r == '-')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/boreq/bolt-ui/internal/config"
 )
 
+const accessTokenHeader = "Access-Token"
+
 type AuthProvider interface {
 	Check(r *http.Request) (bool, error)
 }
@@ -30,10 +32,25 @@ func (h *TokenAuthProvider) Check(r *http.Request) (bool, error) {
 		return false, errors.New("auth token is not set in the config")
 	}
 
-	token := r.Header.Get("Access-Token")
-	if token != h.conf.Token {
+	if r.Header.Get(accessTokenHeader) != h.conf.Token {
 		return false, nil
 	}
 
 	return true, nil
 }
+
+// withQueryToken returns a copy of the request with the access token header
+// set to the value of the token query parameter unless the header is already
+// set. It is only used by the watch endpoint as EventSource doesn't make it
+// possible to set headers, other endpoints don't accept tokens in URLs as
+// those end up in logs and the browser history.
+func withQueryToken(r *http.Request) *http.Request {
+	token := r.URL.Query().Get("token")
+	if token == "" || r.Header.Get(accessTokenHeader) != "" {
+		return r
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set(accessTokenHeader, token)
+	return r
+}
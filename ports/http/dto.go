@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/hex"
-	"unicode"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/boreq/bolt-ui/application"
 	"github.com/boreq/bolt-ui/display"
@@ -10,8 +12,10 @@ import (
 )
 
 type Tree struct {
-	Path    []Key   `json:"path"`
-	Entries []Entry `json:"entries"`
+	Path        []Key   `json:"path"`
+	Entries     []Entry `json:"entries"`
+	HasPrevious bool    `json:"has_prev"`
+	HasNext     bool    `json:"has_next"`
 }
 
 type Entry struct {
@@ -21,8 +25,16 @@ type Entry struct {
 }
 
 type Key struct {
-	Hex string `json:"hex"`
-	Str string `json:"str,omitempty"`
+	Hex     string      `json:"hex"`
+	Str     string      `json:"str,omitempty"`
+	Decoded *DecodedKey `json:"decoded,omitempty"`
+}
+
+// DecodedKey is present if a rule specified a key codec which displays keys
+// differently than as strings or hex.
+type DecodedKey struct {
+	Codec string `json:"codec"`
+	Value string `json:"value"`
 }
 
 type Value struct {
@@ -35,29 +47,432 @@ type Pretty struct {
 	Value       string `json:"value"`
 }
 
-func toTree(tree application.Tree) (Tree, error) {
-	entries, err := toEntries(tree.Entries)
+type SourceInfo struct {
+	SnapshotMode bool       `json:"snapshot_mode"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ReopenedAt   *time.Time `json:"reopened_at,omitempty"`
+}
+
+type NamedDatabase struct {
+	Name   string     `json:"name"`
+	Source SourceInfo `json:"source"`
+}
+
+type SearchResult struct {
+	Path  []Key `json:"path"`
+	Entry Entry `json:"entry"`
+}
+
+type QueryResult struct {
+	Columns []string   `json:"columns"`
+	Rows    []QueryRow `json:"rows"`
+}
+
+type QueryRow struct {
+	Path   []Key             `json:"path"`
+	Key    Key               `json:"key"`
+	Values []json.RawMessage `json:"values"`
+}
+
+type BucketStats struct {
+	BranchPageN       int `json:"branch_page_n"`
+	BranchOverflowN   int `json:"branch_overflow_n"`
+	LeafPageN         int `json:"leaf_page_n"`
+	LeafOverflowN     int `json:"leaf_overflow_n"`
+	KeyN              int `json:"key_n"`
+	Depth             int `json:"depth"`
+	BranchAlloc       int `json:"branch_alloc"`
+	BranchInuse       int `json:"branch_inuse"`
+	LeafAlloc         int `json:"leaf_alloc"`
+	LeafInuse         int `json:"leaf_inuse"`
+	BucketN           int `json:"bucket_n"`
+	InlineBucketN     int `json:"inline_bucket_n"`
+	InlineBucketInuse int `json:"inline_bucket_inuse"`
+}
+
+type DatabaseStats struct {
+	Buckets []NamedBucketStats `json:"buckets"`
+	Total   BucketStats        `json:"total"`
+}
+
+type NamedBucketStats struct {
+	Key   Key         `json:"key"`
+	Stats BucketStats `json:"stats"`
+}
+
+type DatabaseInfoReport struct {
+	Current DatabaseInfoSample   `json:"current"`
+	History []DatabaseInfoSample `json:"history"`
+}
+
+type DatabaseInfoSample struct {
+	Time time.Time    `json:"time"`
+	Info DatabaseInfo `json:"info"`
+}
+
+type DatabaseInfo struct {
+	FileSize      int64   `json:"file_size"`
+	PageSize      int     `json:"page_size"`
+	FreePageN     int     `json:"free_page_n"`
+	PendingPageN  int     `json:"pending_page_n"`
+	FreeAlloc     int     `json:"free_alloc"`
+	FreelistInuse int     `json:"freelist_inuse"`
+	TxN           int     `json:"tx_n"`
+	OpenTxN       int     `json:"open_tx_n"`
+	Tx            TxStats `json:"tx"`
+}
+
+// TxStats contains the transaction stats, durations are expressed in
+// nanoseconds.
+type TxStats struct {
+	PageCount     int64 `json:"page_count"`
+	PageAlloc     int64 `json:"page_alloc"`
+	CursorCount   int64 `json:"cursor_count"`
+	NodeCount     int64 `json:"node_count"`
+	NodeDeref     int64 `json:"node_deref"`
+	Rebalance     int64 `json:"rebalance"`
+	RebalanceTime int64 `json:"rebalance_time_ns"`
+	Split         int64 `json:"split"`
+	Spill         int64 `json:"spill"`
+	SpillTime     int64 `json:"spill_time_ns"`
+	Write         int64 `json:"write"`
+	WriteTime     int64 `json:"write_time_ns"`
+}
+
+type CheckProblem struct {
+	Problem string `json:"problem"`
+}
+
+type CompactRequest struct {
+	Destination string `json:"destination"`
+}
+
+// CompactionEvent has exactly one of its fields set.
+type CompactionEvent struct {
+	Progress *CompactionProgress `json:"progress,omitempty"`
+	Result   *CompactionResult   `json:"result,omitempty"`
+}
+
+type CompactionProgress struct {
+	CopiedBuckets int   `json:"copied_buckets"`
+	TotalBuckets  int   `json:"total_buckets"`
+	Size          int64 `json:"size"`
+}
+
+type CompactionResult struct {
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+}
+
+type ImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+
+	// Changes lists the created and updated entries. The list is
+	// truncated if there are too many of them.
+	Changes   []ImportChange `json:"changes"`
+	Truncated bool           `json:"truncated"`
+}
+
+type ImportChange struct {
+	Path   []Key  `json:"path"`
+	Key    Key    `json:"key"`
+	Bucket bool   `json:"bucket"`
+	Kind   string `json:"kind"`
+}
+
+type DiffEntry struct {
+	Path        []Key           `json:"path"`
+	Key         Key             `json:"key"`
+	Kind        string          `json:"kind"`
+	Old         *DiffEntryState `json:"old,omitempty"`
+	New         *DiffEntryState `json:"new,omitempty"`
+	Differences []Difference    `json:"differences,omitempty"`
+}
+
+type DiffEntryState struct {
+	Bucket   bool    `json:"bucket"`
+	Value    *Value  `json:"value,omitempty"`
+	Sequence *uint64 `json:"sequence,omitempty"`
+}
+
+// Difference describes an element of a structured value which differs
+// between the compared values.
+type Difference struct {
+	Path string          `json:"path"`
+	Kind string          `json:"kind"`
+	Old  json.RawMessage `json:"old,omitempty"`
+	New  json.RawMessage `json:"new,omitempty"`
+}
+
+type BucketChange struct {
+	Path  []Key  `json:"path"`
+	Kind  string `json:"kind"`
+	Entry Entry  `json:"entry"`
+}
+
+type PutRequest struct {
+	Hex string `json:"hex"`
+}
+
+type MoveRequest struct {
+	Destination string `json:"destination"`
+}
+
+func toTree(pretty *display.Pretty, tree application.Tree) (Tree, error) {
+	entries, err := toEntries(pretty, tree.Path, tree.Entries)
 	if err != nil {
 		return Tree{}, errors.Wrap(err, "error converting to entries")
 	}
 	return Tree{
-		Path:    toKeys(tree.Path),
-		Entries: entries,
+		Path:        toKeys(pretty, tree.Path),
+		Entries:     entries,
+		HasPrevious: tree.HasPrevious,
+		HasNext:     tree.HasNext,
 	}, nil
 }
 
-func toKeys(keys []application.Key) []Key {
+func toSourceInfo(info application.SourceInfo) SourceInfo {
+	result := SourceInfo{
+		SnapshotMode: info.SnapshotMode,
+		OpenedAt:     info.OpenedAt,
+	}
+
+	if !info.ReopenedAt.IsZero() {
+		result.ReopenedAt = &info.ReopenedAt
+	}
+
+	return result
+}
+
+func toSearchResult(pretty *display.Pretty, result application.SearchResult) (SearchResult, error) {
+	entry, err := toEntry(pretty, result.Path, result.Entry)
+	if err != nil {
+		return SearchResult{}, errors.Wrap(err, "error converting to an entry")
+	}
+
+	return SearchResult{
+		Path:  toKeys(pretty, result.Path),
+		Entry: entry,
+	}, nil
+}
+
+func toQueryRow(pretty *display.Pretty, result application.QueryResult) QueryRow {
+	values := make([]json.RawMessage, 0)
+	for _, value := range result.Values {
+		values = append(values, toRawJSON(value))
+	}
+
+	return QueryRow{
+		Path:   toKeys(pretty, result.Path),
+		Key:    toKey(pretty, result.Path, result.Key),
+		Values: values,
+	}
+}
+
+// toRawJSON falls back to encoding the value as a string if it can't be
+// encoded as JSON, for example because it is a NaN decoded from CBOR.
+func toRawJSON(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return b
+}
+
+func toBucketStats(stats application.BucketStats) BucketStats {
+	return BucketStats{
+		BranchPageN:       stats.BranchPageN,
+		BranchOverflowN:   stats.BranchOverflowN,
+		LeafPageN:         stats.LeafPageN,
+		LeafOverflowN:     stats.LeafOverflowN,
+		KeyN:              stats.KeyN,
+		Depth:             stats.Depth,
+		BranchAlloc:       stats.BranchAlloc,
+		BranchInuse:       stats.BranchInuse,
+		LeafAlloc:         stats.LeafAlloc,
+		LeafInuse:         stats.LeafInuse,
+		BucketN:           stats.BucketN,
+		InlineBucketN:     stats.InlineBucketN,
+		InlineBucketInuse: stats.InlineBucketInuse,
+	}
+}
+
+func toDatabaseStats(pretty *display.Pretty, stats application.DatabaseStats) DatabaseStats {
+	buckets := make([]NamedBucketStats, 0)
+	for _, bucket := range stats.Buckets {
+		buckets = append(buckets, NamedBucketStats{
+			Key:   toKey(pretty, nil, bucket.Key),
+			Stats: toBucketStats(bucket.Stats),
+		})
+	}
+
+	return DatabaseStats{
+		Buckets: buckets,
+		Total:   toBucketStats(stats.Total),
+	}
+}
+
+func toDatabaseInfoReport(report application.DatabaseInfoReport) DatabaseInfoReport {
+	history := make([]DatabaseInfoSample, 0)
+	for _, sample := range report.History {
+		history = append(history, toDatabaseInfoSample(sample))
+	}
+
+	return DatabaseInfoReport{
+		Current: toDatabaseInfoSample(report.Current),
+		History: history,
+	}
+}
+
+func toDatabaseInfoSample(sample application.DatabaseInfoSample) DatabaseInfoSample {
+	return DatabaseInfoSample{
+		Time: sample.Time,
+		Info: DatabaseInfo{
+			FileSize:      sample.Info.FileSize,
+			PageSize:      sample.Info.PageSize,
+			FreePageN:     sample.Info.FreePageN,
+			PendingPageN:  sample.Info.PendingPageN,
+			FreeAlloc:     sample.Info.FreeAlloc,
+			FreelistInuse: sample.Info.FreelistInuse,
+			TxN:           sample.Info.TxN,
+			OpenTxN:       sample.Info.OpenTxN,
+			Tx: TxStats{
+				PageCount:     sample.Info.Tx.PageCount,
+				PageAlloc:     sample.Info.Tx.PageAlloc,
+				CursorCount:   sample.Info.Tx.CursorCount,
+				NodeCount:     sample.Info.Tx.NodeCount,
+				NodeDeref:     sample.Info.Tx.NodeDeref,
+				Rebalance:     sample.Info.Tx.Rebalance,
+				RebalanceTime: sample.Info.Tx.RebalanceTime.Nanoseconds(),
+				Split:         sample.Info.Tx.Split,
+				Spill:         sample.Info.Tx.Spill,
+				SpillTime:     sample.Info.Tx.SpillTime.Nanoseconds(),
+				Write:         sample.Info.Tx.Write,
+				WriteTime:     sample.Info.Tx.WriteTime.Nanoseconds(),
+			},
+		},
+	}
+}
+
+func toCompactionProgress(progress application.CompactionProgress) *CompactionProgress {
+	return &CompactionProgress{
+		CopiedBuckets: progress.CopiedBuckets,
+		TotalBuckets:  progress.TotalBuckets,
+		Size:          progress.Size,
+	}
+}
+
+func toCompactionResult(result application.CompactionResult) *CompactionResult {
+	return &CompactionResult{
+		SizeBefore: result.SizeBefore,
+		SizeAfter:  result.SizeAfter,
+	}
+}
+
+func toImportChange(pretty *display.Pretty, change application.ImportChange) ImportChange {
+	return ImportChange{
+		Path:   toKeys(pretty, change.Path),
+		Key:    toKey(pretty, change.Path, change.Key),
+		Bucket: change.Bucket,
+		Kind:   change.Kind.String(),
+	}
+}
+
+func toDiffEntry(pretty *display.Pretty, entry application.DiffEntry) (DiffEntry, error) {
+	old, err := toDiffEntryState(pretty, entry.Path, entry.Old)
+	if err != nil {
+		return DiffEntry{}, errors.Wrap(err, "error converting the old state")
+	}
+
+	new, err := toDiffEntryState(pretty, entry.Path, entry.New)
+	if err != nil {
+		return DiffEntry{}, errors.Wrap(err, "error converting the new state")
+	}
+
+	var differences []Difference
+	for _, difference := range entry.Differences {
+		differences = append(differences, toDifference(difference))
+	}
+
+	return DiffEntry{
+		Path:        toKeys(pretty, entry.Path),
+		Key:         toKey(pretty, entry.Path, entry.Key),
+		Kind:        entry.Kind.String(),
+		Old:         old,
+		New:         new,
+		Differences: differences,
+	}, nil
+}
+
+func toDiffEntryState(pretty *display.Pretty, path []application.Key, state *application.DiffEntryState) (*DiffEntryState, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	if state.Bucket {
+		sequence := state.Sequence
+		return &DiffEntryState{
+			Bucket:   true,
+			Sequence: &sequence,
+		}, nil
+	}
+
+	value, err := toValue(pretty, path, state.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error converting to a value")
+	}
+
+	return &DiffEntryState{
+		Value: value,
+	}, nil
+}
+
+func toDifference(difference display.Difference) Difference {
+	result := Difference{
+		Path: difference.Path.String(),
+		Kind: difference.Kind.String(),
+	}
+
+	if difference.Kind != display.DifferenceKindAdded {
+		result.Old = toRawJSON(difference.Old)
+	}
+
+	if difference.Kind != display.DifferenceKindRemoved {
+		result.New = toRawJSON(difference.New)
+	}
+
+	return result
+}
+
+func toBucketChange(pretty *display.Pretty, path []application.Key, change application.BucketChange) (BucketChange, error) {
+	entry, err := toEntry(pretty, path, change.Entry)
+	if err != nil {
+		return BucketChange{}, errors.Wrap(err, "error converting to an entry")
+	}
+
+	return BucketChange{
+		Path:  toKeys(pretty, path),
+		Kind:  change.Kind.String(),
+		Entry: entry,
+	}, nil
+}
+
+// toKeys converts a path so each key is located in the bucket pointed to by
+// the preceding keys.
+func toKeys(pretty *display.Pretty, keys []application.Key) []Key {
 	result := make([]Key, 0)
-	for _, key := range keys {
-		result = append(result, toKey(key))
+	for i, key := range keys {
+		result = append(result, toKey(pretty, keys[:i], key))
 	}
 	return result
 }
 
-func toEntries(entries []application.Entry) ([]Entry, error) {
+func toEntries(pretty *display.Pretty, path []application.Key, entries []application.Entry) ([]Entry, error) {
 	result := make([]Entry, 0)
 	for _, entry := range entries {
-		v, err := toEntry(entry)
+		v, err := toEntry(pretty, path, entry)
 		if err != nil {
 			return nil, errors.Wrap(err, "error converting to an entry")
 		}
@@ -66,55 +481,62 @@ func toEntries(entries []application.Entry) ([]Entry, error) {
 	return result, nil
 }
 
-func toEntry(entry application.Entry) (Entry, error) {
-	value, err := toValue(entry.Value)
+func toEntry(pretty *display.Pretty, path []application.Key, entry application.Entry) (Entry, error) {
+	value, err := toValue(pretty, path, entry.Value)
 	if err != nil {
 		return Entry{}, errors.Wrap(err, "error converting to a value")
 	}
 
 	return Entry{
 		Bucket: entry.Bucket,
-		Key:    toKey(entry.Key),
+		Key:    toKey(pretty, path, entry.Key),
 		Value:  value,
 	}, nil
 }
 
-func toKey(key application.Key) Key {
+func toKey(pretty *display.Pretty, path []application.Key, key application.Key) Key {
 	b := key.Bytes()
 
 	result := Key{
 		Hex: hex.EncodeToString(b),
 	}
 
-	if canDisplayKeyAsString(b) {
-		result.Str = string(b)
+	prettified := pretty.PrintKeyInBucket(toBucketPath(path), b)
+	switch prettified.Codec {
+	case display.KeyCodecString:
+		result.Str = prettified.Value
+	case display.KeyCodecHex:
+	default:
+		result.Decoded = &DecodedKey{
+			Codec: prettified.Codec.String(),
+			Value: prettified.Value,
+		}
 	}
 
 	return result
 }
 
-func toValue(value application.Value) (*Value, error) {
+func toValue(pretty *display.Pretty, path []application.Key, value application.Value) (*Value, error) {
 	if value.IsEmpty() {
 		return nil, nil
 	}
 
 	b := value.Bytes()
 	hexB := hex.EncodeToString(b)
-	pretty, err := toPretty(value)
+	prettyValue, err := toPretty(pretty, path, value)
 	if err != nil {
 		return nil, errors.Wrap(err, "error converting to a pretty value")
 	}
 
 	return &Value{
 		Hex:    hexB,
-		Pretty: pretty,
+		Pretty: prettyValue,
 	}, nil
 }
 
-func toPretty(value application.Value) (*Pretty, error) {
+func toPretty(pretty *display.Pretty, path []application.Key, value application.Value) (*Pretty, error) {
 	b := value.Bytes()
-	pretty := display.NewPretty()
-	prettyPrinted, err := pretty.Print(b)
+	prettyPrinted, err := pretty.PrintInBucket(toBucketPath(path), b)
 	if err == nil {
 		encodedContentType, err := encodeContentType(prettyPrinted.Type)
 		if err != nil {
@@ -129,24 +551,17 @@ func toPretty(value application.Value) (*Pretty, error) {
 	return nil, nil
 }
 
-func encodeContentType(t display.ContentType) (string, error) {
-	switch t {
-	case display.ContentTypeJSON:
-		return "json", nil
-	case display.ContentTypeString:
-		return "string", nil
-	case display.ContentTypeCBOR:
-		return "cbor", nil
-	default:
-		return "", errors.New("unknown content type")
+func toBucketPath(path []application.Key) [][]byte {
+	result := make([][]byte, 0, len(path))
+	for _, key := range path {
+		result = append(result, key.Bytes())
 	}
+	return result
 }
 
-func canDisplayKeyAsString(b []byte) bool {
-	for _, rne := range string(b) {
-		if !unicode.IsGraphic(rne) && !unicode.IsSpace(rne) {
-			return false
-		}
+func encodeContentType(t display.ContentType) (string, error) {
+	if t.IsZero() {
+		return "", errors.New("unknown content type")
 	}
-	return true
+	return t.String(), nil
 }
@@ -1,31 +1,107 @@
 package frontend
 
 import (
+	"bytes"
 	"embed"
+	"html"
+	"io"
+	"io/fs"
 	"net/http"
+
+	"github.com/boreq/errors"
 )
 
 //go:embed css/* js/* index.html favicon.ico
 var content embed.FS
 
+const (
+	indexName   = "/index.html"
+	baseElement = `<base href="/">`
+)
+
 type FrontendFileSystem struct {
-	fs http.FileSystem
+	fs     http.FileSystem
+	prefix string
 }
 
-func NewFrontendFileSystem() (*FrontendFileSystem, error) {
+// NewFrontendFileSystem creates a file system serving the frontend. If the
+// prefix isn't empty then the base element of the index is rewritten so that
+// the frontend can be served under that prefix.
+func NewFrontendFileSystem(prefix string) (*FrontendFileSystem, error) {
 	return &FrontendFileSystem{
-		fs: http.FS(content),
+		fs:     http.FS(content),
+		prefix: prefix,
 	}, nil
 }
 
 func (f *FrontendFileSystem) Open(name string) (http.File, error) {
 	file, err := f.fs.Open(name)
 	if err != nil {
-		file, err := f.fs.Open("/index.html")
+		file, err := f.fs.Open(indexName)
 		if err != nil {
 			return nil, err
 		}
+		return f.rewrite(file, indexName)
+	}
+	return f.rewrite(file, name)
+}
+
+// rewrite replaces the base element of the index. The frontend is built so
+// that the paths of the assets and of the API are relative to the base
+// element and the router reads its base from it.
+func (f *FrontendFileSystem) rewrite(file http.File, name string) (http.File, error) {
+	if f.prefix == "" || name != indexName {
 		return file, nil
 	}
-	return file, nil
+
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Contains(b, []byte(baseElement)) {
+		return nil, errors.New("base element not found in the index")
+	}
+
+	b = bytes.Replace(b, []byte(baseElement), []byte(`<base href="`+html.EscapeString(f.prefix)+`/">`), 1)
+
+	return &rewrittenFile{
+		Reader: bytes.NewReader(b),
+		stat:   stat,
+		size:   int64(len(b)),
+	}, nil
+}
+
+type rewrittenFile struct {
+	*bytes.Reader
+	stat fs.FileInfo
+	size int64
+}
+
+func (f *rewrittenFile) Close() error {
+	return nil
+}
+
+func (f *rewrittenFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, fs.ErrInvalid
+}
+
+func (f *rewrittenFile) Stat() (fs.FileInfo, error) {
+	return rewrittenFileInfo{FileInfo: f.stat, size: f.size}, nil
+}
+
+type rewrittenFileInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (i rewrittenFileInfo) Size() int64 {
+	return i.size
 }
@@ -0,0 +1 @@
+(function(e){function t(t){for(var i,s,o=t[0],c=t[1],u=t[2],h=0,d=[];h<o.length;h++)s=o[h],Object.prototype.hasOwnProperty.call(a,s)&&a[s]&&d.push(a[s][0]),a[s]=0;for(i in c)Object.prototype.hasOwnProperty.call(c,i)&&(e[i]=c[i]);l&&l(t);while(d.length)d.shift()();return r.push.apply(r,u||[]),n()}function n(){for(var e,t=0;t<r.length;t++){for(var n=r[t],i=!0,o=1;o<n.length;o++){var c=n[o];0!==a[c]&&(i=!1)}i&&(r.splice(t--,1),e=s(s.s=n[0]))}return e}var i={},a={app:0},r=[];function s(t){if(i[t])return i[t].exports;var n=i[t]={i:t,l:!1,exports:{}};return e[t].call(n.exports,n,n.exports,s),n.l=!0,n.exports}s.m=e,s.c=i,s.d=function(e,t,n){s.o(e,t)||Object.defineProperty(e,t,{enumerable:!0,get:n})},s.r=function(e){"undefined"!==typeof Symbol&&Symbol.toStringTag&&Object.defineProperty(e,Symbol.toStringTag,{value:"Module"}),Object.defineProperty(e,"__esModule",{value:!0})},s.t=function(e,t){if(1&t&&(e=s(e)),8&t)return e;if(4&t&&"object"===typeof e&&e&&e.__esModule)return e;var n=Object.create(null);if(s.r(n),Object.defineProperty(n,"default",{enumerable:!0,value:e}),2&t&&"string"!=typeof e)for(var i in e)s.d(n,i,function(t){return e[t]}.bind(null,i));return n},s.n=function(e){var t=e&&e.__esModule?function(){return e["default"]}:function(){return e};return s.d(t,"a",t),t},s.o=function(e,t){return Object.prototype.hasOwnProperty.call(e,t)},s.p="/";var o=window["webpackJsonp"]=window["webpackJsonp"]||[],c=o.push.bind(o);o.push=t,o=o.slice();for(var u=0;u<o.length;u++)t(o[u]);var l=c;r.push([0,"chunk-vendors"]),n()})({0:function(e,t,n){e.exports=n("cd49")},"04e6":function(e,t,n){},"293e":function(e,t,n){"use strict";var i=n("def8"),a=n.n(i);a.a},"2cd4":function(e,t,n){"use strict";var i=n("c6e9"),a=n.n(i);a.a},"3a35":function(e,t,n){"use strict";var i=n("9c1f"),a=n.n(i);a.a},"64be":function(e,t,n){},7449:function(e,t,n){"use strict";var i=n("04e6"),a=n.n(i);a.a},"8d14":function(e,t,n){},9192:function(e,t,n){"use strict";var i=n("64be"),a=n.n(i);a.a},"92ec":function(e,t,n){},"9c1f":function(e,t,n){},"9d14":function(e,t,n){"use strict";var i=n("eaaa"),a=n.n(i);a.a},a4cc:function(e,t,n){"use strict";var i=n("92ec"),a=n.n(i);a.a},aacf:function(e,t,n){"use strict";var i=n("8d14"),a=n.n(i);a.a},c6e9:function(e,t,n){},cd49:function(e,t,n){"use strict";n.r(t);n("e260"),n("e6cf"),n("cca6"),n("a79d");var i,a=n("2b0e"),r=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{attrs:{id:"app"}},[n("div",{staticClass:"content"},[n("div",{staticClass:"container"},[n("router-view")],1)]),n("notifications",{staticClass:"notifications"})],1)},s=[],o=n("276c"),c=n("920b"),u=n("92a6"),l=n("9ab4"),h=n("1b40"),d=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("ul",{staticClass:"notifications"},e._l(e.notifications,(function(t){return n("li",{key:t.id,staticClass:"notification",class:[t.class,e.shouldHide(t)?"hide":""]},[n("div",{staticClass:"text"},[e._v(" "+e._s(t.text)+" ")]),t.extra?n("div",{staticClass:"extra"},[e._v(" "+e._s(t.extra)+" ")]):e._e()])})),0)},f=[],v=(n("4de4"),n("a434"),n("e954")),p=i=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){var e;return Object(o["a"])(this,n),e=t.apply(this,arguments),e.notifications=[],e}return Object(v["a"])(n,[{key:"mounted",value:function(){var e=this;this.$root.$on(i.notificationEvent,(function(t){e.notifications.splice(0,0,t)})),this.intervalID=window.setInterval(this.processErrors,100)}},{key:"destroyed",value:function(){window.clearInterval(this.intervalID)}},{key:"shouldHide",value:function(e){var t=this.duration(new Date,e.created);return t>i.visibilityDuration}},{key:"processErrors",value:function(){var e=this;this.notifications=this.notifications.filter((function(t){var n=e.duration(new Date,t.created);return n<i.visibilityDuration+i.animationDuration}))}},{key:"duration",value:function(e,t){return(e.getTime()-t.getTime())/1e3}}],[{key:"pushError",value:function(e,t,n){var i=n&&n.response&&n.response.data&&n.response.data.message?n.response.data.message:null,a={id:this.notificationId++,class:"error",created:new Date,text:t,extra:i};e.$root.$emit(this.notificationEvent,a)}},{key:"pushSuccess",value:function(e,t){var n={id:this.notificationId++,class:"success",created:new Date,text:t,extra:null};e.$root.$emit(this.notificationEvent,n)}}]),n}(h["d"]);p.notificationEvent="eggplant_notification",p.notificationId=0,p.visibilityDuration=10,p.animationDuration=2,p=i=Object(l["a"])([h["a"]],p);var y=p,b=y,k=(n("2cd4"),n("2877")),g=Object(k["a"])(b,d,f,!1,null,"fa2d66b2",null),m=g.exports,j=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){return Object(o["a"])(this,n),t.apply(this,arguments)}return n}(h["d"]);j=Object(l["a"])([Object(h["a"])({components:{Notifications:m}})],j);var O,x=j,_=x,w=(n("9d14"),Object(k["a"])(_,r,s,!1,null,null,null)),P=w.exports,C=n("8c4f"),E=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{staticClass:"browse"},[n("div",{staticClass:"top-bar"},[n("a",{staticClass:"main-header",on:{click:e.onHeaderClick}},[e._v("Bolt UI")]),e.selectedPath&&!e.editingSelectedPath?n("ul",{on:{click:function(t){return t.stopPropagation(),e.startEditing(t)}}},e._l(e.selectedPath,(function(e){return n("li",{key:e.hex},[n("key",{attrs:{k:e}})],1)})),0):e._e(),e.editingSelectedPath?n("div",{staticClass:"edit-path"},[n("input",{directives:[{name:"model",rawName:"v-model",value:e.editedPath,expression:"editedPath"}],staticClass:"path-input",domProps:{value:e.editedPath},on:{keyup:function(t){return!t.type.indexOf("key")&&e._k(t.keyCode,"enter",13,t.key,"Enter")?null:e.finishEditing(t)},click:function(e){e.stopPropagation()},input:function(t){t.target.composing||(e.editedPath=t.target.value)}}})]):e._e(),e.sourceInfo&&e.sourceInfo.snapshot_mode?n("div",{staticClass:"snapshot"},[e._v(" Snapshot taken at "+e._s(e.snapshotTakenAt)+" "),n("a",{class:{disabled:e.takingSnapshot},on:{click:e.takeSnapshot}},[n("i",{staticClass:"fas fa-sync-alt"})])]):e._e()]),n("div",{staticClass:"wrapper"},[e._l(e.paths,(function(t,i){return n("tree",{directives:[{name:"show",rawName:"v-show",value:e.isTreeVisible(i),expression:"isTreeVisible(index)"}],key:e.treeKey(t),attrs:{path:t,selected:e.selectedPath},on:{entry:function(n){return e.onEntry(t,n)},path:e.onPath}})})),e.selectedValue?n("value",{attrs:{entry:e.selectedValue}}):e._e()],2)])},S=[],T=(n("99af"),n("c975"),n("a15b"),n("d81d"),n("fb6a"),n("ac1f"),n("1276"),n("d0ff")),$=n("fc11"),I=n("2f62");a["a"].use(I["a"]),function(e){e["SetToken"]="setToken"}(O||(O={}));var K=new I["a"].Store({state:{token:void 0},mutations:Object($["a"])({},O.SetToken,(function(e,t){e.token=t}))}),V=function(){function e(){Object(o["a"])(this,e)}return Object(v["a"])(e,[{key:"getBrowse",value:function(e,t){var n=this.getQuery(t);if(0===e.length)return{name:"browse",query:n};var i=e.map((function(e){return e.hex})).join("/");return{name:"browse-children",params:{pathMatch:i},query:n}}},{key:"getQuery",value:function(e){return e?{value:e.hex}:null}}]),e}(),N=(n("caad"),n("d3b7"),n("25f0"),n("54f8")),M=function(){function e(){Object(o["a"])(this,e)}return Object(v["a"])(e,[{key:"marshal",value:function(e,t){var n,i=[],a=Object(N["a"])(e);try{for(a.s();!(n=a.n()).done;){var r=n.value;r.str?i.push(A+r.str+F):i.push(L+z+r.hex)}}catch(c){a.e(c)}finally{a.f()}var s=i.join(q+R+q);if(t){var o=q+Q+q;t.str?s+=o+t.str:s+=o+L+z+t.hex}return s}},{key:"unmarshal",value:function(e){for(var t=new B(e),n=[],i=X;i;)i=i(t,n);return this.convert(n)}},{key:"convert",value:function(e){var t,n={path:[],value:null},i=!1,a=Object(N["a"])(e);try{for(a.s();!(t=a.n()).done;){var r=t.value;if(D(r))r.bucket||(i=!0);else{if(n.value)throw"Encountered bucket after value.";r.hex||(r.hex=this.hexEncode(r.str)),i?n.value=r:n.path.push(r)}}}catch(s){a.e(s)}finally{a.f()}return n}},{key:"hexEncode",value:function(e){for(var t="",n=0;n<e.length;n++){var i=e.charCodeAt(n).toString(16);t+=i}return t}}]),e}(),B=function(){function e(t){Object(o["a"])(this,e),this.s=t,this.last=null}return Object(v["a"])(e,[{key:"next",value:function(){return 0===this.s.length?H:(this.last=this.s[0],this.s=this.s.slice(1),this.last)}},{key:"unread",value:function(){this.s?this.s=this.last+this.s:this.s=this.last}}]),e}();function D(e){return void 0!==e.bucket}var H=null,q=" ",R="/",A='"',F='"',L="0",z="x",J="X",Q="-",U="invalid path";function X(e){var t=e.next();switch(t){case q:return X;case A:return Z;case L:return te;case H:return null;default:throw U}}function G(e){var t=e.next();switch(t){case q:return G;case R:return W;case Q:return re;case H:return null;default:throw U}}function W(e,t){return t.push({bucket:!0}),Y}function Y(e){var t=e.next();switch(t){case q:return Y;case A:return Z;case L:return te;default:throw U}}function Z(e,t){return t.push({hex:null,str:""}),ee}function ee(e,t){var n=e.next();switch(n){case F:return G;case H:throw U;default:return t[t.length-1].str+=n,ee}}function te(e){var t=e.next();switch(t){case z:case J:return ne;default:throw U}}function ne(e,t){return t.push({hex:"",str:null}),ae}var ie=["0","1","2","3","4","5","6","7","8","9","a","b","c","d","e","f"];function ae(e,t){var n=e.next();switch(n){case H:return null}return ie.includes(n.toLowerCase())?(t[t.length-1].hex+=n,ae):(e.unread(),G)}function re(e,t){return t.push({bucket:!1}),Y}var se=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{ref:"tree",staticClass:"tree",on:{scroll:e.onScroll}},[e.loadingPrevious?n("spinner",{staticClass:"previous-spinner"}):e._e(),e.tree?n("entries",{attrs:{entries:e.tree.entries,selected:e.selectedInTree},on:{entry:function(t){return e.onEntry(t)}}}):e._e(),e.loadingNext?n("spinner",{staticClass:"next-spinner"}):e._e(),e.tree?e._e():n("spinner",{staticClass:"main-spinner"})],1)},oe=[],ce=(n("ddb0"),n("2c4c")),ue=n("bc3a"),le=n.n(ue),he="Access-Token",de=function(){function e(t){var n=this;Object(o["a"])(this,e),this.vue=t,this.axios=le.a.create(),this.axios.interceptors.request.use((function(e){var t=n.vue.$store.state.token;return t&&(e.headers[he]=t),e}),(function(e){return Promise.reject(e)})),this.axios.interceptors.response.use((function(e){return e}),(function(e){return e.response&&401===e.response.status&&n.vue.$store.commit(O.SetToken,null),Promise.reject(e)}))}return Object(v["a"])(e,[{key:"browse",value:function(e,t,n,i){var a=e?"browse/".concat(e):"browse/";return this.axios.get("/api/"+a,{params:this.browseParams(t,n,i)})}},{key:"sourceInfo",value:function(){return this.axios.get("/api/source")}},{key:"takeSnapshot",value:function(){return this.axios.post("/api/snapshot")}},{key:"browseParams",value:function(e,t,n){return e?{before:e}:t?{after:t}:n?{from:n}:null}}]),e}(),fe=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{staticClass:"entries"},[n("ul",e._l(e.entries,(function(t){return n("li",{key:t.key.hex},[n("a",{class:{selected:e.selected===t},on:{click:function(n){return e.onClick(t)}}},[n("span",{staticClass:"icon"},[t.bucket?n("i",{staticClass:"fas fa-folder"}):n("i",{staticClass:"fas fa-file"})]),n("key",{attrs:{k:t.key}})],1)])})),0),e.isEmpty?n("div",{staticClass:"empty-message"},[e._v(" This bucket is empty. ")]):e._e()])},ve=[],pe=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{staticClass:"key"},[e.k.str?n("span",[n("span",{staticClass:"decoration"},[e._v('"')]),e._v(e._s(e.k.str)),n("span",{staticClass:"decoration"},[e._v('"')])]):n("span",[n("span",{staticClass:"decoration"},[e._v("0x")]),e._v(e._s(e.k.hex)+" ")])])},ye=[],be=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){return Object(o["a"])(this,n),t.apply(this,arguments)}return n}(h["d"]);Object(l["a"])([Object(h["b"])()],be.prototype,"k",void 0),be=Object(l["a"])([h["a"]],be);var ke=be,ge=ke,me=(n("3a35"),Object(k["a"])(ge,pe,ye,!1,null,"47160388",null)),je=me.exports,Oe=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){return Object(o["a"])(this,n),t.apply(this,arguments)}return Object(v["a"])(n,[{key:"onClick",value:function(e){this.$emit("entry",e)}},{key:"isEmpty",get:function(){return this.entries&&0===this.entries.length}}]),n}(h["d"]);Object(l["a"])([Object(h["b"])()],Oe.prototype,"entries",void 0),Object(l["a"])([Object(h["b"])()],Oe.prototype,"selected",void 0),Oe=Object(l["a"])([Object(h["a"])({components:{Key:je}})],Oe);var xe=Oe,_e=xe,we=(n("a4cc"),Object(k["a"])(_e,fe,ve,!1,null,"5935002b",null)),Pe=we.exports,Ce=function(){var e=this,t=e.$createElement;e._self._c;return e._m(0)},Ee=[function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{staticClass:"spinner"},[n("i",{staticClass:"fas fa-circle-notch fa-spin"})])}],Se=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){return Object(o["a"])(this,n),t.apply(this,arguments)}return n}(h["d"]);Se=Object(l["a"])([h["a"]],Se);var Te=Se,$e=Te,Ie=(n("aacf"),Object(k["a"])($e,Ce,Ee,!1,null,"5bbc4aac",null)),Ke=Ie.exports,Ve=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){var e;return Object(o["a"])(this,n),e=t.apply(this,arguments),e.tree=null,e.apiService=new de(Object(ce["a"])(e)),e.loadThresholdInPixels=50,e.loadingPrevious=!1,e.noMoreBefore=!1,e.loadingNext=!1,e.noMoreAfter=!1,e}return Object(v["a"])(n,[{key:"onPathChanged",value:function(){this.tryEmitSelected(),this.tryEmitPath()}},{key:"onSelectedChanged",value:function(){this.tryEmitSelected()}},{key:"tryEmitSelected",value:function(){if(!this.selected||!this.tree)return null;if(this.path.length===this.selected.length-1){var e,t=Object(N["a"])(this.tree.entries);try{for(t.s();!(e=t.n()).done;){var n=e.value;n.bucket||n.key.hex===this.selected[this.selected.length-1].hex&&this.$emit("entry",n)}}catch(i){t.e(i)}finally{t.f()}return null}}},{key:"created",value:function(){this.loadSelected()}},{key:"onScroll",value:function(){this.loadMoreEntriesIfNeeded()}},{key:"onEntry",value:function(e){this.emitEntry(e)}},{key:"loadSelected",value:function(){var e=this.selectedKeyInThisBucket,t=e?e.hex:null;this.load(t)}},{key:"load",value:function(e){var t=this;this.tree=null,this.apiService.browse(this.stringPath,null,null,e).then((function(n){t.tree=n.data,t.noMoreBefore=!n.data.has_prev,t.noMoreAfter=!n.data.has_next,t.loadMoreEntriesIfNeeded(),t.tryEmitPath(),t.tryEmitSelected(),0===t.tree.entries.length&&e&&t.load(null)}),(function(e){m.pushError(t,"Could not query the backend.",e)}))}},{key:"loadMoreEntriesIfNeeded",value:function(){var e=this.domTree.scrollTop,t=this.domTree.scrollHeight,n=this.domTree.clientHeight;e<this.loadThresholdInPixels&&this.loadPreviousIfNeeded(),n+e>t-this.loadThresholdInPixels&&this.loadNextIfNeeded()}},{key:"loadPreviousIfNeeded",value:function(){var e=this;if(!this.loadingPrevious&&!this.noMoreBefore){var t=this.firstKey;t&&(this.loadingPrevious=!0,this.apiService.browse(this.stringPath,t.hex,null,null).then((function(n){var i=e.firstKey;i.hex===t.hex&&(e.noMoreBefore=!n.data.has_prev,e.tree.entries=[].concat(Object(T["a"])(e.tree.entries),Object(T["a"])(n.data.entries)))}),(function(t){m.pushError(e,"Could not query the backend.",t)})).finally((function(){e.loadingPrevious=!1,e.loadMoreEntriesIfNeeded()})))}}},{key:"loadNextIfNeeded",value:function(){var e=this;if(!this.loadingNext&&!this.noMoreAfter){var t=this.lastKey;t&&(this.loadingNext=!0,this.apiService.browse(this.stringPath,null,t.hex,null).then((function(n){var i=e.lastKey;i.hex===t.hex&&(e.noMoreAfter=!n.data.has_next,e.tree.entries=[].concat(Object(T["a"])(e.tree.entries),Object(T["a"])(n.data.entries)))}),(function(t){m.pushError(e,"Could not query the backend.",t)})).finally((function(){e.loadingNext=!1,e.loadMoreEntriesIfNeeded()})))}}},{key:"pathHasPrefix",value:function(e,t){if(t.length>e.length)return!1;for(var n=0;n<t.length;n++)if(t[n].hex!==e[n].hex)return!1;return!0}},{key:"tryEmitPath",value:function(){this.tree&&this.$emit("path",this.tree.path)}},{key:"emitEntry",value:function(e){this.$emit("entry",e)}},{key:"selectedInTree",get:function(){if(!this.selected||!this.tree)return null;var e,t=Object(N["a"])(this.tree.entries);try{for(t.s();!(e=t.n()).done;){var n=e.value,i=[].concat(Object(T["a"])(this.path),[n.key]);if(this.pathHasPrefix(this.selected,i))return n}}catch(a){t.e(a)}finally{t.f()}return null}},{key:"stringPath",get:function(){return this.path.map((function(e){return e.hex})).join("/")}},{key:"firstKey",get:function(){return this.tree&&0!==this.tree.entries.length?this.tree.entries[0].key:null}},{key:"lastKey",get:function(){return this.tree&&0!==this.tree.entries.length?this.tree.entries[this.tree.entries.length-1].key:null}},{key:"selectedKeyInThisBucket",get:function(){return this.selected.length>=this.path.length?this.selected[this.path.length]:null}}]),n}(h["d"]);Object(l["a"])([Object(h["b"])()],Ve.prototype,"path",void 0),Object(l["a"])([Object(h["b"])()],Ve.prototype,"selected",void 0),Object(l["a"])([Object(h["c"])("tree")],Ve.prototype,"domTree",void 0),Object(l["a"])([Object(h["e"])("path")],Ve.prototype,"onPathChanged",null),Object(l["a"])([Object(h["e"])("selected")],Ve.prototype,"onSelectedChanged",null),Ve=Object(l["a"])([Object(h["a"])({components:{Entries:Pe,Spinner:Ke}})],Ve);var Ne=Ve,Me=Ne,Be=(n("293e"),Object(k["a"])(Me,se,oe,!1,null,"7d9d6f16",null)),De=Be.exports,He=function(){var e=this,t=e.$createElement,n=e._self._c||t;return n("div",{staticClass:"value"},[n("div",{staticClass:"header"},[n("i",{staticClass:"fas fa-file"}),n("key",{attrs:{k:e.entry.key}}),n("div",{staticClass:"format-note"},[n("span",{directives:[{name:"tooltip",rawName:"v-tooltip",value:e.formatTooltip,expression:"formatTooltip"}]},[e._v("("+e._s(e.format)+")")])])],1),e.entry.value?n("div",{staticClass:"value-string"},[e.valuePretty?n("div",[n("div",{staticClass:"value-header"},[e._v(" Pretty printed ")]),n("pre",{staticClass:"value-string"},[n("code",[e._v(e._s(e.valuePretty))])])]):e._e(),n("div",{staticClass:"value-header"},[e._v(" Raw value as hex ")]),n("pre",{staticClass:"value-string"},[n("code",[e._v(e._s(e.valueHex))])])]):n("div",{staticClass:"value-empty"},[e._v(" This value is not set. ")])])},qe=[],Re=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){return Object(o["a"])(this,n),t.apply(this,arguments)}return Object(v["a"])(n,[{key:"format",get:function(){return this.entry.value?this.entry.value.pretty?this.entry.value.pretty.content_type:"unknown":"nil"}},{key:"formatTooltip",get:function(){return this.entry.value?this.entry.value.pretty?"Recognized content type ".concat(this.entry.value.pretty.content_type," for pretty printing."):"Pretty printing is unavailable due to unrecognized content type of this value.":"The value is empty."}},{key:"valuePretty",get:function(){return this.entry.value&&this.entry.value.pretty?this.entry.value.pretty.value:null}},{key:"valueHex",get:function(){return this.entry.value?this.entry.value.hex:null}}]),n}(h["d"]);Object(l["a"])([Object(h["b"])()],Re.prototype,"entry",void 0),Re=Object(l["a"])([Object(h["a"])({components:{Key:je}})],Re);var Ae=Re,Fe=Ae,Le=(n("9192"),Object(k["a"])(Fe,He,qe,!1,null,"05bf023a",null)),ze=Le.exports,Je=function(e){Object(c["a"])(n,e);var t=Object(u["a"])(n);function n(){var e;return Object(o["a"])(this,n),e=t.apply(this,arguments),e.paths=[],e.selectedValueKey=null,e.selectedValue=null,e.editingSelectedPath=!1,e.editedPath=null,e.sourceInfo=null,e.takingSnapshot=!1,e.snapshotsTaken=0,e.apiService=new de(Object(ce["a"])(e)),e.navigationService=new V,e.pathService=new M,e.numVisibleTrees=3,e}return Object(v["a"])(n,[{key:"isTreeVisible",value:function(e){var t=this.paths.length-this.numVisibleTrees;return this.selectedValueKey&&t++,e>=t}},{key:"onRouteChanged",value:function(){this.setToken(),this.loadFromRoute()}},{key:"created",value:function(){this.setToken(),this.loadFromRoute(),this.loadSourceInfo(),document.body.addEventListener("click",this.cancelEditing)}},{key:"destroyed",value:function(){document.body.removeEventListener("click",this.cancelEditing)}},{key:"treeKey",value:function(e){var t=e.map((function(e){return e.hex})).join("-");return"".concat(this.snapshotsTaken,"-").concat(t)}},{key:"takeSnapshot",value:function(){var e=this;this.takingSnapshot||(this.takingSnapshot=!0,this.apiService.takeSnapshot().then((function(){m.pushSuccess(e,"Snapshot taken."),e.snapshotsTaken++,e.loadSourceInfo()})).catch((function(t){m.pushError(e,"Could not take a snapshot.",t)})).finally((function(){e.takingSnapshot=!1})))}},{key:"onHeaderClick",value:function(){this.loadBlank()}},{key:"onEntry",value:function(e,t){var n=this.paths.indexOf(e);if(n>=0&&(this.paths.length=n+1),t.bucket){var i=[].concat(Object(T["a"])(e),[t.key]);this.paths.push(i),this.selectedValueKey=null;var a=this.navigationService.getBrowse(i,null);this.$router.push(a)}else{var r,s,o=(null===(r=this.selectedValueKey)||void 0===r?void 0:r.hex)!==(null===(s=t.key)||void 0===s?void 0:s.hex);if(this.selectedValue=t,this.selectedValueKey=t.key,o){var c=this.navigationService.getBrowse(e,t.key);this.$router.push(c)}}}},{key:"onPath",value:function(e){for(var t=e.length,n=0;n<e.length;n++)this.paths[t][n].str=e[n].str}},{key:"startEditing",value:function(){this.paths.length>0&&(this.editedPath=this.pathService.marshal(this.paths[this.paths.length-1],this.selectedValueKey)),this.editingSelectedPath=!0}},{key:"finishEditing",value:function(){try{var e=this.pathService.unmarshal(this.editedPath);this.loadBlank();for(var t=1;t<=e.path.length;t++)this.paths.push(e.path.slice(0,t));this.selectedValueKey=e.value,this.editingSelectedPath=!1}catch(n){m.pushError(this,"Invalid path.",n)}}},{key:"cancelEditing",value:function(){this.editingSelectedPath=!1}},{key:"setToken",value:function(){var e=this.$route.query.token;e&&this.$store.commit(O.SetToken,e)}},{key:"loadSourceInfo",value:function(){var e=this;this.apiService.sourceInfo().then((function(t){e.sourceInfo=t.data})).catch((function(t){m.pushError(e,"Could not query the backend.",t)}))}},{key:"loadBlank",value:function(){this.paths=[[]],this.selectedValueKey=null,this.selectedValue=null}},{key:"loadFromRoute",value:function(){this.loadBlank();for(var e=this.$route.params.pathMatch.split("/").filter((function(e){return""!==e})).map((function(e){return{hex:e,str:null}})),t=1;t<=e.length;t++)this.paths.push(e.slice(0,t));this.$route.query.value&&(this.selectedValueKey={hex:this.$route.query.value,str:null})}},{key:"selectedPath",get:function(){if(0===this.paths.length)return null;var e=Object(T["a"])(this.paths[this.paths.length-1]);return this.selectedValueKey&&e.push(this.selectedValueKey),e}},{key:"snapshotTakenAt",get:function(){return this.sourceInfo?new Date(this.sourceInfo.opened_at).toLocaleString():null}}]),n}(h["d"]);Object(l["a"])([Object(h["e"])("$route")],Je.prototype,"onRouteChanged",null),Je=Object(l["a"])([Object(h["a"])({components:{Tree:De,Value:ze,Key:je}})],Je);var Qe=Je,Ue=Qe,Xe=(n("7449"),Object(k["a"])(Ue,E,S,!1,null,"323876f5",null)),Ge=Xe.exports;a["a"].use(C["a"]);var We=new C["a"]({mode:"history",base:"/",routes:[{path:"/*",name:"browse-children",component:Ge},{path:"/",name:"browse",component:Ge},{path:"*",redirect:{name:"browse"}}]}),Ye=n("e37d");a["a"].use(Ye["a"]),a["a"].config.productionTip=!1,new a["a"]({router:We,store:K,render:function(e){return e(P)}}).$mount("#app")},def8:function(e,t,n){},eaaa:function(e,t,n){}});
\ No newline at end of file
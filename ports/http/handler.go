@@ -1,11 +1,20 @@
 package http
 
 import (
+	"context"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/boreq/bolt-ui/application"
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/jsonlines"
+	"github.com/boreq/bolt-ui/jsonpath"
 	"github.com/boreq/bolt-ui/logging"
 	"github.com/boreq/bolt-ui/ports/http/frontend"
 	"github.com/boreq/errors"
@@ -13,24 +22,73 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+// Database is an application serving a single database file.
+type Database struct {
+	// Name is used to refer to the database in the API.
+	Name        string
+	Application *application.Application
+}
+
 type Handler struct {
-	app          *application.Application
+	databases    []Database
 	authProvider AuthProvider
+	pageSize     int
+	pretty       *display.Pretty
 	router       *httprouter.Router
 	log          logging.Logger
 }
 
-func NewHandler(app *application.Application, authProvider AuthProvider) (*Handler, error) {
+// NewHandler creates a handler serving the provided databases. The API of
+// each database is available under /api/db/{name}/. The API of the first
+// database is also available directly under /api/. The prettifiers and the
+// rules registered in the display package are used only if they were
+// registered before the handler was created.
+func NewHandler(databases []Database, authProvider AuthProvider, conf *config.Config) (*Handler, error) {
+	if len(databases) == 0 {
+		return nil, errors.New("no databases")
+	}
+
+	pageSize := application.DefaultLimit
+	if conf.PageSize != 0 {
+		if conf.PageSize < 0 || conf.PageSize > application.MaxLimit {
+			return nil, errors.New("page size is out of range")
+		}
+		pageSize = conf.PageSize
+	}
+
 	h := &Handler{
-		app:          app,
+		databases:    databases,
 		authProvider: authProvider,
+		pageSize:     pageSize,
+		pretty:       display.NewPretty(),
 		router:       httprouter.New(),
 		log:          logging.New("ports/http.Handler"),
 	}
 
-	h.router.HandlerFunc(http.MethodGet, "/api/browse/*path", rest.Wrap(h.browse))
+	h.router.HandlerFunc(http.MethodGet, "/api/db", rest.Wrap(h.listDatabases))
+
+	h.handleDatabase(http.MethodGet, "/browse/*path", rest.Wrap(h.browse))
+	h.handleDatabase(http.MethodPut, "/browse/*path", rest.Wrap(h.put))
+	h.handleDatabase(http.MethodDelete, "/browse/*path", rest.Wrap(h.delete))
+	h.handleDatabase(http.MethodPost, "/buckets/*path", rest.Wrap(h.createBucket))
+	h.handleDatabase(http.MethodDelete, "/buckets/*path", rest.Wrap(h.deleteBucket))
+	h.handleDatabase(http.MethodPost, "/move/*path", rest.Wrap(h.moveBucket))
+	h.handleDatabase(http.MethodGet, "/source", rest.Wrap(h.sourceInfo))
+	h.handleDatabase(http.MethodPost, "/snapshot", rest.Wrap(h.takeSnapshot))
+	h.handleDatabase(http.MethodPost, "/reopen", rest.Wrap(h.reopen))
+	h.handleDatabase(http.MethodGet, "/search/*path", h.search)
+	h.handleDatabase(http.MethodGet, "/query/*path", rest.Wrap(h.query))
+	h.handleDatabase(http.MethodGet, "/stats/*path", rest.Wrap(h.stats))
+	h.handleDatabase(http.MethodGet, "/database", rest.Wrap(h.databaseInfo))
+	h.handleDatabase(http.MethodGet, "/check", h.check)
+	h.handleDatabase(http.MethodPost, "/compact", h.compact)
+	h.handleDatabase(http.MethodGet, "/backup", h.backup)
+	h.handleDatabase(http.MethodGet, "/export/*path", h.export)
+	h.handleDatabase(http.MethodPost, "/import/*path", rest.Wrap(h.importRecords))
+	h.handleDatabase(http.MethodGet, "/diff/*path", h.diff)
+	h.handleDatabase(http.MethodGet, "/watch/*path", h.watch)
 
-	ffs, err := frontend.NewFrontendFileSystem()
+	ffs, err := frontend.NewFrontendFileSystem(conf.URLPrefix)
 	if err != nil {
 		return nil, err
 	}
@@ -43,17 +101,460 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.router.ServeHTTP(w, r)
 }
 
+type applicationContextKey struct{}
+
+// handleDatabase registers the handler both under the namespace of each
+// database and directly under /api/ for the first database.
+func (h *Handler) handleDatabase(method, path string, handler http.HandlerFunc) {
+	handler = h.withDatabase(handler)
+	h.router.HandlerFunc(method, "/api"+path, handler)
+	h.router.HandlerFunc(method, "/api/db/:database"+path, handler)
+}
+
+// withDatabase selects the application serving the database specified in the
+// path so that it can be retrieved using app.
+func (h *Handler) withDatabase(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ps := httprouter.ParamsFromContext(r.Context())
+
+		app, ok := h.findApplication(ps.ByName("database"))
+		if !ok {
+			if response := h.checkAuth(r); response != nil {
+				h.writeResponse(w, r, response)
+				return
+			}
+			h.writeResponse(w, r, rest.ErrNotFound.WithMessage("Database not found."))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), applicationContextKey{}, app)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// findApplication returns the application serving the first database if the
+// name is empty.
+func (h *Handler) findApplication(name string) (*application.Application, bool) {
+	if name == "" {
+		return h.databases[0].Application, true
+	}
+
+	for _, database := range h.databases {
+		if database.Name == name {
+			return database.Application, true
+		}
+	}
+
+	return nil, false
+}
+
+// app returns the application selected by withDatabase.
+func (h *Handler) app(r *http.Request) *application.Application {
+	return r.Context().Value(applicationContextKey{}).(*application.Application)
+}
+
+func (h *Handler) listDatabases(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	databases := make([]NamedDatabase, 0)
+	for _, database := range h.databases {
+		info, err := database.Application.GetSourceInfo.Execute(application.GetSourceInfo{})
+		if err != nil {
+			return h.errorResponse(err, "get source info failure")
+		}
+
+		databases = append(databases, NamedDatabase{
+			Name:   database.Name,
+			Source: toSourceInfo(info),
+		})
+	}
+
+	return rest.NewResponse(databases)
+}
+
 func (h *Handler) browse(r *http.Request) rest.RestResponse {
 	ps := httprouter.ParamsFromContext(r.Context())
 
-	ok, err := h.authProvider.Check(r)
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, err := readPath(ps.ByName("path"))
 	if err != nil {
-		h.log.Error("auth provider get failed", "err", err)
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	before, err := readKeyQueryParam(r, "before")
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid before query param.")
+	}
+
+	after, err := readKeyQueryParam(r, "after")
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid after query param.")
+	}
+
+	from, err := readKeyQueryParam(r, "from")
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid from query param.")
+	}
+
+	prefix, err := readKeyQueryParam(r, "prefix")
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid prefix query param.")
+	}
+
+	limit := h.pageSize
+
+	if limitString := r.URL.Query().Get("limit"); limitString != "" {
+		limit, err = strconv.Atoi(limitString)
+		if err != nil {
+			return rest.ErrBadRequest.WithMessage("Invalid limit query param.")
+		}
+	}
+
+	query, err := application.NewBrowse(path, before, after, from, prefix, limit)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	tree, err := h.app(r).Browse.Execute(query)
+	if err != nil {
+		if errors.Is(err, application.ErrBucketNotFound) {
+			return rest.ErrNotFound
+		}
+		h.log.Error("browse failure", "err", err)
 		return rest.ErrInternalServerError
 	}
 
-	if !ok {
-		return rest.ErrForbidden.WithMessage("Invalid token.")
+	transportTree, err := toTree(h.pretty, tree)
+	if err != nil {
+		h.log.Error("error converting to a tree", "err", err)
+		return rest.ErrInternalServerError
+	}
+	return rest.NewResponse(transportTree)
+}
+
+func (h *Handler) put(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	var t PutRequest
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		return rest.ErrBadRequest.WithMessage("Malformed input.")
+	}
+
+	b, err := hex.DecodeString(t.Hex)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid value.")
+	}
+
+	value, err := application.NewValue(b)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid value.")
+	}
+
+	cmd, err := application.NewPut(path, key, value)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	if err := h.app(r).Put.Execute(cmd); err != nil {
+		return h.errorResponse(err, "put failure")
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) delete(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, key, err := readPathAndKey(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	cmd, err := application.NewDelete(path, key)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	if err := h.app(r).Delete.Execute(cmd); err != nil {
+		return h.errorResponse(err, "delete failure")
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) createBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	cmd, err := application.NewCreateBucket(path)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	if err := h.app(r).CreateBucket.Execute(cmd); err != nil {
+		return h.errorResponse(err, "create bucket failure")
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) deleteBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	cmd, err := application.NewDeleteBucket(path)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	if err := h.app(r).DeleteBucket.Execute(cmd); err != nil {
+		return h.errorResponse(err, "delete bucket failure")
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) moveBucket(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	source, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	var t MoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		return rest.ErrBadRequest.WithMessage("Malformed input.")
+	}
+
+	destination, err := readPath(t.Destination)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid destination.")
+	}
+
+	cmd, err := application.NewMoveBucket(source, destination)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	if err := h.app(r).MoveBucket.Execute(cmd); err != nil {
+		return h.errorResponse(err, "move bucket failure")
+	}
+
+	return rest.NewResponse(nil)
+}
+
+func (h *Handler) sourceInfo(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	info, err := h.app(r).GetSourceInfo.Execute(application.GetSourceInfo{})
+	if err != nil {
+		return h.errorResponse(err, "get source info failure")
+	}
+
+	return rest.NewResponse(toSourceInfo(info))
+}
+
+func (h *Handler) takeSnapshot(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	if err := h.app(r).TakeSnapshot.Execute(application.TakeSnapshot{}); err != nil {
+		return h.errorResponse(err, "take snapshot failure")
+	}
+
+	return rest.NewResponse(nil)
+}
+
+// reopen returns the source info so that the client can display the reopen
+// time.
+func (h *Handler) reopen(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	if err := h.app(r).Reopen.Execute(application.Reopen{}); err != nil {
+		return h.errorResponse(err, "reopen failure")
+	}
+
+	info, err := h.app(r).GetSourceInfo.Execute(application.GetSourceInfo{})
+	if err != nil {
+		return h.errorResponse(err, "get source info failure")
+	}
+
+	return rest.NewResponse(toSourceInfo(info))
+}
+
+// search streams the results as JSON Lines to avoid making the client wait
+// until the entire bucket is searched.
+func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		h.writeResponse(w, r, response)
+		return
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid path."))
+		return
+	}
+
+	query, err := readSearch(r, path)
+	if err != nil {
+		h.log.Debug("invalid search", "err", err)
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid parameters."))
+		return
+	}
+
+	var written bool
+	encoder := json.NewEncoder(w)
+
+	if err := h.app(r).Search.Execute(r.Context(), query, func(result application.SearchResult) error {
+		transportResult, err := toSearchResult(h.pretty, result)
+		if err != nil {
+			return errors.Wrap(err, "error converting to a search result")
+		}
+
+		if !written {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			written = true
+		}
+
+		if err := encoder.Encode(transportResult); err != nil {
+			return errors.Wrap(err, "error encoding the result")
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return nil
+	}); err != nil {
+		if written {
+			h.log.Warn("search interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "search failure"))
+		return
+	}
+
+	if !written {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+}
+
+func readSearch(r *http.Request, path []application.Key) (application.Search, error) {
+	params := r.URL.Query()
+
+	matcher, err := readMatcher(params.Get("mode"), params.Get("query"))
+	if err != nil {
+		return application.Search{}, errors.Wrap(err, "could not create a matcher")
+	}
+
+	var keys, values bool
+	switch params.Get("target") {
+	case "keys":
+		keys = true
+	case "values":
+		values = true
+	case "", "all":
+		keys = true
+		values = true
+	default:
+		return application.Search{}, errors.New("invalid target")
+	}
+
+	var recursive bool
+	if recursiveString := params.Get("recursive"); recursiveString != "" {
+		recursive, err = strconv.ParseBool(recursiveString)
+		if err != nil {
+			return application.Search{}, errors.Wrap(err, "invalid recursive")
+		}
+	}
+
+	limit := application.DefaultSearchLimit
+	if limitString := params.Get("limit"); limitString != "" {
+		limit, err = strconv.Atoi(limitString)
+		if err != nil {
+			return application.Search{}, errors.Wrap(err, "invalid limit")
+		}
+	}
+
+	return application.NewSearch(path, matcher, keys, values, recursive, limit)
+}
+
+func readMatcher(mode, query string) (application.Matcher, error) {
+	switch mode {
+	case "", "substring":
+		return application.NewSubstringMatcher(query)
+	case "regexp":
+		return application.NewRegexpMatcher(query)
+	case "hex":
+		b, err := hex.DecodeString(query)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode")
+		}
+		return application.NewBytesMatcher(b)
+	default:
+		return nil, errors.New("invalid mode")
+	}
+}
+
+func (h *Handler) query(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
 	}
 
 	path, err := readPath(ps.ByName("path"))
@@ -62,61 +563,591 @@ func (h *Handler) browse(r *http.Request) rest.RestResponse {
 		return rest.ErrBadRequest.WithMessage("Invalid path.")
 	}
 
-	var before *application.Key
-	var after *application.Key
+	params := r.URL.Query()
 
-	if beforeString := r.URL.Query().Get("before"); beforeString != "" {
-		b, err := hex.DecodeString(beforeString)
+	var filter *jsonpath.Expression
+	if filterString := params.Get("filter"); filterString != "" {
+		expression, err := jsonpath.ParseExpression(filterString)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid before query param.")
+			return rest.ErrBadRequest.WithMessage(fmt.Sprintf("Invalid filter: %s.", err))
 		}
+		filter = &expression
+	}
+
+	fieldStrings := params["field"]
+	if len(fieldStrings) == 0 {
+		fieldStrings = []string{"$"}
+	}
 
-		tmp, err := application.NewKey(b)
+	var fields []jsonpath.Path
+	for _, fieldString := range fieldStrings {
+		field, err := jsonpath.ParsePath(fieldString)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid before.")
+			return rest.ErrBadRequest.WithMessage(fmt.Sprintf("Invalid field: %s.", err))
 		}
+		fields = append(fields, field)
+	}
 
-		before = &tmp
+	var recursive bool
+	if recursiveString := params.Get("recursive"); recursiveString != "" {
+		recursive, err = strconv.ParseBool(recursiveString)
+		if err != nil {
+			return rest.ErrBadRequest.WithMessage("Invalid recursive query param.")
+		}
 	}
 
-	if afterString := r.URL.Query().Get("after"); afterString != "" {
-		b, err := hex.DecodeString(afterString)
+	limit := application.DefaultQueryLimit
+	if limitString := params.Get("limit"); limitString != "" {
+		limit, err = strconv.Atoi(limitString)
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid after query param.")
+			return rest.ErrBadRequest.WithMessage("Invalid limit query param.")
 		}
+	}
 
-		tmp, err := application.NewKey(b)
+	query, err := application.NewQuery(path, filter, fields, recursive, limit)
+	if err != nil {
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	result := QueryResult{
+		Columns: fieldStrings,
+		Rows:    make([]QueryRow, 0),
+	}
+
+	if err := h.app(r).Query.Execute(r.Context(), query, func(queryResult application.QueryResult) error {
+		result.Rows = append(result.Rows, toQueryRow(h.pretty, queryResult))
+		return nil
+	}); err != nil {
+		return h.errorResponse(err, "query failure")
+	}
+
+	return rest.NewResponse(result)
+}
+
+// stats returns the stats of the bucket or, if the path is empty, the stats of
+// all top-level buckets.
+func (h *Handler) stats(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	if len(path) == 0 {
+		stats, err := h.app(r).GetDatabaseStats.Execute(application.GetDatabaseStats{})
 		if err != nil {
-			return rest.ErrBadRequest.WithMessage("Invalid after.")
+			return h.errorResponse(err, "get database stats failure")
 		}
 
-		after = &tmp
+		return rest.NewResponse(toDatabaseStats(h.pretty, stats))
 	}
 
-	query, err := application.NewBrowse(path, before, after, nil)
+	query, err := application.NewGetBucketStats(path)
 	if err != nil {
 		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
 	}
 
-	tree, err := h.app.Browse.Execute(query)
+	stats, err := h.app(r).GetBucketStats.Execute(query)
 	if err != nil {
-		if errors.Is(err, application.ErrBucketNotFound) {
-			return rest.ErrNotFound
+		return h.errorResponse(err, "get bucket stats failure")
+	}
+
+	return rest.NewResponse(toBucketStats(stats))
+}
+
+func (h *Handler) databaseInfo(r *http.Request) rest.RestResponse {
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	report, err := h.app(r).GetDatabaseInfo.Execute(application.GetDatabaseInfo{})
+	if err != nil {
+		return h.errorResponse(err, "get database info failure")
+	}
+
+	return rest.NewResponse(toDatabaseInfoReport(report))
+}
+
+// check streams the problems found by the consistency check as JSON Lines as
+// the check can take a long time for large databases. The check always walks
+// the entire database, even if the client disconnects.
+func (h *Handler) check(w http.ResponseWriter, r *http.Request) {
+	if response := h.checkAuth(r); response != nil {
+		h.writeResponse(w, r, response)
+		return
+	}
+
+	var written bool
+	encoder := json.NewEncoder(w)
+
+	if _, err := h.app(r).CheckConsistency.Execute(r.Context(), application.CheckConsistency{}, func(problem error) error {
+		if !written {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			written = true
 		}
-		h.log.Error("browse failure", "err", err)
-		return rest.ErrInternalServerError
+
+		if err := encoder.Encode(CheckProblem{Problem: problem.Error()}); err != nil {
+			return errors.Wrap(err, "error encoding the problem")
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return nil
+	}); err != nil {
+		if written {
+			h.log.Warn("check interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "check failure"))
+		return
+	}
+
+	if !written {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+}
+
+// compact streams the progress of the compaction followed by its result as
+// JSON Lines. The compaction can't be aborted mid-way so if the client
+// disconnects the handler still waits for the entire database to be copied
+// before removing the destination file.
+func (h *Handler) compact(w http.ResponseWriter, r *http.Request) {
+	if response := h.checkAuth(r); response != nil {
+		h.writeResponse(w, r, response)
+		return
 	}
 
-	transportTree, err := toTree(tree)
+	var t CompactRequest
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Malformed input."))
+		return
+	}
+
+	cmd, err := application.NewCompact(t.Destination)
 	if err != nil {
-		h.log.Error("error converting to a tree", "err", err)
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid destination."))
+		return
+	}
+
+	var written bool
+	encoder := json.NewEncoder(w)
+
+	writeEvent := func(event CompactionEvent) error {
+		if !written {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			written = true
+		}
+
+		if err := encoder.Encode(event); err != nil {
+			return errors.Wrap(err, "error encoding the event")
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return nil
+	}
+
+	result, err := h.app(r).Compact.Execute(cmd, func(progress application.CompactionProgress) error {
+		return writeEvent(CompactionEvent{Progress: toCompactionProgress(progress)})
+	})
+	if err != nil {
+		if written {
+			h.log.Warn("compaction interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "compaction failure"))
+		return
+	}
+
+	if err := writeEvent(CompactionEvent{Result: toCompactionResult(result)}); err != nil {
+		h.log.Warn("could not write the compaction result", "err", err)
+	}
+}
+
+// backup streams a consistent copy of the database. Content-Length is dropped
+// by the gzip middleware if the response is compressed.
+func (h *Handler) backup(w http.ResponseWriter, r *http.Request) {
+	if response := h.checkAuth(r); response != nil {
+		h.writeResponse(w, r, response)
+		return
+	}
+
+	var written bool
+
+	if err := h.app(r).Backup.Execute(application.Backup{}, func(size int64) (io.Writer, error) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.Header().Set("Content-Disposition", `attachment; filename="backup.db"`)
+		written = true
+		return w, nil
+	}); err != nil {
+		if written {
+			h.log.Warn("backup interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "backup failure"))
+		return
+	}
+}
+
+// export streams the contents of the bucket as JSON Lines.
+func (h *Handler) export(w http.ResponseWriter, r *http.Request) {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		h.writeResponse(w, r, response)
+		return
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid path."))
+		return
+	}
+
+	encoding := jsonlines.EncodingHex
+	if s := r.URL.Query().Get("encoding"); s != "" {
+		encoding, err = jsonlines.NewEncoding(s)
+		if err != nil {
+			h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid encoding."))
+			return
+		}
+	}
+
+	query, err := application.NewExport(path)
+	if err != nil {
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid parameters."))
+		return
+	}
+
+	var written bool
+	encoder := jsonlines.NewEncoder(w, encoding)
+
+	if err := h.app(r).Export.Execute(r.Context(), query, func(entry application.ExportedEntry) error {
+		if !written {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", `attachment; filename="export.jsonl"`)
+			written = true
+		}
+
+		if err := encoder.Encode(entry); err != nil {
+			return errors.Wrap(err, "error encoding the entry")
+		}
+
+		return nil
+	}); err != nil {
+		if written {
+			h.log.Warn("export interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "export failure"))
+		return
+	}
+
+	if !written {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="export.jsonl"`)
+	}
+}
+
+const maxReportedImportChanges = 1000
+
+// importRecords reads JSON Lines from the request body. The response is only
+// written once the entire body is processed as HTTP/1.x doesn't allow reading
+// the request body after the response was written.
+func (h *Handler) importRecords(r *http.Request) rest.RestResponse {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		return response
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid path.")
+	}
+
+	cmd, err := readImport(r, path)
+	if err != nil {
+		h.log.Debug("invalid import", "err", err)
+		return rest.ErrBadRequest.WithMessage("Invalid parameters.")
+	}
+
+	response := ImportResult{
+		Changes: make([]ImportChange, 0),
+	}
+
+	result, err := h.app(r).Import.Execute(r.Context(), cmd, jsonlines.NewDecoder(r.Body), func(change application.ImportChange) error {
+		if change.Kind == application.ChangeKindUnchanged {
+			return nil
+		}
+
+		if len(response.Changes) >= maxReportedImportChanges {
+			response.Truncated = true
+			return nil
+		}
+
+		response.Changes = append(response.Changes, toImportChange(h.pretty, change))
+		return nil
+	})
+	if err != nil {
+		return h.errorResponse(err, "import failure")
+	}
+
+	response.Created = result.Created
+	response.Updated = result.Updated
+	response.Unchanged = result.Unchanged
+
+	return rest.NewResponse(response)
+}
+
+func readImport(r *http.Request, path []application.Key) (application.Import, error) {
+	params := r.URL.Query()
+
+	var dryRun bool
+	if dryRunString := params.Get("dry_run"); dryRunString != "" {
+		v, err := strconv.ParseBool(dryRunString)
+		if err != nil {
+			return application.Import{}, errors.Wrap(err, "invalid dry run")
+		}
+		dryRun = v
+	}
+
+	batchSize := application.DefaultImportBatchSize
+	if s := params.Get("batch_size"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return application.Import{}, errors.Wrap(err, "invalid batch size")
+		}
+		batchSize = v
+	}
+
+	return application.NewImport(path, dryRun, batchSize)
+}
+
+// diff streams the entries which differ between the served database and the
+// other database as JSON Lines.
+func (h *Handler) diff(w http.ResponseWriter, r *http.Request) {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(r); response != nil {
+		h.writeResponse(w, r, response)
+		return
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid path."))
+		return
+	}
+
+	query, err := application.NewDiff(r.URL.Query().Get("other"), path)
+	if err != nil {
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid parameters."))
+		return
+	}
+
+	var written bool
+	encoder := json.NewEncoder(w)
+
+	if err := h.app(r).Diff.Execute(r.Context(), query, func(entry application.DiffEntry) error {
+		if !written {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			written = true
+		}
+
+		dto, err := toDiffEntry(h.pretty, entry)
+		if err != nil {
+			return errors.Wrap(err, "error converting the entry")
+		}
+
+		if err := encoder.Encode(dto); err != nil {
+			return errors.Wrap(err, "error encoding the entry")
+		}
+
+		return nil
+	}); err != nil {
+		if written {
+			h.log.Warn("diff interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "diff failure"))
+		return
+	}
+
+	if !written {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+}
+
+// watch streams the changes of the direct children of the bucket as
+// Server-Sent Events. Each change is sent as a separate event. The events
+// are named after the kinds of the changes. The token can be passed using the
+// token query parameter.
+func (h *Handler) watch(w http.ResponseWriter, r *http.Request) {
+	ps := httprouter.ParamsFromContext(r.Context())
+
+	if response := h.checkAuth(withQueryToken(r)); response != nil {
+		h.writeResponse(w, r, response)
+		return
+	}
+
+	path, err := readPath(ps.ByName("path"))
+	if err != nil {
+		h.log.Warn("invalid path", "err", err)
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid path."))
+		return
+	}
+
+	query, err := application.NewWatchBucket(path, application.DefaultWatchInterval)
+	if err != nil {
+		h.writeResponse(w, r, rest.ErrBadRequest.WithMessage("Invalid parameters."))
+		return
+	}
+
+	var written bool
+
+	if err := h.app(r).WatchBucket.Execute(r.Context(), query, func(changes []application.BucketChange) error {
+		if !written {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			written = true
+		}
+
+		for _, change := range changes {
+			dto, err := toBucketChange(h.pretty, query.Path(), change)
+			if err != nil {
+				return errors.Wrap(err, "error converting the change")
+			}
+
+			b, err := json.Marshal(dto)
+			if err != nil {
+				return errors.Wrap(err, "error encoding the change")
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Kind.String(), b); err != nil {
+				return errors.Wrap(err, "error writing the event")
+			}
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return nil
+	}); err != nil {
+		if written {
+			h.log.Debug("watch interrupted", "err", err)
+			return
+		}
+		h.writeResponse(w, r, h.errorResponse(err, "watch failure"))
+		return
+	}
+}
+
+func (h *Handler) writeResponse(w http.ResponseWriter, r *http.Request, response rest.RestResponse) {
+	if err := rest.Call(w, r, func(r *http.Request) rest.RestResponse {
+		return response
+	}); err != nil {
+		h.log.Error("could not write the response", "err", err)
+	}
+}
+
+func (h *Handler) checkAuth(r *http.Request) rest.RestResponse {
+	ok, err := h.authProvider.Check(r)
+	if err != nil {
+		h.log.Error("auth provider get failed", "err", err)
+		return rest.ErrInternalServerError
+	}
+
+	if !ok {
+		return rest.ErrForbidden.WithMessage("Invalid token.")
+	}
+
+	return nil
+}
+
+func (h *Handler) errorResponse(err error, msg string) rest.RestResponse {
+	switch {
+	case errors.Is(err, application.ErrWritesDisabled):
+		return rest.ErrForbidden.WithMessage("Writes are disabled.")
+	case errors.Is(err, application.ErrBucketNotFound):
+		return rest.ErrNotFound
+	case errors.Is(err, application.ErrKeyNotFound):
+		return rest.ErrNotFound
+	case errors.Is(err, application.ErrKeyIsABucket):
+		return rest.ErrConflict.WithMessage("Key is a bucket.")
+	case errors.Is(err, application.ErrKeyAlreadyExists):
+		return rest.ErrConflict.WithMessage("Key already exists.")
+	case errors.Is(err, application.ErrSnapshotsDisabled):
+		return rest.ErrBadRequest.WithMessage("Snapshot mode is disabled.")
+	case errors.Is(err, application.ErrReopenDisabled):
+		return rest.ErrBadRequest.WithMessage("Reopening is disabled.")
+	case errors.Is(err, application.ErrCompactionDisabled):
+		return rest.ErrForbidden.WithMessage("Compaction is disabled.")
+	case errors.Is(err, application.ErrDiffDisabled):
+		return rest.ErrForbidden.WithMessage("Diff is disabled.")
+	case errors.Is(err, application.ErrDestinationExists):
+		return rest.ErrConflict.WithMessage("Destination already exists.")
+	case errors.Is(err, application.ErrInvalidEntry):
+		return rest.ErrBadRequest.WithMessage("Invalid entry.")
+	default:
+		h.log.Error(msg, "err", err)
 		return rest.ErrInternalServerError
 	}
-	return rest.NewResponse(transportTree)
 }
 
 const sep = "/"
 
+// readKeyQueryParam returns nil if the query param is not present.
+func readKeyQueryParam(r *http.Request, name string) (*application.Key, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode")
+	}
+
+	key, err := application.NewKey(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create a key")
+	}
+
+	return &key, nil
+}
+
+// readPathAndKey reads a path in which the last element is treated as a key
+// in the bucket specified by the preceding elements.
+func readPathAndKey(s string) ([]application.Key, application.Key, error) {
+	path, err := readPath(s)
+	if err != nil {
+		return nil, application.Key{}, errors.Wrap(err, "could not read the path")
+	}
+
+	if len(path) == 0 {
+		return nil, application.Key{}, errors.New("path is empty")
+	}
+
+	return path[:len(path)-1], path[len(path)-1], nil
+}
+
 func readPath(s string) ([]application.Key, error) {
 	s = strings.Trim(s, sep)
 
@@ -0,0 +1,87 @@
+package http_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/boreq/bolt-ui/display"
+	"github.com/boreq/bolt-ui/internal/config"
+	"github.com/boreq/bolt-ui/internal/fixture"
+	"github.com/boreq/bolt-ui/internal/wire"
+	httpport "github.com/boreq/bolt-ui/ports/http"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestHandlerReturnsDecodedKeys(t *testing.T) {
+	rules, err := display.NewRules([]display.Rule{
+		{Bucket: "ids", Key: "uint64"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, display.RegisterRules(rules))
+	t.Cleanup(func() {
+		require.NoError(t, display.RegisterRules(nil))
+	})
+
+	db, cleanup := fixture.Bolt(t)
+	t.Cleanup(cleanup)
+
+	key := binary.BigEndian.AppendUint64(nil, 1)
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{"ids", "other"} {
+			bucket, err := tx.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.Put(key, []byte("value")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	embedded, err := wire.BuildHandler(db, httpport.NewTokenAuthProvider(&config.Config{InsecureToken: true}), &config.Config{})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Bucket string
+		Key    httpport.Key
+	}{
+		{
+			Bucket: "ids",
+			Key: httpport.Key{
+				Hex: "0000000000000001",
+				Decoded: &httpport.DecodedKey{
+					Codec: "uint64",
+					Value: "1",
+				},
+			},
+		},
+		{
+			Bucket: "other",
+			Key: httpport.Key{
+				Hex: "0000000000000001",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Bucket, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			embedded.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/browse/"+hex.EncodeToString([]byte(testCase.Bucket)), nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var tree httpport.Tree
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tree))
+			require.Len(t, tree.Entries, 1)
+			require.Equal(t, testCase.Key, tree.Entries[0].Key)
+		})
+	}
+}
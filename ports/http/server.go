@@ -33,7 +33,7 @@ func (s *Server) Serve() error {
 		handler = cors.AllowAll().Handler(s.handler)
 	}
 
-	handler = gziphandler.GzipHandler(handler)
+	handler = Compress(handler)
 
 	if s.conf.InsecureTLS {
 		s.log.Debug("starting an insecure listener", "address", s.conf.ServeAddress)
@@ -56,3 +56,16 @@ func (s *Server) Serve() error {
 	return http.Serve(l, handler)
 
 }
+
+// Compress compresses all responses except for Server-Sent Events as the gzip
+// handler buffers small responses which would delay the events.
+func Compress(handler http.Handler) http.Handler {
+	gzipped := gziphandler.GzipHandler(handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "text/event-stream" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		gzipped.ServeHTTP(w, r)
+	})
+}